@@ -1,6 +1,6 @@
 package bcc
 
-import "log"
+import "context"
 
 type Account struct {
 	manager  *Manager
@@ -10,10 +10,14 @@ type Account struct {
 }
 
 func (m *Manager) GetAccount() (account *Account, err error) {
+	return m.GetAccountCtx(m.ctx)
+}
+
+func (m *Manager) GetAccountCtx(ctx context.Context) (account *Account, err error) {
 	path := "v1/account/me"
 
-	if err = m.Get(path, Defaults(), &account); err != nil {
-		log.Printf("[REQUEST-ERROR] get-account was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &account); err != nil {
+		m.log("[REQUEST-ERROR] get-account was failed: %s", err)
 	} else {
 		account.manager = m
 	}
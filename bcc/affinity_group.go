@@ -1,7 +1,8 @@
 package bcc
 
 import (
-	"log"
+	"context"
+	"iter"
 	"net/url"
 )
 
@@ -22,36 +23,63 @@ func NewAffinityGroup(name string, description string, policy string, vms []*Met
 }
 
 func (m *Manager) GetAffinityGroups(extraArgs ...Arguments) (affinityGroups []*AffinityGroup, err error) {
-	path := "v1/affinity_group"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetAffinityGroupsCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &affinityGroups); err != nil {
-		log.Printf("[REQUEST-ERROR] get-affinityGroups was failed: %s", err)
-	} else {
-		for i := range affinityGroups {
-			affinityGroups[i].manager = m
-		}
+func (m *Manager) GetAffinityGroupsCtx(ctx context.Context, extraArgs ...Arguments) (affinityGroups []*AffinityGroup, err error) {
+	if affinityGroups, err = Collect(m.AllAffinityGroupsCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-affinityGroups was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllAffinityGroups(extraArgs ...ListOptions) iter.Seq2[*AffinityGroup, error] {
+	return m.AllAffinityGroupsCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllAffinityGroupsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*AffinityGroup, error] {
+	path := "v1/affinity_group"
+	return listItems(m.WithContext(ctx), path, typedQuery[AffinityGroupListOptions](extraArgs), func(affinityGroup *AffinityGroup) {
+		affinityGroup.manager = m
+	})
+}
+
 func (v *Vdc) GetAffinityGroups(extraArgs ...Arguments) (affinityGroups []*AffinityGroup, err error) {
+	return v.GetAffinityGroupsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetAffinityGroupsCtx(ctx context.Context, extraArgs ...Arguments) (affinityGroups []*AffinityGroup, err error) {
 	args := Arguments{
 		"vdc": v.ID,
 	}
-	args.merge(extraArgs)
 
-	affinityGroups, err = v.manager.GetAffinityGroups(args)
+	args.merge(extraArgs)
+	affinityGroups, err = v.manager.GetAffinityGroupsCtx(ctx, args)
 	return
 }
 
+func (v *Vdc) AllAffinityGroups(extraArgs ...ListOptions) iter.Seq2[*AffinityGroup, error] {
+	return v.AllAffinityGroupsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllAffinityGroupsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*AffinityGroup, error] {
+	args := Arguments{
+		"vdc": v.ID,
+	}
+
+	return v.manager.AllAffinityGroupsCtx(ctx, args, listQuery(extraArgs))
+}
+
 func (m *Manager) GetAffinityGroup(id string) (affinityGroup *AffinityGroup, err error) {
+	return m.GetAffinityGroupCtx(m.ctx, id)
+}
+
+func (m *Manager) GetAffinityGroupCtx(ctx context.Context, id string) (affinityGroup *AffinityGroup, err error) {
 	path, _ := url.JoinPath("v1/affinity_group", id)
 
-	if err = m.Get(path, Defaults(), &affinityGroup); err != nil {
-		log.Printf("[REQUEST-ERROR] get-affinityGroup was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &affinityGroup); err != nil {
+		m.log("[REQUEST-ERROR] get-affinityGroup was failed: %s", err)
 	} else {
 		affinityGroup.Vdc.manager = m
 	}
@@ -60,6 +88,10 @@ func (m *Manager) GetAffinityGroup(id string) (affinityGroup *AffinityGroup, err
 }
 
 func (v *Vdc) CreateAffinityGroup(affinityGroup *AffinityGroup) (err error) {
+	return v.CreateAffinityGroupCtx(v.manager.ctx, affinityGroup)
+}
+
+func (v *Vdc) CreateAffinityGroupCtx(ctx context.Context, affinityGroup *AffinityGroup) (err error) {
 	path := "v1/affinity_group"
 	args := &struct {
 		Name        string   `json:"name"`
@@ -75,8 +107,8 @@ func (v *Vdc) CreateAffinityGroup(affinityGroup *AffinityGroup) (err error) {
 		Vdc:         v.ID,
 	}
 
-	if err = v.manager.Request("POST", path, args, &affinityGroup); err != nil {
-		log.Printf("[REQUEST-ERROR] create-affinityGroup was failed: %s", err)
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, &affinityGroup); err != nil {
+		v.manager.log("[REQUEST-ERROR] create-affinityGroup was failed: %s", err)
 	} else {
 		affinityGroup.manager = v.manager
 		affinityGroup.Vdc = v
@@ -86,11 +118,15 @@ func (v *Vdc) CreateAffinityGroup(affinityGroup *AffinityGroup) (err error) {
 }
 
 func (a *AffinityGroup) Reload() (err error) {
+	return a.ReloadCtx(a.manager.ctx)
+}
+
+func (a *AffinityGroup) ReloadCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/affinity_group", a.ID)
 	m := a.manager
 
-	if err := m.Get(path, Defaults(), &a); err != nil {
-		log.Printf("[REQUEST-ERROR] reload-affinityGroup was failed: %s", err)
+	if err := m.WithContext(ctx).Get(path, Defaults(), &a); err != nil {
+		a.manager.log("[REQUEST-ERROR] reload-affinityGroup was failed: %s", err)
 	} else {
 		a.manager = m
 		a.Vdc.manager = m
@@ -100,6 +136,10 @@ func (a *AffinityGroup) Reload() (err error) {
 }
 
 func (a *AffinityGroup) Update() error {
+	return a.UpdateCtx(a.manager.ctx)
+}
+
+func (a *AffinityGroup) UpdateCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/affinity_group", a.ID)
 	args := &struct {
 		Name        string   `json:"name"`
@@ -113,7 +153,7 @@ func (a *AffinityGroup) Update() error {
 		Vms:         convertNameToId(a.Vms),
 	}
 
-	if err := a.manager.Request("PUT", path, args, a); err != nil {
+	if err := a.manager.WithContext(ctx).Request("PUT", path, args, a); err != nil {
 		return err
 	}
 
@@ -121,11 +161,19 @@ func (a *AffinityGroup) Update() error {
 }
 
 func (a *AffinityGroup) Delete() error {
+	return a.DeleteCtx(a.manager.ctx)
+}
+
+func (a *AffinityGroup) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/affinity_group", a.ID)
-	return a.manager.Delete(path, Defaults(), nil)
+	return a.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (a *AffinityGroup) WaitLock() error {
+	return a.WaitLockCtx(a.manager.ctx)
+}
+
+func (a *AffinityGroup) WaitLockCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/affinity_group", a.ID)
-	return loopWaitLock(a.manager, path)
+	return loopWaitLock(a.manager.WithContext(ctx), path)
 }
@@ -0,0 +1,403 @@
+package bcc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BackupPolicy takes backups of the attached vms and disks on Schedule and
+// keeps the last Retention restore points of each of them on
+// StorageProfile.
+type BackupPolicy struct {
+	manager *Manager
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	// Schedule is a five field cron expression, like "0 3 * * *", or one of
+	// @hourly, @daily, @weekly and @monthly.
+	Schedule       string          `json:"schedule"`
+	Retention      int             `json:"retention"`
+	StorageProfile *StorageProfile `json:"storage_profile"`
+	Vdc            *Vdc            `json:"vdc,omitempty"`
+	Vms            []*MetaData     `json:"vms"`
+	Disks          []*MetaData     `json:"disks"`
+
+	Locked bool   `json:"locked"`
+	JobId  string `json:"job_id"`
+}
+
+// RestorePoint is a backup of a single disk taken by a backup policy.
+type RestorePoint struct {
+	manager      *Manager
+	ID           string    `json:"id"`
+	BackupPolicy *MetaData `json:"backup_policy"`
+	Disk         *MetaData `json:"disk"`
+	// Vm is the vm the disk was attached to when the backup was taken.
+	Vm        *MetaData `json:"vm"`
+	Size      int       `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewBackupPolicy(name string, schedule string, retention int, storageProfile *StorageProfile) BackupPolicy {
+	return BackupPolicy{Name: name, Schedule: schedule, Retention: retention, StorageProfile: storageProfile}
+}
+
+func (m *Manager) GetBackupPolicies(extraArgs ...Arguments) (policies []*BackupPolicy, err error) {
+	return m.GetBackupPoliciesCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) GetBackupPoliciesCtx(ctx context.Context, extraArgs ...Arguments) (policies []*BackupPolicy, err error) {
+	if policies, err = Collect(m.AllBackupPoliciesCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-backup-policies was failed: %s", err)
+	}
+
+	return
+}
+
+func (m *Manager) AllBackupPolicies(extraArgs ...ListOptions) iter.Seq2[*BackupPolicy, error] {
+	return m.AllBackupPoliciesCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllBackupPoliciesCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*BackupPolicy, error] {
+	path := "v1/backup_policy"
+	return listItems(m.WithContext(ctx), path, typedQuery[BackupPolicyListOptions](extraArgs), func(policy *BackupPolicy) {
+		policy.bind(m)
+	})
+}
+
+func (v *Vdc) GetBackupPolicies(extraArgs ...Arguments) (policies []*BackupPolicy, err error) {
+	return v.GetBackupPoliciesCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetBackupPoliciesCtx(ctx context.Context, extraArgs ...Arguments) (policies []*BackupPolicy, err error) {
+	args := Arguments{
+		"vdc": v.ID,
+	}
+	args.merge(extraArgs)
+	policies, err = v.manager.GetBackupPoliciesCtx(ctx, args)
+	return
+}
+
+func (v *Vdc) AllBackupPolicies(extraArgs ...ListOptions) iter.Seq2[*BackupPolicy, error] {
+	return v.AllBackupPoliciesCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllBackupPoliciesCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*BackupPolicy, error] {
+	args := Arguments{
+		"vdc": v.ID,
+	}
+	return v.manager.AllBackupPoliciesCtx(ctx, args, listQuery(extraArgs))
+}
+
+func (m *Manager) GetBackupPolicy(id string) (policy *BackupPolicy, err error) {
+	return m.GetBackupPolicyCtx(m.ctx, id)
+}
+
+func (m *Manager) GetBackupPolicyCtx(ctx context.Context, id string) (policy *BackupPolicy, err error) {
+	path, _ := url.JoinPath("v1/backup_policy", id)
+
+	if err = m.WithContext(ctx).Get(path, Defaults(), &policy); err != nil {
+		m.log("[REQUEST-ERROR] get-backup-policy was failed: %s", err)
+	} else {
+		policy.bind(m)
+	}
+
+	return
+}
+
+func (v *Vdc) CreateBackupPolicy(policy *BackupPolicy) (err error) {
+	return v.CreateBackupPolicyCtx(v.manager.ctx, policy)
+}
+
+func (v *Vdc) CreateBackupPolicyCtx(ctx context.Context, policy *BackupPolicy) (err error) {
+	if err = policy.validate(); err != nil {
+		return
+	}
+
+	path := "v1/backup_policy"
+	args := &struct {
+		Vdc            string `json:"vdc"`
+		Name           string `json:"name"`
+		Schedule       string `json:"schedule"`
+		Retention      int    `json:"retention"`
+		StorageProfile string `json:"storage_profile"`
+	}{
+		Vdc:            v.ID,
+		Name:           policy.Name,
+		Schedule:       policy.Schedule,
+		Retention:      policy.Retention,
+		StorageProfile: policy.StorageProfile.ID,
+	}
+
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, &policy); err != nil {
+		v.manager.log("[REQUEST-ERROR] create-backup-policy was failed: %s", err)
+	} else {
+		policy.bind(v.manager)
+	}
+
+	return
+}
+
+// Update applies the name, schedule, retention and target storage of the
+// policy. The attached vms and disks are managed with the Attach and Detach
+// methods.
+func (p *BackupPolicy) Update() (err error) {
+	return p.UpdateCtx(p.manager.ctx)
+}
+
+func (p *BackupPolicy) UpdateCtx(ctx context.Context) (err error) {
+	if err = p.validate(); err != nil {
+		return
+	}
+
+	path, _ := url.JoinPath("v1/backup_policy", p.ID)
+	args := &struct {
+		Name           string `json:"name"`
+		Schedule       string `json:"schedule"`
+		Retention      int    `json:"retention"`
+		StorageProfile string `json:"storage_profile"`
+	}{
+		Name:           p.Name,
+		Schedule:       p.Schedule,
+		Retention:      p.Retention,
+		StorageProfile: p.StorageProfile.ID,
+	}
+
+	if err = p.manager.WithContext(ctx).Request("PUT", path, args, p); err != nil {
+		p.manager.log("[REQUEST-ERROR] update-backup-policy was failed: %s", err)
+	} else {
+		p.bind(p.manager)
+	}
+
+	return
+}
+
+// Delete removes the policy. Restore points it has taken are kept.
+func (p *BackupPolicy) Delete() error {
+	return p.DeleteCtx(p.manager.ctx)
+}
+
+func (p *BackupPolicy) DeleteCtx(ctx context.Context) error {
+	path, _ := url.JoinPath("v1/backup_policy", p.ID)
+	return p.manager.WithContext(ctx).Delete(path, Defaults(), nil)
+}
+
+func (p BackupPolicy) WaitLock() error {
+	return p.WaitLockCtx(p.manager.ctx)
+}
+
+func (p BackupPolicy) WaitLockCtx(ctx context.Context) error {
+	path, _ := url.JoinPath("v1/backup_policy", p.ID)
+	return loopWaitLock(p.manager.WithContext(ctx), path)
+}
+
+// AttachBackupPolicy puts all disks of the vm under the policy.
+func (v *Vm) AttachBackupPolicy(policy *BackupPolicy) error {
+	return v.AttachBackupPolicyCtx(v.manager.ctx, policy)
+}
+
+func (v *Vm) AttachBackupPolicyCtx(ctx context.Context, policy *BackupPolicy) error {
+	return policy.attach(ctx, "attach", "vm", v.ID)
+}
+
+func (v *Vm) DetachBackupPolicy(policy *BackupPolicy) error {
+	return v.DetachBackupPolicyCtx(v.manager.ctx, policy)
+}
+
+func (v *Vm) DetachBackupPolicyCtx(ctx context.Context, policy *BackupPolicy) error {
+	return policy.attach(ctx, "detach", "vm", v.ID)
+}
+
+func (d *Disk) AttachBackupPolicy(policy *BackupPolicy) error {
+	return d.AttachBackupPolicyCtx(d.manager.ctx, policy)
+}
+
+func (d *Disk) AttachBackupPolicyCtx(ctx context.Context, policy *BackupPolicy) error {
+	return policy.attach(ctx, "attach", "disk", d.ID)
+}
+
+func (d *Disk) DetachBackupPolicy(policy *BackupPolicy) error {
+	return d.DetachBackupPolicyCtx(d.manager.ctx, policy)
+}
+
+func (d *Disk) DetachBackupPolicyCtx(ctx context.Context, policy *BackupPolicy) error {
+	return policy.attach(ctx, "detach", "disk", d.ID)
+}
+
+func (p *BackupPolicy) attach(ctx context.Context, action string, kind string, id string) (err error) {
+	path := fmt.Sprintf("v1/backup_policy/%s/%s", p.ID, action)
+	args := map[string]string{kind: id}
+
+	if err = p.manager.WithContext(ctx).Request("POST", path, args, p); err != nil {
+		p.manager.log("[REQUEST-ERROR] %s-backup-policy with %s='%s' was failed: %s", action, kind, id, err)
+	} else {
+		p.bind(p.manager)
+	}
+
+	return
+}
+
+func (m *Manager) GetRestorePoints(extraArgs ...Arguments) (restorePoints []*RestorePoint, err error) {
+	return m.GetRestorePointsCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) GetRestorePointsCtx(ctx context.Context, extraArgs ...Arguments) (restorePoints []*RestorePoint, err error) {
+	if restorePoints, err = Collect(m.AllRestorePointsCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-restore-points was failed: %s", err)
+	}
+
+	return
+}
+
+func (m *Manager) AllRestorePoints(extraArgs ...ListOptions) iter.Seq2[*RestorePoint, error] {
+	return m.AllRestorePointsCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllRestorePointsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*RestorePoint, error] {
+	path := "v1/restore_point"
+	return listItems(m.WithContext(ctx), path, typedQuery[RestorePointListOptions](extraArgs), func(restorePoint *RestorePoint) {
+		restorePoint.manager = m
+	})
+}
+
+func (p *BackupPolicy) GetRestorePoints(extraArgs ...Arguments) (restorePoints []*RestorePoint, err error) {
+	return p.GetRestorePointsCtx(p.manager.ctx, extraArgs...)
+}
+
+func (p *BackupPolicy) GetRestorePointsCtx(ctx context.Context, extraArgs ...Arguments) (restorePoints []*RestorePoint, err error) {
+	args := Arguments{
+		"backup_policy": p.ID,
+	}
+	args.merge(extraArgs)
+	restorePoints, err = p.manager.GetRestorePointsCtx(ctx, args)
+	return
+}
+
+func (v *Vm) GetRestorePoints(extraArgs ...Arguments) (restorePoints []*RestorePoint, err error) {
+	return v.GetRestorePointsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vm) GetRestorePointsCtx(ctx context.Context, extraArgs ...Arguments) (restorePoints []*RestorePoint, err error) {
+	args := Arguments{
+		"vm": v.ID,
+	}
+	args.merge(extraArgs)
+	restorePoints, err = v.manager.GetRestorePointsCtx(ctx, args)
+	return
+}
+
+func (d *Disk) GetRestorePoints(extraArgs ...Arguments) (restorePoints []*RestorePoint, err error) {
+	return d.GetRestorePointsCtx(d.manager.ctx, extraArgs...)
+}
+
+func (d *Disk) GetRestorePointsCtx(ctx context.Context, extraArgs ...Arguments) (restorePoints []*RestorePoint, err error) {
+	args := Arguments{
+		"disk": d.ID,
+	}
+	args.merge(extraArgs)
+	restorePoints, err = d.manager.GetRestorePointsCtx(ctx, args)
+	return
+}
+
+// Restore creates a new disk named name on vdc from the restore point. A nil
+// storageProfile keeps the storage profile of the backed up disk.
+func (r *RestorePoint) Restore(vdc *Vdc, name string, storageProfile *StorageProfile) (disk *Disk, err error) {
+	return r.RestoreCtx(r.manager.ctx, vdc, name, storageProfile)
+}
+
+func (r *RestorePoint) RestoreCtx(ctx context.Context, vdc *Vdc, name string, storageProfile *StorageProfile) (disk *Disk, err error) {
+	if vdc == nil {
+		return nil, fmt.Errorf("%w: vdc is required to restore a disk", ErrValidation)
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("%w: restored disk name must not be blank", ErrValidation)
+	}
+
+	path := fmt.Sprintf("v1/restore_point/%s/restore", r.ID)
+	args := &struct {
+		Vdc            string  `json:"vdc"`
+		Name           string  `json:"name"`
+		StorageProfile *string `json:"storage_profile,omitempty"`
+	}{
+		Vdc:  vdc.ID,
+		Name: name,
+	}
+	if storageProfile != nil {
+		args.StorageProfile = &storageProfile.ID
+	}
+
+	if err = r.manager.WithContext(ctx).Request("POST", path, args, &disk); err != nil {
+		r.manager.log("[REQUEST-ERROR] restore of restore point with id='%s' was failed: %s", r.ID, err)
+		return nil, err
+	}
+
+	disk.manager = r.manager
+	return
+}
+
+func (r *RestorePoint) UnmarshalJSON(b []byte) error {
+	type restorePoint RestorePoint
+	raw := struct {
+		*restorePoint
+		CreatedAt string `json:"created_at"`
+	}{
+		restorePoint: (*restorePoint)(r),
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	r.CreatedAt = parseJobTime(raw.CreatedAt)
+	return nil
+}
+
+func (p *BackupPolicy) bind(m *Manager) {
+	p.manager = m
+	if p.StorageProfile != nil {
+		p.StorageProfile.manager = m
+	}
+	if p.Vdc != nil {
+		p.Vdc.manager = m
+	}
+}
+
+func (p *BackupPolicy) validate() error {
+	switch {
+	case strings.TrimSpace(p.Name) == "":
+		return fmt.Errorf("%w: backup policy name must not be blank", ErrValidation)
+	case p.Retention < 1:
+		return fmt.Errorf("%w: backup policy retention must be at least 1, got %d", ErrValidation, p.Retention)
+	case p.StorageProfile == nil:
+		return fmt.Errorf("%w: backup policy needs a storage profile", ErrValidation)
+	}
+
+	return validateCron(p.Schedule)
+}
+
+var cronFieldRegexp = regexp.MustCompile(`^(\*|[0-9A-Za-z]+(-[0-9A-Za-z]+)?)(/[0-9]+)?(,(\*|[0-9A-Za-z]+(-[0-9A-Za-z]+)?)(/[0-9]+)?)*$`)
+
+// validateCron only checks the shape of the expression, the API is the
+// judge of the values.
+func validateCron(schedule string) error {
+	switch schedule {
+	case "@hourly", "@daily", "@weekly", "@monthly":
+		return nil
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return fmt.Errorf("%w: schedule %q must have 5 fields, got %d", ErrValidation, schedule, len(fields))
+	}
+	for _, field := range fields {
+		if !cronFieldRegexp.MatchString(field) {
+			return fmt.Errorf("%w: schedule %q has invalid field %q", ErrValidation, schedule, field)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+package bcc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+// backupFixtures seed the policies nightly and weekly, vm0 and vm1 with
+// the disks disk0 and disk1, and restore points of both disks.
+var backupFixtures = []fixture{
+	mainVdc,
+	object("storage_profile", bcctest.Object{"id": "sp1", "name": "ssd"}),
+	object("storage_profile", bcctest.Object{"id": "sp2", "name": "hdd"}),
+	vms(2, "vdc1"),
+	object("disk", bcctest.Object{"id": "disk0", "name": "root", "size": 10, "vm": "vm0", "storage_profile": "sp1"}),
+	object("disk", bcctest.Object{"id": "disk1", "name": "root", "size": 20, "vm": "vm1", "storage_profile": "sp1"}),
+	object("backup_policy", bcctest.Object{"id": "bp1", "name": "nightly", "vdc": "vdc1", "schedule": "0 3 * * *", "retention": 7, "storage_profile": "sp2", "vms": []interface{}{}, "disks": []interface{}{}}),
+	object("backup_policy", bcctest.Object{"id": "bp2", "name": "weekly", "vdc": "vdc1", "schedule": "@weekly", "retention": 4, "storage_profile": "sp2", "vms": []interface{}{}, "disks": []interface{}{}}),
+	object("restore_point", bcctest.Object{"id": "rp1", "backup_policy": "bp1", "disk": "disk1", "vm": "vm1", "size": 20, "created_at": "2026-10-17T03:00:00Z"}),
+	object("restore_point", bcctest.Object{"id": "rp2", "backup_policy": "bp1", "disk": "disk0", "vm": "vm0", "size": 10, "created_at": "2026-10-17T03:00:00Z"}),
+	object("restore_point", bcctest.Object{"id": "rp3", "backup_policy": "bp2", "disk": "disk1", "vm": "vm1", "size": 20, "created_at": "2026-10-12T03:00:00Z"}),
+}
+
+func TestCreateBackupPolicy(t *testing.T) {
+	storageProfile := &bcc.StorageProfile{ID: "sp1"}
+
+	tests := []struct {
+		name    string
+		policy  bcc.BackupPolicy
+		wantErr string
+	}{
+		{name: "cron", policy: bcc.NewBackupPolicy("nightly", "0 3 * * *", 7, storageProfile)},
+		{name: "steps", policy: bcc.NewBackupPolicy("often", "*/15 * * * *", 7, storageProfile)},
+		{name: "ranges and lists", policy: bcc.NewBackupPolicy("workdays", "0 3,15 1-5 * MON-FRI", 7, storageProfile)},
+		{name: "macro", policy: bcc.NewBackupPolicy("daily", "@daily", 7, storageProfile)},
+		{
+			name:    "too few fields",
+			policy:  bcc.NewBackupPolicy("nightly", "0 3 * *", 7, storageProfile),
+			wantErr: `schedule "0 3 * *" must have 5 fields, got 4`,
+		},
+		{
+			name:    "seconds field",
+			policy:  bcc.NewBackupPolicy("nightly", "0 0 3 * * *", 7, storageProfile),
+			wantErr: `schedule "0 0 3 * * *" must have 5 fields, got 6`,
+		},
+		{
+			name:    "unknown macro",
+			policy:  bcc.NewBackupPolicy("yearly", "@yearly", 7, storageProfile),
+			wantErr: `schedule "@yearly" must have 5 fields, got 1`,
+		},
+		{
+			name:    "empty schedule",
+			policy:  bcc.NewBackupPolicy("nightly", "", 7, storageProfile),
+			wantErr: `schedule "" must have 5 fields, got 0`,
+		},
+		{
+			name:    "invalid field",
+			policy:  bcc.NewBackupPolicy("nightly", "0 3 ? * *", 7, storageProfile),
+			wantErr: `schedule "0 3 ? * *" has invalid field "?"`,
+		},
+		{
+			name:    "blank name",
+			policy:  bcc.NewBackupPolicy(" ", "@daily", 7, storageProfile),
+			wantErr: "backup policy name must not be blank",
+		},
+		{
+			name:    "no retention",
+			policy:  bcc.NewBackupPolicy("nightly", "@daily", 0, storageProfile),
+			wantErr: "backup policy retention must be at least 1, got 0",
+		},
+		{
+			name:    "no storage profile",
+			policy:  bcc.NewBackupPolicy("nightly", "@daily", 7, nil),
+			wantErr: "backup policy needs a storage profile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newServer(t, backupFixtures...)
+			vdc, err := m.GetVdc("vdc1")
+			if err != nil {
+				t.Fatalf("GetVdc: %s", err)
+			}
+
+			policy := tt.policy
+			err = vdc.CreateBackupPolicy(&policy)
+			if tt.wantErr != "" {
+				if !errors.Is(err, bcc.ErrValidation) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CreateBackupPolicy = %v, want a validation error saying %q", err, tt.wantErr)
+				}
+				for _, r := range s.Requests() {
+					if r.Method == http.MethodPost {
+						t.Errorf("sent %s %s, want nothing", r.Method, r.Path)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateBackupPolicy: %s", err)
+			}
+
+			stored := s.Get("backup_policy", policy.ID)
+			if stored == nil || stored["schedule"] != tt.policy.Schedule {
+				t.Errorf("stored %v, want the schedule %s", stored, tt.policy.Schedule)
+			}
+		})
+	}
+}
+
+func TestBackupPolicyAttach(t *testing.T) {
+	tests := []struct {
+		name      string
+		change    func(ctx context.Context, m *bcc.Manager, policy *bcc.BackupPolicy) error
+		wantVms   string
+		wantDisks string
+	}{
+		{
+			name: "attach vm",
+			change: func(ctx context.Context, m *bcc.Manager, policy *bcc.BackupPolicy) error {
+				vm, err := m.GetVm("vm1")
+				if err != nil {
+					return err
+				}
+				return vm.AttachBackupPolicyCtx(ctx, policy)
+			},
+			wantVms: "vm0,vm1",
+		},
+		{
+			name: "detach vm",
+			change: func(ctx context.Context, m *bcc.Manager, policy *bcc.BackupPolicy) error {
+				vm, err := m.GetVm("vm0")
+				if err != nil {
+					return err
+				}
+				return vm.DetachBackupPolicyCtx(ctx, policy)
+			},
+		},
+		{
+			name: "attach disk",
+			change: func(ctx context.Context, m *bcc.Manager, policy *bcc.BackupPolicy) error {
+				disk, err := m.GetDisk("disk1")
+				if err != nil {
+					return err
+				}
+				return disk.AttachBackupPolicyCtx(ctx, policy)
+			},
+			wantVms:   "vm0",
+			wantDisks: "disk1",
+		},
+		{
+			name: "detach disk not attached",
+			change: func(ctx context.Context, m *bcc.Manager, policy *bcc.BackupPolicy) error {
+				disk, err := m.GetDisk("disk0")
+				if err != nil {
+					return err
+				}
+				return disk.DetachBackupPolicyCtx(ctx, policy)
+			},
+			wantVms: "vm0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newServer(t, backupFixtures...)
+			policy, err := m.GetBackupPolicy("bp1")
+			if err != nil {
+				t.Fatalf("GetBackupPolicy: %s", err)
+			}
+			vm, err := m.GetVm("vm0")
+			if err != nil {
+				t.Fatalf("GetVm: %s", err)
+			}
+			if err := vm.AttachBackupPolicy(policy); err != nil {
+				t.Fatalf("AttachBackupPolicy: %s", err)
+			}
+
+			ctx, jobs := bcc.NoWait(context.Background())
+			if err := tt.change(ctx, m, policy); err != nil {
+				t.Fatalf("%s: %s", tt.name, err)
+			}
+			if len(jobs.Jobs()) != 1 {
+				t.Fatalf("collected %d jobs, want 1", len(jobs.Jobs()))
+			}
+
+			id := func(item *bcc.MetaData) string { return item.ID }
+			if got := names(policy.Vms, id); got != tt.wantVms {
+				t.Errorf("vms = %s, want %s", got, tt.wantVms)
+			}
+			if got := names(policy.Disks, id); got != tt.wantDisks {
+				t.Errorf("disks = %s, want %s", got, tt.wantDisks)
+			}
+			if stored := s.Get("backup_policy", "bp1"); len(stored["vms"].([]interface{})) != len(policy.Vms) {
+				t.Errorf("stored vms %v, want them to match %s", stored["vms"], tt.wantVms)
+			}
+		})
+	}
+}
+
+func TestBackupPolicyAttachMissing(t *testing.T) {
+	_, m := newServer(t, backupFixtures...)
+	policy, err := m.GetBackupPolicy("bp1")
+	if err != nil {
+		t.Fatalf("GetBackupPolicy: %s", err)
+	}
+
+	vm := &bcc.Vm{ID: "missing"}
+	if err := vm.AttachBackupPolicyCtx(context.Background(), policy); !errors.Is(err, bcc.ErrValidation) {
+		t.Errorf("AttachBackupPolicy of a missing vm = %v, want a validation error", err)
+	}
+}
+
+func TestGetRestorePoints(t *testing.T) {
+	_, m := newServer(t, backupFixtures...)
+	policy, err := m.GetBackupPolicy("bp1")
+	if err != nil {
+		t.Fatalf("GetBackupPolicy: %s", err)
+	}
+	vm, err := m.GetVm("vm1")
+	if err != nil {
+		t.Fatalf("GetVm: %s", err)
+	}
+	disk, err := m.GetDisk("disk0")
+	if err != nil {
+		t.Fatalf("GetDisk: %s", err)
+	}
+
+	tests := []struct {
+		name string
+		list func() ([]*bcc.RestorePoint, error)
+		want string
+	}{
+		{name: "all", list: func() ([]*bcc.RestorePoint, error) { return m.GetRestorePoints() }, want: "rp1,rp2,rp3"},
+		{name: "of a policy", list: func() ([]*bcc.RestorePoint, error) { return policy.GetRestorePoints() }, want: "rp1,rp2"},
+		{name: "of a vm", list: func() ([]*bcc.RestorePoint, error) { return vm.GetRestorePoints() }, want: "rp1,rp3"},
+		{name: "of a disk", list: func() ([]*bcc.RestorePoint, error) { return disk.GetRestorePoints() }, want: "rp2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restorePoints, err := tt.list()
+			if err != nil {
+				t.Fatalf("GetRestorePoints: %s", err)
+			}
+			if got := names(restorePoints, func(r *bcc.RestorePoint) string { return r.ID }); got != tt.want {
+				t.Errorf("listed %s, want %s", got, tt.want)
+			}
+			for _, restorePoint := range restorePoints {
+				if restorePoint.CreatedAt.IsZero() {
+					t.Errorf("created_at of %s was not parsed", restorePoint.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestRestorePointRestore(t *testing.T) {
+	tests := []struct {
+		name               string
+		vdc                *bcc.Vdc
+		disk               string
+		storageProfile     *bcc.StorageProfile
+		wantStorageProfile string
+		wantErr            error
+	}{
+		{name: "storage profile of the backup", vdc: &bcc.Vdc{ID: "vdc1"}, disk: "restored", wantStorageProfile: "sp1"},
+		{name: "other storage profile", vdc: &bcc.Vdc{ID: "vdc1"}, disk: "restored", storageProfile: &bcc.StorageProfile{ID: "sp2"}, wantStorageProfile: "sp2"},
+		{name: "no vdc", disk: "restored", wantErr: bcc.ErrValidation},
+		{name: "blank name", vdc: &bcc.Vdc{ID: "vdc1"}, disk: " ", wantErr: bcc.ErrValidation},
+		{name: "missing vdc", vdc: &bcc.Vdc{ID: "vdc9"}, disk: "restored", wantErr: bcc.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newServer(t, backupFixtures...)
+			restorePoints, err := m.GetRestorePoints(bcc.Arguments{"disk": "disk1", "backup_policy": "bp1"})
+			if err != nil || len(restorePoints) != 1 {
+				t.Fatalf("GetRestorePoints = %d, %v, want rp1", len(restorePoints), err)
+			}
+
+			ctx, jobs := bcc.NoWait(context.Background())
+			disk, err := restorePoints[0].RestoreCtx(ctx, tt.vdc, tt.disk, tt.storageProfile)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Restore = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Restore: %s", err)
+			}
+
+			if disk.Name != tt.disk || disk.Size != 20 || disk.Vm != nil || disk.StorageProfile.ID != tt.wantStorageProfile {
+				t.Errorf("restored %+v, want an unattached disk of 20 on %s", disk, tt.wantStorageProfile)
+			}
+			if s.Get("disk", disk.ID) == nil {
+				t.Errorf("disk %s was not stored", disk.ID)
+			}
+
+			if len(jobs.Jobs()) != 1 {
+				t.Fatalf("collected %d jobs, want 1", len(jobs.Jobs()))
+			}
+			job := jobs.Jobs()[0]
+			if err := job.Wait(context.Background()); err != nil || job.Name != "restore_point.restore" {
+				t.Errorf("job %s ended with %v, want restore_point.restore done", job.Name, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package bccotel traces a bcc.Manager with OpenTelemetry.
+//
+//	manager.Tracer = bccotel.NewTracer(nil)
+//
+// It is a module of its own, so only its importers depend on OpenTelemetry:
+//
+//	go get github.com/basis-cloud/bcc-go/bcc/bccotel
+package bccotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/basis-cloud/bcc-go/bcc"
+
+// Tracer implements bcc.Tracer.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a tracer taking its spans from provider, or from the
+// global provider when provider is nil.
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...bcc.Attribute) (context.Context, bcc.Span) {
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(spanKind(name)),
+		trace.WithAttributes(convert(attrs)...),
+	)
+	return ctx, &Span{span: span}
+}
+
+// Span implements bcc.Span.
+type Span struct {
+	span trace.Span
+}
+
+func (s *Span) SetAttributes(attrs ...bcc.Attribute) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+func (s *Span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *Span) End() {
+	s.span.End()
+}
+
+func spanKind(name string) trace.SpanKind {
+	if name == "bcc.http" {
+		return trace.SpanKindClient
+	}
+	return trace.SpanKindInternal
+}
+
+func convert(attrs []bcc.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch value := attr.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(attr.Key, value))
+		case bool:
+			kvs = append(kvs, attribute.Bool(attr.Key, value))
+		case int:
+			kvs = append(kvs, attribute.Int(attr.Key, value))
+		case int64:
+			kvs = append(kvs, attribute.Int64(attr.Key, value))
+		case float64:
+			kvs = append(kvs, attribute.Float64(attr.Key, value))
+		case []string:
+			kvs = append(kvs, attribute.StringSlice(attr.Key, value))
+		default:
+			kvs = append(kvs, attribute.String(attr.Key, fmt.Sprint(value)))
+		}
+	}
+
+	return kvs
+}
@@ -0,0 +1,146 @@
+package bccotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bccotel"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newRecordingTracer() (*bccotel.Tracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	return bccotel.NewTracer(provider), recorder
+}
+
+func TestStart(t *testing.T) {
+	tests := []struct {
+		name     string
+		span     string
+		wantKind trace.SpanKind
+	}{
+		{name: "operation", span: "bcc.vm.get", wantKind: trace.SpanKindInternal},
+		{name: "http attempt", span: "bcc.http", wantKind: trace.SpanKindClient},
+		{name: "job wait", span: "bcc.job.wait", wantKind: trace.SpanKindInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, recorder := newRecordingTracer()
+
+			ctx, parent := tracer.Start(context.Background(), "parent")
+			_, span := tracer.Start(ctx, tt.span, bcc.Attr("bcc.vm.id", "vm1"))
+			span.End()
+			parent.End()
+
+			ended := recorder.Ended()
+			if len(ended) != 2 {
+				t.Fatalf("ended %d spans, want 2", len(ended))
+			}
+			got, want := ended[0], ended[1]
+			if got.Name() != tt.span || got.SpanKind() != tt.wantKind {
+				t.Errorf("span %s of kind %s, want %s of kind %s", got.Name(), got.SpanKind(), tt.span, tt.wantKind)
+			}
+			if got.Parent().SpanID() != want.SpanContext().SpanID() {
+				t.Errorf("span is not a child of the span in its context")
+			}
+			if attrs := got.Attributes(); len(attrs) != 1 || attrs[0] != attribute.String("bcc.vm.id", "vm1") {
+				t.Errorf("attributes = %v, want bcc.vm.id=vm1", attrs)
+			}
+		})
+	}
+}
+
+func TestSetAttributes(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  attribute.Value
+	}{
+		{name: "string", value: "hit", want: attribute.StringValue("hit")},
+		{name: "bool", value: true, want: attribute.BoolValue(true)},
+		{name: "int", value: 3, want: attribute.IntValue(3)},
+		{name: "int64", value: int64(1) << 40, want: attribute.Int64Value(1 << 40)},
+		{name: "float64", value: 0.5, want: attribute.Float64Value(0.5)},
+		{name: "string slice", value: []string{"a", "b"}, want: attribute.StringSliceValue([]string{"a", "b"})},
+		{name: "other", value: 2 * time.Second, want: attribute.StringValue("2s")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, recorder := newRecordingTracer()
+
+			_, span := tracer.Start(context.Background(), "bcc.vm.get")
+			span.SetAttributes(bcc.Attr("key", tt.value))
+			span.End()
+
+			attrs := recorder.Ended()[0].Attributes()
+			if len(attrs) != 1 || attrs[0].Key != "key" || attrs[0].Value != tt.want {
+				t.Errorf("attributes = %v, want key=%s", attrs, tt.want.Emit())
+			}
+		})
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	tracer, recorder := newRecordingTracer()
+
+	_, span := tracer.Start(context.Background(), "bcc.vm.get")
+	span.RecordError(errors.New("boom"))
+	span.End()
+
+	got := recorder.Ended()[0]
+	if status := got.Status(); status.Code != codes.Error || status.Description != "boom" {
+		t.Errorf("status = %+v, want an error described as boom", status)
+	}
+	if events := got.Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Errorf("events = %+v, want one exception", events)
+	}
+}
+
+func TestManagerSpans(t *testing.T) {
+	tracer, recorder := newRecordingTracer()
+
+	s := bcctest.NewServer()
+	defer s.Close()
+	s.Add("vdc", bcctest.Object{"id": "vdc1", "name": "main"})
+	s.Add("vm", bcctest.Object{"id": "vm1", "name": "web", "vdc": "vdc1"})
+	m := s.Manager()
+	m.Tracer = tracer
+
+	if _, err := m.GetVm("vm1"); err != nil {
+		t.Fatalf("GetVm: %s", err)
+	}
+	if _, err := m.GetVm("missing"); err == nil {
+		t.Fatal("GetVm of a missing vm succeeded")
+	}
+
+	var operations, attempts, failed int
+	for _, span := range recorder.Ended() {
+		switch span.SpanKind() {
+		case trace.SpanKindInternal:
+			operations++
+		case trace.SpanKindClient:
+			attempts++
+		}
+		if span.Status().Code == codes.Error {
+			failed++
+		}
+	}
+	if operations != 2 || attempts != 2 {
+		t.Errorf("recorded %d operation and %d http spans, want 2 of each", operations, attempts)
+	}
+	if failed == 0 {
+		t.Error("no span recorded the 404")
+	}
+}
@@ -0,0 +1,124 @@
+// Package bccprom exposes the metrics of a bcc.Manager to Prometheus.
+//
+//	collector := bccprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//	manager.Metrics = collector
+//
+// It is a module of its own, so only its importers depend on Prometheus:
+//
+//	go get github.com/basis-cloud/bcc-go/bcc/bccprom
+package bccprom
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector implements bcc.Metrics and prometheus.Collector. One collector
+// may be shared by several managers.
+type Collector struct {
+	requests    *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	lockRetries *prometheus.CounterVec
+	errors      *prometheus.CounterVec
+	cacheHits   *prometheus.CounterVec
+	jobWaits    *prometheus.HistogramVec
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bcc_requests_total",
+			Help: "API calls by method, route and status code, 0 when the API did not answer.",
+		}, []string{"method", "route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "bcc_request_duration_seconds",
+			Help:    "Duration of API calls, including retries and lock waits.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		lockRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bcc_lock_retries_total",
+			Help: "409 object_locked answers waited out.",
+		}, []string{"method", "route"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bcc_error_aliases_total",
+			Help: "Error aliases returned by the API.",
+		}, []string{"method", "route", "alias"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bcc_cache_hits_total",
+			Help: "API calls answered by Manager.Cache, with or without revalidation.",
+		}, []string{"method", "route"}),
+		jobWaits: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "bcc_job_wait_duration_seconds",
+			Help:    "Time spent waiting for jobs by job name and final status, or timeout, wait_cancelled, poll_error or unknown.",
+			Buckets: []float64{1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1200},
+		}, []string{"job", "status"}),
+	}
+}
+
+func (c *Collector) ObserveCall(method string, route string, result *bcc.CallResult, err error) {
+	code := "0"
+	if result != nil {
+		code = strconv.Itoa(result.Status)
+		if result.Cached {
+			c.cacheHits.WithLabelValues(method, route).Inc()
+		}
+		// answers taken from the cache without a request would skew the
+		// latency towards zero
+		if !result.Cached || result.Duration > 0 {
+			c.duration.WithLabelValues(method, route).Observe(result.Duration.Seconds())
+		}
+		if result.LockRetries > 0 {
+			c.lockRetries.WithLabelValues(method, route).Add(float64(result.LockRetries))
+		}
+	}
+	c.requests.WithLabelValues(method, route, code).Inc()
+
+	var apiErr *bcc.ApiError
+	if errors.As(err, &apiErr) {
+		for _, alias := range apiErr.ErrorAliases() {
+			c.errors.WithLabelValues(method, route, alias).Inc()
+		}
+	}
+}
+
+func (c *Collector) ObserveJobWait(job *bcc.Job, duration time.Duration, err error) {
+	status := job.Status
+	switch {
+	case job.Pending() && errors.Is(err, context.Canceled):
+		// the caller gave up the wait
+		status = "wait_cancelled"
+	case job.Pending() && errors.Is(err, context.DeadlineExceeded):
+		status = "timeout"
+	case job.Pending():
+		// polling the job failed
+		status = "poll_error"
+	case !job.Done():
+		status = "unknown"
+	}
+
+	c.jobWaits.WithLabelValues(job.Name, status).Observe(duration.Seconds())
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.lockRetries.Describe(ch)
+	c.errors.Describe(ch)
+	c.cacheHits.Describe(ch)
+	c.jobWaits.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.lockRetries.Collect(ch)
+	c.errors.Collect(ch)
+	c.cacheHits.Collect(ch)
+	c.jobWaits.Collect(ch)
+}
@@ -0,0 +1,167 @@
+package bccprom_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bccprom"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var help = map[string]string{
+	"bcc_requests_total":      "# HELP bcc_requests_total API calls by method, route and status code, 0 when the API did not answer.\n# TYPE bcc_requests_total counter\n",
+	"bcc_cache_hits_total":    "# HELP bcc_cache_hits_total API calls answered by Manager.Cache, with or without revalidation.\n# TYPE bcc_cache_hits_total counter\n",
+	"bcc_lock_retries_total":  "# HELP bcc_lock_retries_total 409 object_locked answers waited out.\n# TYPE bcc_lock_retries_total counter\n",
+	"bcc_error_aliases_total": "# HELP bcc_error_aliases_total Error aliases returned by the API.\n# TYPE bcc_error_aliases_total counter\n",
+}
+
+// exposition returns the text exposition of samples, headed by the HELP
+// and TYPE lines of their metrics.
+func exposition(samples ...string) string {
+	var b strings.Builder
+	for _, name := range []string{"bcc_cache_hits_total", "bcc_error_aliases_total", "bcc_lock_retries_total", "bcc_requests_total"} {
+		header := false
+		for _, sample := range samples {
+			if !strings.HasPrefix(sample, name+"{") {
+				continue
+			}
+			if !header {
+				b.WriteString(help[name])
+				header = true
+			}
+			b.WriteString(sample + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+func apiError(status int, aliases ...string) error {
+	body := `{"error_alias": ["` + strings.Join(aliases, `", "`) + `"]}`
+	return bcc.NewApiError("v1/vm/vm1/state", &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))})
+}
+
+func TestObserveCall(t *testing.T) {
+	tests := []struct {
+		name          string
+		result        *bcc.CallResult
+		err           error
+		want          []string
+		wantDurations int
+	}{
+		{
+			name:          "answered",
+			result:        &bcc.CallResult{Status: http.StatusOK, Duration: time.Second},
+			want:          []string{`bcc_requests_total{code="200",method="POST",route="v1/vm/{id}/state"} 1`},
+			wantDurations: 1,
+		},
+		{
+			name: "not answered",
+			err:  errors.New("connection refused"),
+			want: []string{`bcc_requests_total{code="0",method="POST",route="v1/vm/{id}/state"} 1`},
+		},
+		{
+			name:   "cache hit",
+			result: &bcc.CallResult{Status: http.StatusOK, Cached: true},
+			want: []string{
+				`bcc_cache_hits_total{method="POST",route="v1/vm/{id}/state"} 1`,
+				`bcc_requests_total{code="200",method="POST",route="v1/vm/{id}/state"} 1`,
+			},
+		},
+		{
+			name:   "cache revalidated",
+			result: &bcc.CallResult{Status: http.StatusNotModified, Duration: time.Second, Cached: true},
+			want: []string{
+				`bcc_cache_hits_total{method="POST",route="v1/vm/{id}/state"} 1`,
+				`bcc_requests_total{code="304",method="POST",route="v1/vm/{id}/state"} 1`,
+			},
+			wantDurations: 1,
+		},
+		{
+			name:   "lock retries",
+			result: &bcc.CallResult{Status: http.StatusOK, Duration: time.Second, LockRetries: 3},
+			want: []string{
+				`bcc_lock_retries_total{method="POST",route="v1/vm/{id}/state"} 3`,
+				`bcc_requests_total{code="200",method="POST",route="v1/vm/{id}/state"} 1`,
+			},
+			wantDurations: 1,
+		},
+		{
+			name:   "error aliases",
+			result: &bcc.CallResult{Status: http.StatusConflict, Duration: time.Second},
+			err:    apiError(http.StatusConflict, "vm_state_conflict", "quota_exceeded"),
+			want: []string{
+				`bcc_error_aliases_total{alias="quota_exceeded",method="POST",route="v1/vm/{id}/state"} 1`,
+				`bcc_error_aliases_total{alias="vm_state_conflict",method="POST",route="v1/vm/{id}/state"} 1`,
+				`bcc_requests_total{code="409",method="POST",route="v1/vm/{id}/state"} 1`,
+			},
+			wantDurations: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := bccprom.NewCollector()
+			c.ObserveCall(http.MethodPost, "v1/vm/{id}/state", tt.result, tt.err)
+
+			names := []string{"bcc_requests_total", "bcc_cache_hits_total", "bcc_lock_retries_total", "bcc_error_aliases_total"}
+			if err := testutil.CollectAndCompare(c, strings.NewReader(exposition(tt.want...)), names...); err != nil {
+				t.Error(err)
+			}
+			if n := testutil.CollectAndCount(c, "bcc_request_duration_seconds"); n != tt.wantDurations {
+				t.Errorf("observed %d durations, want %d", n, tt.wantDurations)
+			}
+		})
+	}
+}
+
+func TestObserveJobWait(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		err        error
+		wantStatus string
+	}{
+		{name: "done", status: bcc.JobStatusDone, wantStatus: "done"},
+		{name: "failed", status: bcc.JobStatusError, err: errors.New("job failed"), wantStatus: "error"},
+		{name: "cancelled job", status: bcc.JobStatusCancelled, err: errors.New("job cancelled"), wantStatus: "cancelled"},
+		{name: "timeout", status: "in_progress", err: fmt.Errorf("poll: %w", context.DeadlineExceeded), wantStatus: "timeout"},
+		{name: "wait cancelled", status: "in_progress", err: context.Canceled, wantStatus: "wait_cancelled"},
+		{name: "poll error", status: "in_progress", err: apiError(http.StatusBadGateway, "bad_gateway"), wantStatus: "poll_error"},
+		{name: "unknown status", status: "paused", err: bcc.ErrUnknownJobStatus, wantStatus: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := bccprom.NewCollector()
+			c.ObserveJobWait(&bcc.Job{ID: "job1", Name: "vm.create", Status: tt.status}, 2*time.Second, tt.err)
+
+			want := fmt.Sprintf(`# HELP bcc_job_wait_duration_seconds Time spent waiting for jobs by job name and final status, or timeout, wait_cancelled, poll_error or unknown.
+# TYPE bcc_job_wait_duration_seconds histogram
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="1"} 0
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="2.5"} 1
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="5"} 1
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="10"} 1
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="30"} 1
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="60"} 1
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="120"} 1
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="300"} 1
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="600"} 1
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="1200"} 1
+bcc_job_wait_duration_seconds_bucket{job="vm.create",status=%[1]q,le="+Inf"} 1
+bcc_job_wait_duration_seconds_sum{job="vm.create",status=%[1]q} 2
+bcc_job_wait_duration_seconds_count{job="vm.create",status=%[1]q} 1
+`, tt.wantStatus)
+			if err := testutil.CollectAndCompare(c, strings.NewReader(want), "bcc_job_wait_duration_seconds"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,672 @@
+package bcctest
+
+import (
+	"fmt"
+	"time"
+)
+
+type actionFunc func(s *Server, obj Object, payload Object) map[string][]string
+
+// createFunc creates the object of another kind an action answers with and
+// returns its kind, or the field errors refusing the action.
+type createFunc func(s *Server, obj Object, payload Object) (string, Object, map[string][]string)
+
+// fileFunc renders a file served as it is, like the kubeconfig of a cluster.
+type fileFunc func(s *Server, obj Object) []byte
+
+// resource describes how the fake server creates, updates and renders one
+// kind of object.
+type resource struct {
+	required []string
+	defaults Object
+	refs     map[string]string
+	created  func(s *Server, obj Object, payload Object)
+	deleted  func(s *Server, obj Object)
+	render   func(s *Server, obj Object)
+	actions  map[string]actionFunc
+	creates  map[string]createFunc
+	files    map[string]fileFunc
+	// children maps a sub-path of an object to the kind listed there, as a
+	// bare array of the objects of that kind referring to the object.
+	children map[string]string
+}
+
+var resources map[string]*resource
+
+func init() {
+	resources = map[string]*resource{
+		"project": {
+			required: []string{"name", "client"},
+			refs:     map[string]string{"client": "client"},
+		},
+		"vdc": {
+			required: []string{"name", "project", "hypervisor"},
+			refs:     map[string]string{"project": "project", "hypervisor": "hypervisor"},
+		},
+		"network": {
+			required: []string{"name", "vdc"},
+			defaults: Object{"is_default": false, "external": false, "subnets": []interface{}{}},
+			refs:     map[string]string{"vdc": "vdc"},
+		},
+		"router": {
+			required: []string{"name", "vdc"},
+			defaults: Object{"is_default": false, "routes": []interface{}{}, "floating": nil},
+			refs:     map[string]string{"vdc": "vdc"},
+			created:  routerCreated,
+			deleted:  disconnectPorts,
+			render:   renderPorts,
+		},
+		"port": {
+			required: []string{"network"},
+			defaults: Object{"connected": nil, "fw_templates": []interface{}{}},
+			created:  portCreated,
+			actions: map[string]actionFunc{
+				"PATCH disconnect": func(s *Server, obj Object, payload Object) map[string][]string {
+					obj["connected"] = nil
+					return nil
+				},
+				"DELETE force": func(s *Server, obj Object, payload Object) map[string][]string {
+					s.remove("port", obj["id"].(string))
+					return nil
+				},
+			},
+		},
+		"disk": {
+			required: []string{"name", "size", "storage_profile"},
+			defaults: Object{"is_root": false, "vm": nil, "external_id": ""},
+			refs:     map[string]string{"vdc": "vdc", "vm": "vm", "storage_profile": "storage_profile"},
+			created:  diskCreated,
+			actions: map[string]actionFunc{
+				"POST attach": func(s *Server, obj Object, payload Object) map[string][]string {
+					vmID, _ := payload["vm"].(string)
+					if s.lookup("vm", vmID) == nil {
+						return map[string][]string{"vm": {"Object does not exist."}}
+					}
+					obj["vm"] = s.ref("vm", vmID)
+					return nil
+				},
+				"POST detach": func(s *Server, obj Object, payload Object) map[string][]string {
+					obj["vm"] = nil
+					return nil
+				},
+			},
+		},
+		"backup_policy": {
+			required: []string{"name", "vdc", "schedule", "retention", "storage_profile"},
+			defaults: Object{"vms": []interface{}{}, "disks": []interface{}{}},
+			refs:     map[string]string{"vdc": "vdc", "storage_profile": "storage_profile"},
+			actions: map[string]actionFunc{
+				"POST attach": func(s *Server, obj Object, payload Object) map[string][]string {
+					return backupPolicyAttach(s, obj, payload, true)
+				},
+				"POST detach": func(s *Server, obj Object, payload Object) map[string][]string {
+					return backupPolicyAttach(s, obj, payload, false)
+				},
+			},
+		},
+		"restore_point": {
+			required: []string{"backup_policy", "disk"},
+			refs:     map[string]string{"backup_policy": "backup_policy", "disk": "disk", "vm": "vm"},
+			creates: map[string]createFunc{
+				"POST restore": restorePointRestore,
+			},
+		},
+		"snapshot": {
+			required: []string{"name", "vm"},
+			defaults: Object{"description": ""},
+			refs:     map[string]string{"vm": "vm"},
+			created:  snapshotCreated,
+			actions: map[string]actionFunc{
+				"POST revert": func(s *Server, obj Object, payload Object) map[string][]string {
+					return nil
+				},
+			},
+		},
+		"template": {
+			required: []string{"name", "vdc"},
+			refs:     map[string]string{"vdc": "vdc"},
+			children: map[string]string{"field": "template_field"},
+		},
+		"vm": {
+			required: []string{"name", "vdc", "template"},
+			defaults: Object{"power": true, "description": "", "floating": nil, "metadata": []interface{}{}},
+			refs: map[string]string{
+				"vdc":      "vdc",
+				"template": "template",
+				"platform": "platform",
+			},
+			created: vmCreated,
+			deleted: vmDeleted,
+			render:  renderVm,
+			actions: map[string]actionFunc{
+				"POST state":   vmState,
+				"POST rebuild": vmRebuild,
+				"POST migrate": vmMigrate,
+			},
+		},
+		"kubernetes": {
+			required: []string{"name", "vdc", "template", "nodes_count", "node_storage_profile"},
+			defaults: Object{"floating": nil, "user_public_key": "", "vms": []interface{}{}, "node_groups": []interface{}{}, "autoscaler": nil},
+			refs: map[string]string{
+				"vdc":                  "vdc",
+				"template":             "kubernetes_template",
+				"node_storage_profile": "storage_profile",
+				"node_platform":        "platform",
+			},
+			render: renderKubernetes,
+			actions: map[string]actionFunc{
+				"POST upgrade": kubernetesUpgrade,
+			},
+			files: map[string]fileFunc{
+				"config": kubernetesConfig,
+			},
+		},
+	}
+}
+
+// apply copies the fields of a create or update payload into obj, expanding
+// identifiers into the embedded form the API returns.
+func (s *Server) apply(kind string, obj Object, payload Object) {
+	spec := resources[kind]
+
+	for key, value := range payload {
+		switch key {
+		case "id", "locked":
+			continue
+		case "tags":
+			obj[key] = s.tags(value)
+			continue
+		}
+
+		if refKind, ok := spec.refs[key]; ok {
+			if id := idOf(value); id != "" {
+				obj[key] = s.ref(refKind, id)
+			} else {
+				obj[key] = nil
+			}
+			continue
+		}
+
+		switch kind {
+		case "port":
+			s.applyPort(obj, key, value)
+			continue
+		case "vm":
+			if s.applyVm(obj, key, value) {
+				continue
+			}
+		case "router":
+			if key == "ports" {
+				continue
+			}
+			if key == "floating" {
+				obj[key] = s.floating(value)
+				continue
+			}
+		}
+
+		obj[key] = value
+	}
+}
+
+// expandRefs turns the references of obj given as bare ids into embedded
+// objects. References already given as objects are kept as they are.
+func (s *Server) expandRefs(kind string, obj Object) {
+	spec, ok := resources[kind]
+	if !ok {
+		return
+	}
+
+	for key, value := range obj {
+		id, ok := value.(string)
+		if !ok || id == "" {
+			continue
+		}
+
+		if kind == "port" && (key == "network" || key == "vdc") {
+			s.applyPort(obj, key, id)
+			continue
+		}
+		if refKind, ok := spec.refs[key]; ok {
+			obj[key] = s.ref(refKind, id)
+		}
+	}
+}
+
+func (s *Server) applyPort(obj Object, key string, value interface{}) {
+	switch key {
+	case "network":
+		id := idOf(value)
+		network := s.lookup("network", id)
+		if network == nil {
+			obj["network"] = Object{"id": id}
+			return
+		}
+		obj["network"] = copyObject(network)
+		if _, ok := obj["vdc"]; !ok {
+			obj["vdc"] = network["vdc"]
+		}
+	case "vdc":
+		if id := idOf(value); id != "" {
+			obj["vdc"] = s.ref("vdc", id)
+		}
+	case "vm", "router":
+		if id := idOf(value); id != "" {
+			connected := s.ref(key, id)
+			connected["type"] = key
+			obj["connected"] = connected
+		}
+	case "fw_templates":
+		obj["fw_templates"] = s.refs("firewall", value)
+	default:
+		obj[key] = value
+	}
+}
+
+func (s *Server) applyVm(obj Object, key string, value interface{}) bool {
+	switch key {
+	case "ports", "disks":
+		// handled by vmCreated, rendered from the port and disk collections
+		return true
+	case "floating":
+		obj[key] = s.floating(value)
+	case "affinity_groups":
+		obj[key] = s.refs("affinity_group", value)
+	case "metadata":
+		metadata := make([]interface{}, 0)
+		items, _ := value.([]interface{})
+		for _, item := range items {
+			field, _ := item.(map[string]interface{})
+			metadata = append(metadata, Object{
+				"id":    s.nextID(),
+				"field": s.ref("template_field", idOf(field["field"])),
+				"value": field["value"],
+			})
+		}
+		obj[key] = metadata
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) tags(value interface{}) []interface{} {
+	names, _ := value.([]interface{})
+	tags := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, Object{"id": s.nextID(), "name": name})
+	}
+
+	return tags
+}
+
+func (s *Server) refs(kind string, value interface{}) []interface{} {
+	items, _ := value.([]interface{})
+	refs := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if id := idOf(item); id != "" {
+			refs = append(refs, s.ref(kind, id))
+		}
+	}
+
+	return refs
+}
+
+// floating accepts either the id of an existing external port or an ip
+// address and returns the embedded floating port.
+func (s *Server) floating(value interface{}) interface{} {
+	v, ok := value.(string)
+	if !ok || v == "" {
+		return nil
+	}
+	if port := s.lookup("port", v); port != nil {
+		return Object{"id": v, "ip_address": port["ip_address"]}
+	}
+
+	return Object{"id": s.nextID(), "ip_address": v}
+}
+
+func portCreated(s *Server, obj Object, payload Object) {
+	if _, ok := obj["ip_address"]; !ok {
+		obj["ip_address"] = fmt.Sprintf("10.0.0.%d", len(s.store["port"].order)+1)
+	}
+	if _, ok := obj["vdc"]; !ok {
+		obj["vdc"] = nil
+	}
+}
+
+func diskCreated(s *Server, obj Object, payload Object) {
+	obj["scsi"] = fmt.Sprintf("0:%d", len(s.store["disk"].order))
+	if obj["vdc"] == nil {
+		if owner := s.lookup("vm", idOf(obj["vm"])); owner != nil {
+			obj["vdc"] = owner["vdc"]
+		}
+	}
+}
+
+func routerCreated(s *Server, obj Object, payload Object) {
+	items, _ := payload["ports"].([]interface{})
+	connectPorts(s, obj, "router", items)
+}
+
+func backupPolicyAttach(s *Server, obj Object, payload Object, attach bool) map[string][]string {
+	for kind, field := range map[string]string{"vm": "vms", "disk": "disks"} {
+		id, ok := payload[kind].(string)
+		if !ok {
+			continue
+		}
+		if s.lookup(kind, id) == nil {
+			return map[string][]string{kind: {"Object does not exist."}}
+		}
+
+		items, _ := obj[field].([]interface{})
+		kept := []interface{}{}
+		for _, item := range items {
+			if idOf(item) != id {
+				kept = append(kept, item)
+			}
+		}
+		if attach {
+			kept = append(kept, s.ref(kind, id))
+		}
+		obj[field] = kept
+		return nil
+	}
+
+	return map[string][]string{"non_field_errors": {"Either vm or disk is required."}}
+}
+
+// restorePointRestore creates the disk restored from the restore point obj,
+// on the storage profile of the backed up disk unless the payload names
+// another one.
+func restorePointRestore(s *Server, obj Object, payload Object) (string, Object, map[string][]string) {
+	if fieldErrors := requireFields(payload, []string{"vdc", "name"}); len(fieldErrors) > 0 {
+		return "", nil, fieldErrors
+	}
+	vdcID, _ := payload["vdc"].(string)
+	if s.lookup("vdc", vdcID) == nil {
+		return "", nil, map[string][]string{"vdc": {"Object does not exist."}}
+	}
+
+	storageProfile := obj["storage_profile"]
+	if source := s.lookup("disk", idOf(obj["disk"])); source != nil {
+		storageProfile = source["storage_profile"]
+	}
+	if id, ok := payload["storage_profile"].(string); ok {
+		if s.lookup("storage_profile", id) == nil {
+			return "", nil, map[string][]string{"storage_profile": {"Object does not exist."}}
+		}
+		storageProfile = s.ref("storage_profile", id)
+	}
+
+	return "disk", Object{
+		"id":              s.nextID(),
+		"name":            payload["name"],
+		"size":            obj["size"],
+		"storage_profile": storageProfile,
+		"vdc":             s.ref("vdc", vdcID),
+		"vm":              nil,
+		"is_root":         false,
+		"external_id":     "",
+		"scsi":            "",
+		"locked":          false,
+		"tags":            []interface{}{},
+	}, nil
+}
+
+func snapshotCreated(s *Server, obj Object, payload Object) {
+	obj["created_at"] = time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func vmCreated(s *Server, obj Object, payload Object) {
+	items, _ := payload["ports"].([]interface{})
+	connectPorts(s, obj, "vm", items)
+
+	disks, _ := payload["disks"].([]interface{})
+	for i, item := range disks {
+		spec, _ := item.(map[string]interface{})
+		disk := Object{
+			"id":              s.nextID(),
+			"name":            spec["name"],
+			"size":            spec["size"],
+			"storage_profile": s.ref("storage_profile", idOf(spec["storage_profile"])),
+			"vdc":             obj["vdc"],
+			"vm":              Object{"id": obj["id"], "name": obj["name"]},
+			"is_root":         i == 0,
+			"external_id":     "",
+			"locked":          false,
+			"tags":            []interface{}{},
+		}
+		s.put("disk", disk)
+		disk["scsi"] = fmt.Sprintf("0:%d", i)
+	}
+}
+
+func vmDeleted(s *Server, obj Object) {
+	disconnectPorts(s, obj)
+
+	id := obj["id"].(string)
+	for _, disk := range s.attached("disk", "vm", id) {
+		if disk["is_root"] == true {
+			s.remove("disk", disk["id"].(string))
+		} else {
+			disk["vm"] = nil
+		}
+	}
+	for _, snapshot := range s.attached("snapshot", "vm", id) {
+		s.remove("snapshot", snapshot["id"].(string))
+	}
+}
+
+func vmState(s *Server, obj Object, payload Object) map[string][]string {
+	power, _ := obj["power"].(bool)
+	suspended, _ := obj["suspended"].(bool)
+
+	switch payload["state"] {
+	case "power_on":
+		obj["power"] = true
+		obj["suspended"] = false
+	case "power_off":
+		obj["power"] = false
+		obj["suspended"] = false
+	case "reboot", "reset", "shutdown", "suspend":
+		if !power {
+			return map[string][]string{"state": {"Vm is powered off."}}
+		}
+		switch payload["state"] {
+		case "shutdown":
+			obj["power"] = false
+		case "suspend":
+			obj["power"] = false
+			obj["suspended"] = true
+		}
+	case "resume":
+		if !suspended {
+			return map[string][]string{"state": {"Vm is not suspended."}}
+		}
+		obj["power"] = true
+		obj["suspended"] = false
+	default:
+		return map[string][]string{"state": {fmt.Sprintf("\"%v\" is not a valid choice.", payload["state"])}}
+	}
+
+	return nil
+}
+
+func vmRebuild(s *Server, obj Object, payload Object) map[string][]string {
+	templateID, _ := payload["template"].(string)
+	if s.lookup("template", templateID) == nil {
+		return map[string][]string{"template": {"Object does not exist."}}
+	}
+
+	obj["template"] = s.ref("template", templateID)
+	if items, ok := payload["metadata"].([]interface{}); ok {
+		metadata := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			spec, _ := item.(map[string]interface{})
+			metadata = append(metadata, Object{"field": Object{"id": spec["field"]}, "value": spec["value"]})
+		}
+		obj["metadata"] = metadata
+	}
+	if userData, ok := payload["user_data"]; ok {
+		obj["user_data"] = userData
+	}
+
+	return nil
+}
+
+func vmMigrate(s *Server, obj Object, payload Object) map[string][]string {
+	vdcID, _ := payload["vdc"].(string)
+	if s.lookup("vdc", vdcID) == nil {
+		return map[string][]string{"vdc": {"Object does not exist."}}
+	}
+	if platformID, ok := payload["platform"].(string); ok {
+		if s.lookup("platform", platformID) == nil {
+			return map[string][]string{"platform": {"Object does not exist."}}
+		}
+		obj["platform"] = s.ref("platform", platformID)
+	}
+
+	items, _ := payload["ports"].([]interface{})
+	for _, item := range items {
+		spec, _ := item.(map[string]interface{})
+		port := s.lookup("port", idOf(spec["id"]))
+		if port == nil {
+			return map[string][]string{"ports": {"Object does not exist."}}
+		}
+		s.applyPort(port, "network", idOf(spec["network"]))
+		port["vdc"] = s.ref("vdc", vdcID)
+	}
+
+	obj["vdc"] = s.ref("vdc", vdcID)
+	for _, disk := range s.attached("disk", "vm", obj["id"].(string)) {
+		disk["vdc"] = obj["vdc"]
+	}
+
+	return nil
+}
+
+// connectPorts attaches the ports listed as [{"id": ...}] to device.
+func connectPorts(s *Server, device Object, deviceType string, items []interface{}) {
+	for _, item := range items {
+		if port := s.lookup("port", idOf(item)); port != nil {
+			port["connected"] = Object{"id": device["id"], "name": device["name"], "type": deviceType}
+		}
+	}
+}
+
+func disconnectPorts(s *Server, obj Object) {
+	for _, port := range s.connectedPorts(obj["id"].(string)) {
+		port["connected"] = nil
+	}
+}
+
+func (s *Server) connectedPorts(deviceID string) []Object {
+	var ports []Object
+	if c := s.store["port"]; c != nil {
+		for _, id := range c.order {
+			port := c.items[id]
+			if idOf(port["connected"]) == deviceID {
+				ports = append(ports, port)
+			}
+		}
+	}
+
+	return ports
+}
+
+// attached returns the objects of kind whose field refers to id.
+func (s *Server) attached(kind string, field string, id string) []Object {
+	var items []Object
+	if c := s.store[kind]; c != nil {
+		for _, itemID := range c.order {
+			item := c.items[itemID]
+			if idOf(item[field]) == id {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items
+}
+
+func renderPorts(s *Server, obj Object) {
+	ports := make([]interface{}, 0)
+	for _, port := range s.connectedPorts(obj["id"].(string)) {
+		ports = append(ports, s.render("port", port))
+	}
+	obj["ports"] = ports
+}
+
+func renderVm(s *Server, obj Object) {
+	renderPorts(s, obj)
+
+	disks := make([]interface{}, 0)
+	for _, disk := range s.attached("disk", "vm", obj["id"].(string)) {
+		disks = append(disks, s.render("disk", disk))
+	}
+	obj["disks"] = disks
+}
+
+// idOf returns the identifier carried by value, which is either a bare id
+// or an embedded object.
+func idOf(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case Object:
+		id, _ := v["id"].(string)
+		return id
+	case map[string]interface{}:
+		id, _ := v["id"].(string)
+		return id
+	}
+
+	return ""
+}
+
+// renderKubernetes marks the nodes of the cluster that are locked, like the
+// nodes being replaced by an upgrade.
+func renderKubernetes(s *Server, obj Object) {
+	nodes, _ := obj["vms"].([]interface{})
+	for _, node := range nodes {
+		if vm, ok := node.(map[string]interface{}); ok {
+			vm["locked"] = s.isLocked("vm", idOf(vm))
+		}
+	}
+}
+
+func kubernetesUpgrade(s *Server, obj Object, payload Object) map[string][]string {
+	templateID, _ := payload["template"].(string)
+	if s.lookup("kubernetes_template", templateID) == nil {
+		return map[string][]string{"template": {"Object does not exist."}}
+	}
+
+	obj["template"] = s.ref("kubernetes_template", templateID)
+	return nil
+}
+
+// kubernetesConfig renders a kubeconfig giving access to the cluster obj,
+// with a cluster, a context and a user named after it.
+func kubernetesConfig(s *Server, obj Object) []byte {
+	name, id := obj["name"], obj["id"]
+	return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[2]s.kubernetes.test:6443
+    certificate-authority-data: Y2E=
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s-admin
+users:
+- name: %[1]s-admin
+  user:
+    token: token-%[2]s
+current-context: %[1]s
+`, name, id))
+}
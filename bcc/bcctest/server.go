@@ -0,0 +1,696 @@
+// Package bcctest provides an in-process fake of the BCC API, so code built
+// on bcc.Manager can be exercised end to end without reaching a real control
+// panel.
+//
+// The fake keeps every resource in memory, answers list calls with the same
+// {total, limit, items} envelope as the real API, or with a bare array for
+// the few kinds it does not paginate, reports asynchronous work through the
+// X-Esu-Tasks header and v1/job/{id}, and replies with 409 object_locked
+// while a resource is locked. Reads carry an ETag and are answered with
+// 304 Not Modified when If-None-Match matches it.
+package bcctest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+)
+
+const DefaultPageSize = 100
+
+// bareLists holds the kinds the real API lists as a bare array, without
+// pagination.
+var bareLists = map[string]bool{"platform": true, "template": true}
+
+// Object is a resource as it is stored and rendered by the fake server.
+type Object map[string]interface{}
+
+// Request is a request received by the fake server.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+type job struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	polls     int
+}
+
+type Server struct {
+	// URL is the base URL of the fake API, suitable for Manager.BaseURL.
+	URL string
+	// Token is the bearer token the server expects. Empty accepts any token.
+	Token string
+	// PageSize is the number of items returned per page when the request
+	// does not carry a limit.
+	PageSize int
+	// JobPolls is the number of v1/job polls a task stays in progress
+	// before it is reported as done.
+	JobPolls int
+
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	seq      int
+	store    map[string]*collection
+	locked   map[string]bool
+	jobs     map[string]*job
+	failJob  string
+	failures int
+	failCode int
+	requests []Request
+}
+
+type collection struct {
+	order []string
+	items map[string]Object
+}
+
+// NewServer starts a fake BCC API server. The caller must Close it.
+func NewServer() *Server {
+	s := &Server{
+		Token:    "bcctest-token",
+		PageSize: DefaultPageSize,
+		store:    make(map[string]*collection),
+		locked:   make(map[string]bool),
+		jobs:     make(map[string]*job),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+
+	return s
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns an HTTP client configured to talk to the server.
+func (s *Server) Client() *http.Client {
+	return s.srv.Client()
+}
+
+// Manager returns a bcc.Manager pointed at the server, with lock waiting
+// timeouts short enough for tests.
+func (s *Server) Manager() *bcc.Manager {
+	m, _ := bcc.NewManager(s.Token, "", "", "", false)
+	m.BaseURL = s.URL
+	m.Client = s.Client()
+	m.RequestTimeout = 5 * time.Second
+	m.RequestInterval = 10 * time.Millisecond
+	m.JobPollInterval = 10 * time.Millisecond
+
+	return m
+}
+
+// Add stores obj under kind, e.g. "template" or "storage_profile", and
+// returns the stored copy. An ID is generated when obj has none. References
+// given as bare ids, like "vdc": id, are expanded into the embedded form the
+// API returns, as on create.
+func (s *Server) Add(kind string, obj Object) Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj = copyObject(obj)
+	if id, _ := obj["id"].(string); id == "" {
+		obj["id"] = s.nextID()
+	}
+	s.expandRefs(kind, obj)
+	if _, ok := obj["tags"]; !ok {
+		obj["tags"] = []interface{}{}
+	}
+	s.put(kind, obj)
+
+	return s.render(kind, obj)
+}
+
+// Get returns the resource of kind with id as the API would render it,
+// or nil if it does not exist.
+func (s *Server) Get(kind string, id string) Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj := s.lookup(kind, id)
+	if obj == nil {
+		return nil
+	}
+
+	return s.render(kind, obj)
+}
+
+// List returns every resource of kind in creation order.
+func (s *Server) List(kind string) []Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.store[kind]
+	if c == nil {
+		return nil
+	}
+	items := make([]Object, 0, len(c.order))
+	for _, id := range c.order {
+		items = append(items, s.render(kind, c.items[id]))
+	}
+
+	return items
+}
+
+// Lock marks a resource as locked. Until Unlock is called, the resource is
+// rendered with "locked": true and every mutating request on it is answered
+// with 409 object_locked.
+func (s *Server) Lock(kind string, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locked[kind+"/"+id] = true
+}
+
+func (s *Server) Unlock(kind string, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.locked, kind+"/"+id)
+}
+
+// FailNextJob makes the next task created by the server end in the error
+// status with the given step name.
+func (s *Server) FailNextJob(step string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failJob = step
+}
+
+// SetJobStatus overrides the status of the task with the given id, e.g. to
+// report a status the client does not know.
+func (s *Server) SetJobStatus(id string, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j, ok := s.jobs[id]; ok {
+		j.Status = status
+	}
+}
+
+// FailNext makes the next n requests fail with the given HTTP status before
+// they reach the fake API, e.g. to exercise retries on 502 or 503.
+func (s *Server) FailNext(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+	s.failCode = status
+}
+
+// Requests returns every request received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+
+	if s.failures > 0 {
+		s.failures--
+		http.Error(w, http.StatusText(s.failCode), s.failCode)
+		return
+	}
+
+	if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, http.StatusUnauthorized, "not_authenticated", "Authentication credentials were not provided.")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "v1" {
+		writeError(w, http.StatusNotFound, "not_found", "Not found.")
+		return
+	}
+
+	var payload Object
+	if len(body) > 0 && string(body) != "null" {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			writeError(w, http.StatusBadRequest, "parse_error", fmt.Sprintf("JSON parse error - %s", err))
+			return
+		}
+	}
+	if payload == nil {
+		payload = Object{}
+	}
+
+	kind := parts[1]
+	parts = parts[2:]
+
+	if kind == "job" {
+		s.serveJob(w, r, parts)
+		return
+	}
+
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		s.serveList(w, r, kind)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		s.serveCreate(w, kind, payload)
+	case len(parts) == 1:
+		s.serveObject(w, r, kind, parts[0], payload)
+	case len(parts) == 2:
+		s.serveAction(w, r, kind, parts[0], parts[1], payload)
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "Not found.")
+	}
+}
+
+func (s *Server) serveJob(w http.ResponseWriter, r *http.Request, parts []string) {
+	cancel := len(parts) == 2 && parts[1] == "cancel" && r.Method == http.MethodPost
+	if !cancel && (len(parts) != 1 || r.Method != http.MethodGet) {
+		writeError(w, http.StatusNotFound, "not_found", "Not found.")
+		return
+	}
+
+	j, ok := s.jobs[parts[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not found.")
+		return
+	}
+
+	if cancel {
+		if j.Status != "processing" {
+			writeError(w, http.StatusBadRequest, "job_not_cancellable", "Task is already finished.")
+			return
+		}
+		j.Status = "cancelled"
+		j.UpdatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+		writeJSON(w, http.StatusOK, j)
+		return
+	}
+
+	if j.Status == "processing" {
+		j.polls++
+		if j.polls > s.JobPolls {
+			j.Status = "done"
+			j.UpdatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, j)
+}
+
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request, kind string) {
+	query := r.URL.Query()
+
+	limit := s.PageSize
+	if v, err := strconv.Atoi(query.Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	page := 1
+	if v, err := strconv.Atoi(query.Get("page")); err == nil && v > 0 {
+		page = v
+	}
+
+	var matched []Object
+	if c := s.store[kind]; c != nil {
+		for _, id := range c.order {
+			obj := s.render(kind, c.items[id])
+			if matchQuery(obj, query) {
+				matched = append(matched, obj)
+			}
+		}
+	}
+
+	if bareLists[kind] {
+		writeTagged(w, r, append([]Object{}, matched...))
+		return
+	}
+
+	items := make([]Object, 0, limit)
+	start := (page - 1) * limit
+	for i := start; i < len(matched) && i < start+limit; i++ {
+		items = append(items, matched[i])
+	}
+
+	writeTagged(w, r, map[string]interface{}{
+		"total": len(matched),
+		"limit": limit,
+		"items": items,
+	})
+}
+
+func (s *Server) serveCreate(w http.ResponseWriter, kind string, payload Object) {
+	spec, ok := resources[kind]
+	if !ok {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method \"POST\" not allowed.")
+		return
+	}
+
+	if fieldErrors := requireFields(payload, spec.required); len(fieldErrors) > 0 {
+		writeJSON(w, http.StatusBadRequest, fieldErrors)
+		return
+	}
+
+	obj := Object{
+		"id":     s.nextID(),
+		"locked": false,
+		"tags":   []interface{}{},
+	}
+	for key, value := range spec.defaults {
+		obj[key] = value
+	}
+	s.apply(kind, obj, payload)
+	s.put(kind, obj)
+
+	if spec.created != nil {
+		spec.created(s, obj, payload)
+	}
+
+	s.writeTask(w, kind+".create")
+	writeJSON(w, http.StatusCreated, s.render(kind, obj))
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, kind string, id string, payload Object) {
+	obj := s.lookup(kind, id)
+	if obj == nil {
+		writeError(w, http.StatusNotFound, "not_found", "Not found.")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		writeTagged(w, r, s.render(kind, obj))
+		return
+	}
+
+	if _, ok := resources[kind]; !ok {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", fmt.Sprintf("Method \"%s\" not allowed.", r.Method))
+		return
+	}
+
+	if s.isLocked(kind, id) {
+		writeLocked(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut, http.MethodPatch:
+		s.apply(kind, obj, payload)
+		s.writeTask(w, kind+".update")
+		writeJSON(w, http.StatusOK, s.render(kind, obj))
+	case http.MethodDelete:
+		s.remove(kind, id)
+		s.writeTask(w, kind+".delete")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", fmt.Sprintf("Method \"%s\" not allowed.", r.Method))
+	}
+}
+
+func (s *Server) serveAction(w http.ResponseWriter, r *http.Request, kind string, id string, action string, payload Object) {
+	spec, ok := resources[kind]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Not found.")
+		return
+	}
+
+	if child, ok := spec.children[action]; ok && r.Method == http.MethodGet {
+		s.serveChildren(w, r, kind, id, child)
+		return
+	}
+
+	if file, ok := spec.files[action]; ok && r.Method == http.MethodGet {
+		s.serveFile(w, kind, id, file)
+		return
+	}
+
+	handler, ok := spec.actions[r.Method+" "+action]
+	create, creates := spec.creates[r.Method+" "+action]
+	if !ok && !creates {
+		writeError(w, http.StatusNotFound, "not_found", "Not found.")
+		return
+	}
+
+	obj := s.lookup(kind, id)
+	if obj == nil {
+		writeError(w, http.StatusNotFound, "not_found", "Not found.")
+		return
+	}
+
+	if s.isLocked(kind, id) {
+		writeLocked(w)
+		return
+	}
+
+	if creates {
+		createdKind, created, fieldErrors := create(s, obj, payload)
+		if len(fieldErrors) > 0 {
+			writeJSON(w, http.StatusBadRequest, fieldErrors)
+			return
+		}
+		s.put(createdKind, created)
+		s.writeTask(w, kind+"."+action)
+		writeJSON(w, http.StatusCreated, s.render(createdKind, created))
+		return
+	}
+
+	if fieldErrors := handler(s, obj, payload); len(fieldErrors) > 0 {
+		writeJSON(w, http.StatusBadRequest, fieldErrors)
+		return
+	}
+
+	s.writeTask(w, kind+"."+action)
+	if s.lookup(kind, id) == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.render(kind, obj))
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, kind string, id string, file fileFunc) {
+	obj := s.lookup(kind, id)
+	if obj == nil {
+		writeError(w, http.StatusNotFound, "not_found", "Not found.")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(file(s, obj))
+}
+
+// serveChildren lists the objects of kind child referring to the object of
+// kind with id.
+func (s *Server) serveChildren(w http.ResponseWriter, r *http.Request, kind string, id string, child string) {
+	if s.lookup(kind, id) == nil {
+		writeError(w, http.StatusNotFound, "not_found", "Not found.")
+		return
+	}
+
+	items := make([]Object, 0)
+	for _, obj := range s.attached(child, kind, id) {
+		items = append(items, s.render(child, obj))
+	}
+	writeTagged(w, r, items)
+}
+
+// writeTask registers a new task and announces it in the X-Esu-Tasks header.
+func (s *Server) writeTask(w http.ResponseWriter, name string) {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	j := &job{ID: s.nextID(), Status: "processing", Name: name, CreatedAt: now, UpdatedAt: now}
+	if s.JobPolls <= 0 {
+		j.Status = "done"
+	}
+	if s.failJob != "" {
+		j.Status = "error"
+		j.Name = s.failJob
+		s.failJob = ""
+	}
+	s.jobs[j.ID] = j
+
+	w.Header().Set("X-Esu-Tasks", j.ID)
+}
+
+func (s *Server) nextID() string {
+	s.seq++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.seq)
+}
+
+func (s *Server) put(kind string, obj Object) {
+	c := s.store[kind]
+	if c == nil {
+		c = &collection{items: make(map[string]Object)}
+		s.store[kind] = c
+	}
+
+	id := obj["id"].(string)
+	if _, ok := c.items[id]; !ok {
+		c.order = append(c.order, id)
+	}
+	c.items[id] = obj
+}
+
+func (s *Server) lookup(kind string, id string) Object {
+	if c := s.store[kind]; c != nil {
+		return c.items[id]
+	}
+
+	return nil
+}
+
+func (s *Server) remove(kind string, id string) {
+	c := s.store[kind]
+	if c == nil {
+		return
+	}
+
+	if spec, ok := resources[kind]; ok && spec.deleted != nil {
+		spec.deleted(s, c.items[id])
+	}
+
+	delete(c.items, id)
+	for i, itemID := range c.order {
+		if itemID == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	delete(s.locked, kind+"/"+id)
+}
+
+func (s *Server) isLocked(kind string, id string) bool {
+	return s.locked[kind+"/"+id]
+}
+
+// render returns a copy of obj with its lock state and the collections that
+// are derived from other resources, such as the ports of a vm.
+func (s *Server) render(kind string, obj Object) Object {
+	out := copyObject(obj)
+	out["locked"] = s.isLocked(kind, obj["id"].(string))
+
+	if spec, ok := resources[kind]; ok && spec.render != nil {
+		spec.render(s, out)
+	}
+
+	return out
+}
+
+// ref returns the short {id, name} form used when a resource is embedded
+// into another one.
+func (s *Server) ref(kind string, id string) Object {
+	ref := Object{"id": id}
+	if obj := s.lookup(kind, id); obj != nil {
+		ref["name"] = obj["name"]
+	}
+
+	return ref
+}
+
+func matchQuery(obj Object, query map[string][]string) bool {
+	for key, values := range query {
+		switch key {
+		case "page", "limit", "sort", "ordering":
+			continue
+		}
+
+		value, ok := obj[key]
+		if !ok {
+			continue
+		}
+		switch value.(type) {
+		case Object, map[string]interface{}:
+			value = idOf(value)
+		}
+
+		found := false
+		for _, want := range values {
+			if fmt.Sprint(value) == want {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func requireFields(payload Object, fields []string) map[string][]string {
+	fieldErrors := make(map[string][]string)
+	for _, field := range fields {
+		if value, ok := payload[field]; !ok || value == nil || value == "" {
+			fieldErrors[field] = []string{"This field is required."}
+		}
+	}
+
+	return fieldErrors
+}
+
+func copyObject(obj Object) Object {
+	b, _ := json.Marshal(obj)
+	out := make(Object)
+	json.Unmarshal(b, &out)
+
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeTagged answers a read with body and its ETag, or with 304 Not
+// Modified when the client already holds it.
+func writeTagged(w http.ResponseWriter, r *http.Request, body interface{}) {
+	b, _ := json.Marshal(body)
+	hash := sha256.Sum256(b)
+	etag := `"` + hex.EncodeToString(hash[:8]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, status int, alias string, detail string) {
+	writeJSON(w, status, map[string]interface{}{
+		"detail":      detail,
+		"error_alias": []string{alias},
+	})
+}
+
+func writeLocked(w http.ResponseWriter) {
+	writeJSON(w, http.StatusConflict, map[string]interface{}{
+		"details":          []interface{}{},
+		"error_alias":      []string{"object_locked"},
+		"non_field_errors": []string{"Object is locked, try again later."},
+	})
+}
@@ -0,0 +1,129 @@
+package bcctest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+func TestAddExpandsRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		vm   bcctest.Object
+	}{
+		{
+			name: "bare ids",
+			vm:   bcctest.Object{"id": "vm1", "name": "web", "vdc": "vdc1", "template": "tpl1"},
+		},
+		{
+			name: "embedded objects",
+			vm: bcctest.Object{
+				"id":       "vm1",
+				"name":     "web",
+				"vdc":      bcctest.Object{"id": "vdc1", "name": "main"},
+				"template": bcctest.Object{"id": "tpl1", "name": "ubuntu"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := bcctest.NewServer()
+			defer s.Close()
+
+			s.Add("vdc", bcctest.Object{"id": "vdc1", "name": "main"})
+			s.Add("template", bcctest.Object{"id": "tpl1", "name": "ubuntu"})
+			s.Add("vm", tt.vm)
+
+			vm, err := s.Manager().GetVm("vm1")
+			if err != nil {
+				t.Fatalf("GetVm: %s", err)
+			}
+			if vm.Vdc == nil || vm.Vdc.ID != "vdc1" || vm.Vdc.Name != "main" {
+				t.Errorf("vdc = %+v, want vdc1 named main", vm.Vdc)
+			}
+			if vm.Template == nil || vm.Template.ID != "tpl1" || vm.Template.Name != "ubuntu" {
+				t.Errorf("template = %+v, want tpl1 named ubuntu", vm.Template)
+			}
+
+			if err := vm.PowerOff(); err != nil {
+				t.Fatalf("PowerOff: %s", err)
+			}
+			if vm.Power {
+				t.Error("vm is still powered on")
+			}
+		})
+	}
+}
+
+func TestAddPortOnNetwork(t *testing.T) {
+	s := bcctest.NewServer()
+	defer s.Close()
+
+	s.Add("vdc", bcctest.Object{"id": "vdc1", "name": "main"})
+	s.Add("network", bcctest.Object{"id": "net1", "name": "lan", "vdc": "vdc1", "subnets": []interface{}{}})
+	port := s.Add("port", bcctest.Object{"id": "port1", "network": "net1"})
+
+	network, _ := port["network"].(map[string]interface{})
+	if network["name"] != "lan" {
+		t.Errorf("port network = %v, want the lan network", port["network"])
+	}
+	vdc, _ := port["vdc"].(map[string]interface{})
+	if vdc["id"] != "vdc1" {
+		t.Errorf("port vdc = %v, want vdc1 taken from the network", port["vdc"])
+	}
+}
+
+func TestLockedObject(t *testing.T) {
+	s := bcctest.NewServer()
+	defer s.Close()
+
+	s.Add("vdc", bcctest.Object{"id": "vdc1", "name": "main"})
+	s.Add("vm", bcctest.Object{"id": "vm1", "name": "web", "vdc": "vdc1"})
+	s.Lock("vm", "vm1")
+
+	m := s.Manager()
+	vm, err := m.GetVm("vm1")
+	if err != nil {
+		t.Fatalf("GetVm: %s", err)
+	}
+	if !vm.Locked {
+		t.Error("vm is not rendered as locked")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	if err := vm.PowerOffCtx(ctx); err == nil {
+		t.Fatal("PowerOff succeeded on a locked vm")
+	}
+
+	s.Unlock("vm", "vm1")
+	if err := vm.PowerOff(); err != nil {
+		t.Fatalf("PowerOff after unlock: %s", err)
+	}
+}
+
+func TestFailNextJob(t *testing.T) {
+	s := bcctest.NewServer()
+	defer s.Close()
+
+	s.Add("vdc", bcctest.Object{"id": "vdc1", "name": "main"})
+	s.Add("vm", bcctest.Object{"id": "vm1", "name": "web", "vdc": "vdc1"})
+	s.FailNextJob("vm.power_off")
+
+	vm, err := s.Manager().GetVm("vm1")
+	if err != nil {
+		t.Fatalf("GetVm: %s", err)
+	}
+
+	var jobErr *bcc.JobError
+	if err := vm.PowerOff(); !errors.As(err, &jobErr) {
+		t.Fatalf("PowerOff error = %v, want a *bcc.JobError", err)
+	}
+	if jobErr.Step != "vm.power_off" {
+		t.Errorf("failed step = %q, want vm.power_off", jobErr.Step)
+	}
+}
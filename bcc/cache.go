@@ -0,0 +1,214 @@
+package bcc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheResource names a group of catalog endpoints sharing a TTL.
+type CacheResource string
+
+const (
+	CacheTemplates           CacheResource = "template"
+	CachePlatforms           CacheResource = "platform"
+	CacheStorageProfiles     CacheResource = "storage_profile"
+	CacheHypervisors         CacheResource = "hypervisor"
+	CacheKubernetesTemplates CacheResource = "kubernetes_template"
+)
+
+var cacheResources = []CacheResource{
+	CacheTemplates,
+	CachePlatforms,
+	CacheStorageProfiles,
+	CacheHypervisors,
+	CacheKubernetesTemplates,
+}
+
+const DefaultCacheTTL = 10 * time.Minute
+
+// Cache holds the answers of the catalog endpoints read by GetTemplates,
+// GetPlatforms, GetStorageProfiles, GetAvailableHypervisors and
+// GetKubernetesTemplates. Set Manager.Cache to enable it.
+//
+// Entries are kept past their expiry: when the API sent an ETag the entry is
+// revalidated with If-None-Match instead of being fetched again.
+//
+// The cache is the innermost stage of the middleware chain: the headers set
+// by middleware are part of the key, and cached answers still go through
+// the middleware and Manager.Metrics, with CallResult.Cached set.
+type Cache interface {
+	Get(resource CacheResource, key string) (CacheEntry, bool)
+	Set(resource CacheResource, key string, entry CacheEntry)
+	// Invalidate drops every entry of resource.
+	Invalidate(resource CacheResource)
+}
+
+type CacheEntry struct {
+	Body    []byte
+	ETag    string
+	Expires time.Time
+}
+
+// MemoryCache is a Cache kept in memory. It is safe for concurrent use and
+// may be shared by managers of different accounts, as the keys include the
+// credentials.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[CacheResource]map[string]CacheEntry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[CacheResource]map[string]CacheEntry)}
+}
+
+func (c *MemoryCache) Get(resource CacheResource, key string) (CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[resource][key]
+	return entry, ok
+}
+
+func (c *MemoryCache) Set(resource CacheResource, key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[resource] == nil {
+		c.entries[resource] = make(map[string]CacheEntry)
+	}
+	c.entries[resource][key] = entry
+}
+
+func (c *MemoryCache) Invalidate(resource CacheResource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, resource)
+}
+
+// InvalidateCache drops the cached answers of resources, or of every
+// resource when none is given.
+func (m *Manager) InvalidateCache(resources ...CacheResource) {
+	if m.Cache == nil {
+		return
+	}
+	if len(resources) == 0 {
+		resources = cacheResources
+	}
+
+	for _, resource := range resources {
+		m.Cache.Invalidate(resource)
+	}
+}
+
+// cached returns a manager whose GET calls are answered from the cache as
+// resource.
+func (m *Manager) cached(resource CacheResource) *Manager {
+	if m.Cache == nil {
+		return m
+	}
+
+	newManager := *m
+	newManager.cacheResource = resource
+	return &newManager
+}
+
+func (m *Manager) cacheTTL(resource CacheResource) time.Duration {
+	if ttl, ok := m.CacheTTL[resource]; ok {
+		return ttl
+	}
+
+	return DefaultCacheTTL
+}
+
+// cacheKeyIgnoredHeaders differ from one call to the next without changing
+// the answer, they are left out of the cache key.
+var cacheKeyIgnoredHeaders = map[string]bool{
+	"Authorization":   true,
+	"If-None-Match":   true,
+	"Idempotency-Key": true,
+	"X-Request-Id":    true,
+	"Traceparent":     true,
+	"Tracestate":      true,
+	"Baggage":         true,
+}
+
+// cacheKey identifies the answer to call. Besides the credentials and the
+// URL it covers the headers set by middleware, like a tenant or project
+// header, so that calls made on behalf of different tenants are kept apart.
+func (m *Manager) cacheKey(call *Call) string {
+	credentials := call.Header.Get("Authorization")
+	if credentials == "" {
+		credentials = m.Token
+	}
+	hash := sha256.Sum256([]byte(credentials))
+
+	var key strings.Builder
+	key.WriteString(hex.EncodeToString(hash[:8]) + " " + m.BaseURL + " " + call.Path + "?" + call.Query.Encode())
+
+	names := make([]string, 0, len(call.Header))
+	for name := range call.Header {
+		if !cacheKeyIgnoredHeaders[http.CanonicalHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key.WriteString(" " + http.CanonicalHeaderKey(name) + ": " + strings.Join(call.Header[name], ", "))
+	}
+
+	return key.String()
+}
+
+// cacheStage answers the GET calls of m.cacheResource from the cache. It is
+// the innermost stage of the chain, so middleware, metrics and recorded
+// cassettes see cache hits like any other call.
+func (m *Manager) cacheStage(next Doer) Doer {
+	return DoerFunc(func(ctx context.Context, call *Call) (*CallResult, error) {
+		if call.Method != http.MethodGet {
+			return next.Do(ctx, call)
+		}
+
+		key := m.cacheKey(call)
+		entry, ok := m.Cache.Get(m.cacheResource, key)
+		if ok && time.Now().Before(entry.Expires) {
+			m.log("[bcc] Cache hit on %s", call.Path)
+			m.traceCache("hit")
+			return &CallResult{Status: http.StatusOK, Header: http.Header{}, Body: entry.Body, Cached: true}, nil
+		}
+		if ok && entry.ETag != "" {
+			call.Header.Set("If-None-Match", entry.ETag)
+		}
+
+		result, err := next.Do(ctx, call)
+		if err != nil {
+			return result, err
+		}
+
+		if ok && result.Status == http.StatusNotModified {
+			m.log("[bcc] Cache revalidated on %s", call.Path)
+			m.traceCache("revalidated")
+			result.Body = entry.Body
+			result.Cached = true
+		} else {
+			m.traceCache("miss")
+			entry = CacheEntry{Body: result.Body, ETag: result.Header.Get("ETag")}
+		}
+		entry.Expires = time.Now().Add(m.cacheTTL(m.cacheResource))
+		m.Cache.Set(m.cacheResource, key, entry)
+
+		return result, nil
+	})
+}
+
+func (m *Manager) traceCache(outcome string) {
+	if m.span != nil {
+		m.span.SetAttributes(Attr("bcc.cache", outcome))
+	}
+}
@@ -0,0 +1,173 @@
+package bcc_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+// tenantHeader sets X-Tenant-Id on every call to the next of tenants.
+func tenantHeader(tenants ...string) bcc.Middleware {
+	i := 0
+	return func(next bcc.Doer) bcc.Doer {
+		return bcc.DoerFunc(func(ctx context.Context, call *bcc.Call) (*bcc.CallResult, error) {
+			call.Header.Set("X-Tenant-Id", tenants[i%len(tenants)])
+			i++
+			return next.Do(ctx, call)
+		})
+	}
+}
+
+// recordedMetrics keeps the results passed to ObserveCall.
+type recordedMetrics struct {
+	mu      sync.Mutex
+	results []*bcc.CallResult
+}
+
+func (r *recordedMetrics) ObserveCall(method string, route string, result *bcc.CallResult, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result)
+}
+
+func (r *recordedMetrics) ObserveJobWait(job *bcc.Job, duration time.Duration, err error) {}
+
+func newStorageProfileServer(t *testing.T) (*bcctest.Server, *bcc.Manager, *bcc.Vdc) {
+	t.Helper()
+
+	s, m := newServer(t, mainVdc, object("storage_profile", bcctest.Object{"id": "sp1", "name": "ssd"}))
+	m.Cache = bcc.NewMemoryCache()
+	vdc, err := m.GetVdc("vdc1")
+	if err != nil {
+		t.Fatalf("GetVdc: %s", err)
+	}
+
+	return s, m, vdc
+}
+
+// storageProfileRequests counts the storage profile lists sent to s and
+// those of them revalidating a cached answer.
+func storageProfileRequests(s *bcctest.Server) (sent int, revalidated int) {
+	for _, r := range s.Requests() {
+		if r.Method != http.MethodGet || r.Path != "/v1/storage_profile" {
+			continue
+		}
+		sent++
+		if r.Header.Get("If-None-Match") != "" {
+			revalidated++
+		}
+	}
+
+	return sent, revalidated
+}
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name            string
+		setup           func(m *bcc.Manager)
+		between         func(m *bcc.Manager)
+		wantSent        int
+		wantRevalidated int
+	}{
+		{name: "hit", wantSent: 1},
+		{
+			name:     "no cache",
+			setup:    func(m *bcc.Manager) { m.Cache = nil },
+			wantSent: 2,
+		},
+		{
+			name:            "expired entry is revalidated",
+			setup:           func(m *bcc.Manager) { m.CacheTTL = map[bcc.CacheResource]time.Duration{bcc.CacheStorageProfiles: 0} },
+			wantSent:        2,
+			wantRevalidated: 1,
+		},
+		{
+			name:     "other resource ttl",
+			setup:    func(m *bcc.Manager) { m.CacheTTL = map[bcc.CacheResource]time.Duration{bcc.CacheTemplates: 0} },
+			wantSent: 1,
+		},
+		{
+			name:     "invalidated",
+			between:  func(m *bcc.Manager) { m.InvalidateCache(bcc.CacheStorageProfiles) },
+			wantSent: 2,
+		},
+		{
+			name:     "other resource invalidated",
+			between:  func(m *bcc.Manager) { m.InvalidateCache(bcc.CacheTemplates) },
+			wantSent: 1,
+		},
+		{
+			name:     "tenant headers keep entries apart",
+			setup:    func(m *bcc.Manager) { m.Use(tenantHeader("t1", "t2")) },
+			wantSent: 2,
+		},
+		{
+			name:     "same tenant shares the entry",
+			setup:    func(m *bcc.Manager) { m.Use(tenantHeader("t1")) },
+			wantSent: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m, vdc := newStorageProfileServer(t)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			for i := 0; i < 2; i++ {
+				if i == 1 && tt.between != nil {
+					tt.between(m)
+				}
+				profiles, err := vdc.GetStorageProfiles()
+				if err != nil {
+					t.Fatalf("GetStorageProfiles: %s", err)
+				}
+				if len(profiles) != 1 || profiles[0].Name != "ssd" {
+					t.Fatalf("call %d: got %+v, want the ssd profile", i, profiles)
+				}
+			}
+
+			sent, revalidated := storageProfileRequests(s)
+			if sent != tt.wantSent || revalidated != tt.wantRevalidated {
+				t.Errorf("sent %d requests, %d revalidated, want %d and %d", sent, revalidated, tt.wantSent, tt.wantRevalidated)
+			}
+		})
+	}
+}
+
+func TestCacheHitsPassTheChain(t *testing.T) {
+	_, m, vdc := newStorageProfileServer(t)
+
+	metrics := &recordedMetrics{}
+	m.Metrics = metrics
+	var seen []bool
+	m.Use(func(next bcc.Doer) bcc.Doer {
+		return bcc.DoerFunc(func(ctx context.Context, call *bcc.Call) (*bcc.CallResult, error) {
+			result, err := next.Do(ctx, call)
+			seen = append(seen, result != nil && result.Cached)
+			return result, err
+		})
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := vdc.GetStorageProfiles(); err != nil {
+			t.Fatalf("GetStorageProfiles: %s", err)
+		}
+	}
+
+	if len(seen) != 2 || seen[0] || !seen[1] {
+		t.Errorf("middleware saw cached = %v, want [false true]", seen)
+	}
+	if len(metrics.results) != 2 {
+		t.Fatalf("observed %d calls, want 2", len(metrics.results))
+	}
+	if hit := metrics.results[1]; !hit.Cached || hit.Status != http.StatusOK || hit.Duration != 0 {
+		t.Errorf("observed the hit as %+v, want a cached 200 without duration", hit)
+	}
+}
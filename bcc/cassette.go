@@ -0,0 +1,265 @@
+package bcc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var ErrNoInteraction = errors.New("bcc: no recorded interaction")
+
+// Cassette holds API interactions recorded through a Manager, to replay
+// them in tests without reaching the API:
+//
+//	if os.Getenv("BCC_RECORD") != "" {
+//		cassette = bcc.NewCassette("testdata/vm.json")
+//		manager.Use(cassette.Record())
+//		defer cassette.Save()
+//	} else {
+//		cassette, err = bcc.LoadCassette("testdata/vm.json")
+//		manager.Use(cassette.Replay(manager))
+//	}
+//
+// The bearer token and cookies are never written and the values of
+// sensitive keys such as secret_key are redacted, in JSON bodies as well as
+// in text bodies like kubeconfig files.
+type Cassette struct {
+	Path         string         `json:"-"`
+	Interactions []*Interaction `json:"interactions"`
+
+	mu   sync.Mutex
+	used []bool
+}
+
+type Interaction struct {
+	Request  InteractionRequest  `json:"request"`
+	Response InteractionResponse `json:"response"`
+}
+
+type InteractionRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  url.Values      `json:"query,omitempty"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type InteractionResponse struct {
+	Status int             `json:"status"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	// Text holds a body that is not JSON.
+	Text string `json:"text,omitempty"`
+}
+
+// NewCassette returns an empty cassette that is written to path by Save.
+func NewCassette(path string) *Cassette {
+	return &Cassette{Path: path}
+}
+
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cassette{Path: path}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	c.used = make([]bool, len(c.Interactions))
+
+	return c, nil
+}
+
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.Path, append(b, '\n'), 0o644)
+}
+
+// Record returns middleware appending every answered call to the cassette.
+// Add it last with Manager.Use, so it sees the calls as they are sent.
+func (c *Cassette) Record() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, call *Call) (*CallResult, error) {
+			result, err := next.Do(ctx, call)
+			if result != nil {
+				c.add(call, result)
+			}
+			return result, err
+		})
+	}
+}
+
+// Replay returns middleware answering every call from the cassette instead
+// of the API. A call matches an interaction with the same method, the same
+// path once ids are replaced by {id}, the same query and the same JSON body,
+// preferring the one with the very same path. Interactions are used up in
+// the order they were recorded, so repeated calls like job polls and pages
+// replay as they happened. Once used up, the last matching interaction is
+// repeated. Calls matching nothing fail with ErrNoInteraction. Recorded
+// errors name the URL below m.BaseURL, as they do when m calls the API.
+func (c *Cassette) Replay(m *Manager) Middleware {
+	return func(Doer) Doer {
+		return DoerFunc(func(ctx context.Context, call *Call) (*CallResult, error) {
+			interaction, err := c.match(call)
+			if err != nil {
+				return nil, err
+			}
+
+			response := interaction.Response
+			result := &CallResult{Status: response.Status, Header: response.Header.Clone(), Body: []byte(response.Body)}
+			if response.Text != "" {
+				result.Body = []byte(response.Text)
+			}
+			if result.Header == nil {
+				result.Header = http.Header{}
+			}
+
+			if (result.Status < 200 || result.Status > 299) && result.Status != http.StatusNotModified {
+				requestUrl, _ := url.JoinPath(m.BaseURL, call.Path)
+				return result, newApiError(requestUrl, result.Status, result.Body)
+			}
+			return result, nil
+		})
+	}
+}
+
+func (c *Cassette) add(call *Call, result *CallResult) {
+	interaction := &Interaction{
+		Request: InteractionRequest{
+			Method: call.Method,
+			Path:   call.Path,
+			Query:  call.Query,
+			Header: call.Header.Clone(),
+		},
+		Response: InteractionResponse{
+			Status: result.Status,
+			Header: result.Header.Clone(),
+		},
+	}
+	if interaction.Request.Header.Get("Authorization") != "" {
+		interaction.Request.Header.Set("Authorization", "Bearer "+redacted)
+	}
+	for _, key := range unrecordedHeaders {
+		interaction.Response.Header.Del(key)
+	}
+	if len(call.Payload) > 0 {
+		interaction.Request.Body, _ = redactJSON(call.Payload)
+	}
+	if len(result.Body) > 0 {
+		if body, ok := redactJSON(result.Body); ok {
+			interaction.Response.Body = body
+		} else {
+			interaction.Response.Text = redactText(string(result.Body))
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Interactions = append(c.Interactions, interaction)
+	c.used = append(c.used, false)
+}
+
+// unrecordedHeaders are left out of recorded responses, they carry
+// credentials that are of no use on replay.
+var unrecordedHeaders = []string{"Set-Cookie", "Cookie", "Authorization"}
+
+func (c *Cassette) match(call *Call) (*Interaction, error) {
+	route := parseOperation(call.Method, call.Path).Route
+	query := call.Query.Encode()
+	body, _ := redactJSON(call.Payload)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	found, last := -1, -1
+	for i, interaction := range c.Interactions {
+		request := interaction.Request
+		if request.Method != call.Method ||
+			parseOperation(request.Method, request.Path).Route != route ||
+			request.Query.Encode() != query ||
+			!sameJSON(request.Body, body) {
+			continue
+		}
+
+		samePath := strings.Trim(request.Path, "/") == strings.Trim(call.Path, "/")
+		if last < 0 || samePath {
+			last = i
+		}
+		if c.used[i] {
+			continue
+		}
+		if samePath {
+			found = i
+			break
+		}
+		if found < 0 {
+			found = i
+		}
+	}
+
+	if found < 0 {
+		found = last
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, call.Method, call.Path)
+	}
+	c.used[found] = true
+
+	return c.Interactions[found], nil
+}
+
+// redactJSON returns the JSON document b compacted, with the values of
+// sensitive keys replaced. ok is false when b is not JSON.
+func redactJSON(b []byte) (redactedBody json.RawMessage, ok bool) {
+	if len(b) == 0 {
+		return nil, true
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+
+	redactedBody, err := json.Marshal(redactValue(value))
+	return redactedBody, err == nil
+}
+
+func sameJSON(a json.RawMessage, b json.RawMessage) bool {
+	a, _ = redactJSON(a)
+	b, _ = redactJSON(b)
+	return bytes.Equal(a, b)
+}
+
+var sensitiveLine = regexp.MustCompile(`(?m)^([ \t]*-?[ \t]*"?([A-Za-z_-]+)"?[ \t]*[:=][ \t]*).+$`)
+
+// redactText replaces the values of sensitive keys in YAML or key=value
+// text such as kubeconfig files.
+func redactText(text string) string {
+	return sensitiveLine.ReplaceAllStringFunc(text, func(line string) string {
+		parts := sensitiveLine.FindStringSubmatch(line)
+		if !sensitiveKeys[strings.ToLower(parts[2])] {
+			return line
+		}
+		return parts[1] + redacted
+	})
+}
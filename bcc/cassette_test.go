@@ -0,0 +1,268 @@
+package bcc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+// answer answers every call with status, header and body instead of
+// passing it on.
+func answer(status int, header http.Header, body string) bcc.Middleware {
+	return func(bcc.Doer) bcc.Doer {
+		return bcc.DoerFunc(func(ctx context.Context, call *bcc.Call) (*bcc.CallResult, error) {
+			return &bcc.CallResult{Status: status, Header: header.Clone(), Body: []byte(body)}, nil
+		})
+	}
+}
+
+// newCassetteServer serves vdc1 with the storage profile sp1 and the vms
+// vm0 to vm4.
+func newCassetteServer(t *testing.T) *bcctest.Server {
+	t.Helper()
+
+	s, _ := newServer(t, mainVdc, object("storage_profile", bcctest.Object{"id": "sp1", "name": "ssd"}), vms(5, "vdc1"))
+	s.JobPolls = 2
+
+	return s
+}
+
+func TestCassetteReplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(m *bcc.Manager)
+		run      func(m *bcc.Manager) (string, error)
+		wantCode int
+	}{
+		{
+			name: "object",
+			run: func(m *bcc.Manager) (string, error) {
+				vm, err := m.GetVm("vm1")
+				if err != nil {
+					return "", err
+				}
+				return vm.Name, nil
+			},
+		},
+		{
+			name: "pages",
+			run: func(m *bcc.Manager) (string, error) {
+				vms, err := bcc.Collect(m.AllVms(bcc.VmListOptions{PageSize: 2}))
+				names := make([]string, 0, len(vms))
+				for _, vm := range vms {
+					names = append(names, vm.Name)
+				}
+				return strings.Join(names, ","), err
+			},
+		},
+		{
+			name: "job polls",
+			run: func(m *bcc.Manager) (string, error) {
+				vm, err := m.GetVm("vm1")
+				if err != nil {
+					return "", err
+				}
+				before := vm.Power
+				if err := vm.PowerOff(); err != nil {
+					return "", err
+				}
+				if vm, err = m.GetVm("vm1"); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("power %t, then %t", before, vm.Power), nil
+			},
+		},
+		{
+			name: "api error",
+			run: func(m *bcc.Manager) (string, error) {
+				_, err := m.GetVm("missing")
+				return "", err
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:  "cache hits",
+			setup: func(m *bcc.Manager) { m.Cache = bcc.NewMemoryCache() },
+			run: func(m *bcc.Manager) (string, error) {
+				vdc, err := m.GetVdc("vdc1")
+				if err != nil {
+					return "", err
+				}
+				var names []string
+				for i := 0; i < 2; i++ {
+					profiles, err := vdc.GetStorageProfiles()
+					if err != nil {
+						return "", err
+					}
+					for _, profile := range profiles {
+						names = append(names, profile.Name)
+					}
+				}
+				return strings.Join(names, ","), nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newCassetteServer(t)
+			path := filepath.Join(t.TempDir(), "cassette.json")
+
+			recorder := s.Manager()
+			if tt.setup != nil {
+				tt.setup(recorder)
+			}
+			cassette := bcc.NewCassette(path)
+			recorder.Use(cassette.Record())
+			recorded, recordErr := tt.run(recorder)
+			if err := cassette.Save(); err != nil {
+				t.Fatalf("Save: %s", err)
+			}
+
+			sent := len(s.Requests())
+			loaded, err := bcc.LoadCassette(path)
+			if err != nil {
+				t.Fatalf("LoadCassette: %s", err)
+			}
+			replayer := s.Manager()
+			if tt.setup != nil {
+				tt.setup(replayer)
+			}
+			replayer.Use(loaded.Replay(replayer))
+			replayed, replayErr := tt.run(replayer)
+
+			if replayed != recorded {
+				t.Errorf("replayed %q, recorded %q", replayed, recorded)
+			}
+			for _, err := range []error{recordErr, replayErr} {
+				var apiErr *bcc.ApiError
+				switch {
+				case tt.wantCode == 0 && err != nil:
+					t.Errorf("error = %v, want none", err)
+				case tt.wantCode != 0 && (!errors.As(err, &apiErr) || apiErr.Code() != tt.wantCode):
+					t.Errorf("error = %v, want an ApiError %d", err, tt.wantCode)
+				}
+			}
+			// the body is compacted on record, the url must match
+			if tt.wantCode != 0 && recordErr != nil && replayErr != nil {
+				replayedUrl, _, _ := strings.Cut(replayErr.Error(), "\n")
+				recordedUrl, _, _ := strings.Cut(recordErr.Error(), "\n")
+				if replayedUrl != recordedUrl {
+					t.Errorf("replayed error %q, recorded %q", replayedUrl, recordedUrl)
+				}
+			}
+			if got := len(s.Requests()); got != sent {
+				t.Errorf("replay sent %d requests to the server", got-sent)
+			}
+		})
+	}
+}
+
+func TestCassetteNoInteraction(t *testing.T) {
+	s := newCassetteServer(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder := s.Manager()
+	cassette := bcc.NewCassette(path)
+	recorder.Use(cassette.Record())
+	if _, err := recorder.GetVm("vm1"); err != nil {
+		t.Fatalf("GetVm: %s", err)
+	}
+	if err := cassette.Save(); err != nil {
+		t.Fatalf("Save: %s", err)
+	}
+
+	loaded, err := bcc.LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette: %s", err)
+	}
+	replayer := s.Manager()
+	replayer.Use(loaded.Replay(replayer))
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{name: "recorded", run: func() error { _, err := replayer.GetVm("vm1"); return err }},
+		{name: "repeated", run: func() error { _, err := replayer.GetVm("vm1"); return err }},
+		{name: "other id", run: func() error { _, err := replayer.GetVm("vm2"); return err }},
+		{name: "other route", run: func() error { _, err := replayer.GetVdc("vdc1"); return err }, wantErr: bcc.ErrNoInteraction},
+		{
+			name:    "other method",
+			run:     func() error { return replayer.Delete("v1/vm/vm1", nil, nil) },
+			wantErr: bcc.ErrNoInteraction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCassetteRedaction(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		secret string
+	}{
+		{name: "json body", body: `{"access_key": "AK", "secret_key": "s3cr3t-key"}`, secret: "s3cr3t-key"},
+		{name: "nested json", body: `{"items": [{"name": "app", "password": "s3cr3t-pass"}]}`, secret: "s3cr3t-pass"},
+		{name: "kubeconfig", body: "users:\n- name: admin\n  user:\n    token: s3cr3t-kube\n", secret: "s3cr3t-kube"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newCassetteServer(t)
+			path := filepath.Join(t.TempDir(), "cassette.json")
+
+			m := s.Manager()
+			m.Token = "s3cr3t-token"
+			cassette := bcc.NewCassette(path)
+			header := http.Header{
+				"Content-Type":  {"application/json"},
+				"Set-Cookie":    {"sessionid=s3cr3t-session; HttpOnly"},
+				"Cookie":        {"csrftoken=s3cr3t-csrf"},
+				"Authorization": {"Bearer s3cr3t-echo"},
+			}
+			m.Use(cassette.Record(), answer(http.StatusOK, header, tt.body))
+
+			var raw []byte
+			payload := map[string]interface{}{"name": "app", "password": "s3cr3t-payload"}
+			if err := m.Request(http.MethodPost, "v1/s3_storage", payload, &raw); err != nil {
+				t.Fatalf("Request: %s", err)
+			}
+			if err := cassette.Save(); err != nil {
+				t.Fatalf("Save: %s", err)
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, secret := range []string{"s3cr3t-token", "s3cr3t-payload", "s3cr3t-session", "s3cr3t-csrf", "s3cr3t-echo", tt.secret} {
+				if strings.Contains(string(b), secret) {
+					t.Errorf("cassette holds %q:\n%s", secret, b)
+				}
+			}
+			if !strings.Contains(string(b), "application/json") {
+				t.Errorf("cassette lost the Content-Type header:\n%s", b)
+			}
+			if !strings.Contains(string(b), "[REDACTED]") {
+				t.Errorf("cassette has no redacted value:\n%s", b)
+			}
+		})
+	}
+}
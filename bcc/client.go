@@ -1,7 +1,8 @@
 package bcc
 
 import (
-	"log"
+	"context"
+	"iter"
 	"net/url"
 )
 
@@ -14,26 +15,37 @@ type Client struct {
 }
 
 func (m *Manager) GetClients(extraArgs ...Arguments) (clients []*Client, err error) {
-	path := "v1/client"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetClientsCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &clients); err != nil {
-		log.Printf("[REQUEST-ERROR] get-clients was failed: %s", err)
-	} else {
-		for i := range clients {
-			clients[i].manager = m
-		}
+func (m *Manager) GetClientsCtx(ctx context.Context, extraArgs ...Arguments) (clients []*Client, err error) {
+	if clients, err = Collect(m.AllClientsCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-clients was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllClients(extraArgs ...ListOptions) iter.Seq2[*Client, error] {
+	return m.AllClientsCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllClientsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Client, error] {
+	path := "v1/client"
+	return listItems(m.WithContext(ctx), path, typedQuery[ClientListOptions](extraArgs), func(client *Client) {
+		client.manager = m
+	})
+}
+
 func (m *Manager) GetClient(id string) (client *Client, err error) {
+	return m.GetClientCtx(m.ctx, id)
+}
+
+func (m *Manager) GetClientCtx(ctx context.Context, id string) (client *Client, err error) {
 	path, _ := url.JoinPath("v1/client", id)
 
-	if err = m.Get(path, Defaults(), &client); err != nil {
-		log.Printf("[REQUEST-ERROR] get-client with id='%s' was failed: %s", id, err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &client); err != nil {
+		m.log("[REQUEST-ERROR] get-client with id='%s' was failed: %s", id, err)
 	} else {
 		client.manager = m
 	}
@@ -0,0 +1,300 @@
+package bcc
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultUserAgent       = "bcc-go"
+	DefaultRequestTimeout  = LockTimeout * time.Second
+	DefaultRequestInterval = RetryTime * time.Millisecond
+	DefaultJobTimeout      = TaskTimeout * time.Second
+)
+
+// Environment variables read by LoadConfig. They take precedence over the
+// values of the profile file.
+const (
+	EnvToken           = "BCC_TOKEN"
+	EnvBaseURL         = "BCC_BASE_URL"
+	EnvCACert          = "BCC_CA_CERT"
+	EnvClientCert      = "BCC_CLIENT_CERT"
+	EnvClientKey       = "BCC_CLIENT_KEY"
+	EnvInsecure        = "BCC_INSECURE"
+	EnvRequestTimeout  = "BCC_REQUEST_TIMEOUT"
+	EnvRequestInterval = "BCC_REQUEST_INTERVAL"
+	EnvJobTimeout      = "BCC_JOB_TIMEOUT"
+	EnvProfile         = "BCC_PROFILE"
+	EnvConfigFile      = "BCC_CONFIG"
+)
+
+// Config describes how to reach the API. Durations are written as "30s",
+// "20m" and so on in the profile file.
+type Config struct {
+	Token           string        `yaml:"token"`
+	BaseURL         string        `yaml:"base_url"`
+	CACert          string        `yaml:"ca_cert"`
+	ClientCert      string        `yaml:"client_cert"`
+	ClientKey       string        `yaml:"client_key"`
+	Insecure        bool          `yaml:"insecure"`
+	RequestTimeout  time.Duration `yaml:"request_timeout"`
+	RequestInterval time.Duration `yaml:"request_interval"`
+	JobTimeout      time.Duration `yaml:"job_timeout"`
+	UserAgent       string        `yaml:"user_agent"`
+}
+
+// configFile is the layout of ~/.config/bcc/config.yaml:
+//
+//	default_profile: prod
+//	profiles:
+//	  prod:
+//	    token: ...
+//	    base_url: https://cp.iteco.cloud
+//	    request_timeout: 20m
+type configFile struct {
+	DefaultProfile string             `yaml:"default_profile"`
+	Profiles       map[string]*Config `yaml:"profiles"`
+}
+
+// DefaultConfigPath returns the profile file location, which may be
+// overridden with BCC_CONFIG.
+func DefaultConfigPath() string {
+	if path := os.Getenv(EnvConfigFile); path != "" {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "bcc", "config.yaml")
+}
+
+// LoadConfig builds a Config from the profile file and the environment.
+// The profile is chosen by the profile argument, then BCC_PROFILE, then the
+// default_profile of the file. A missing file is not an error unless a
+// profile was asked for explicitly.
+func LoadConfig(profile string) (*Config, error) {
+	return LoadConfigFile(DefaultConfigPath(), profile)
+}
+
+// LoadConfigFile is LoadConfig with an explicit profile file path.
+func LoadConfigFile(path string, profile string) (*Config, error) {
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+
+	cfg := new(Config)
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var file configFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse config file %s", path)
+		}
+
+		if profile == "" {
+			profile = file.DefaultProfile
+		}
+		if profile != "" {
+			p, ok := file.Profiles[profile]
+			if !ok || p == nil {
+				return nil, fmt.Errorf("profile %q not found in %s", profile, path)
+			}
+			*cfg = *p
+		}
+	case os.IsNotExist(err) && profile == "":
+	default:
+		return nil, errors.Wrapf(err, "Failed to read config file %s", path)
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (cfg *Config) applyEnv() error {
+	for env, field := range map[string]*string{
+		EnvToken:      &cfg.Token,
+		EnvBaseURL:    &cfg.BaseURL,
+		EnvCACert:     &cfg.CACert,
+		EnvClientCert: &cfg.ClientCert,
+		EnvClientKey:  &cfg.ClientKey,
+	} {
+		if value := os.Getenv(env); value != "" {
+			*field = value
+		}
+	}
+
+	if value := os.Getenv(EnvInsecure); value != "" {
+		insecure, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid %s", EnvInsecure)
+		}
+		cfg.Insecure = insecure
+	}
+
+	for env, field := range map[string]*time.Duration{
+		EnvRequestTimeout:  &cfg.RequestTimeout,
+		EnvRequestInterval: &cfg.RequestInterval,
+		EnvJobTimeout:      &cfg.JobTimeout,
+	} {
+		if value := os.Getenv(env); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return errors.Wrapf(err, "Invalid %s", env)
+			}
+			*field = d
+		}
+	}
+
+	return nil
+}
+
+// Option adjusts a Manager built by NewManagerFromConfig.
+type Option func(*Manager) error
+
+func WithBaseURL(baseURL string) Option {
+	return func(m *Manager) error {
+		m.BaseURL = baseURL
+		return nil
+	}
+}
+
+// WithHTTPClient replaces the client built from the certificate settings
+// of the config.
+func WithHTTPClient(client *http.Client) Option {
+	return func(m *Manager) error {
+		if client == nil {
+			return fmt.Errorf("http client must not be nil")
+		}
+		m.Client = client
+		return nil
+	}
+}
+
+// WithTimeouts sets how long to wait for a locked object and for a job.
+// Zero keeps the current value.
+func WithTimeouts(request time.Duration, job time.Duration) Option {
+	return func(m *Manager) error {
+		if request != 0 {
+			m.RequestTimeout = request
+		}
+		if job != 0 {
+			m.JobTimeout = job
+		}
+		return nil
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(m *Manager) error {
+		m.UserAgent = userAgent
+		return nil
+	}
+}
+
+// WithTracer traces every call of the manager, see Tracer.
+func WithTracer(tracer Tracer) Option {
+	return func(m *Manager) error {
+		m.Tracer = tracer
+		return nil
+	}
+}
+
+// WithMetrics reports the calls and job waits of the manager to metrics.
+func WithMetrics(metrics Metrics) Option {
+	return func(m *Manager) error {
+		m.Metrics = metrics
+		return nil
+	}
+}
+
+// WithCache answers the catalog endpoints from cache, see Cache. ttl
+// overrides DefaultCacheTTL per resource.
+func WithCache(cache Cache, ttl map[CacheResource]time.Duration) Option {
+	return func(m *Manager) error {
+		m.Cache = cache
+		m.CacheTTL = ttl
+		return nil
+	}
+}
+
+// NewManagerFromConfig builds a Manager from cfg, filling in defaults for
+// everything left empty, applies opts and validates the result.
+func NewManagerFromConfig(cfg *Config, opts ...Option) (*Manager, error) {
+	if cfg == nil {
+		cfg = new(Config)
+	}
+
+	m, err := NewManager(cfg.Token, cfg.CACert, cfg.ClientCert, cfg.ClientKey, cfg.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.BaseURL != "" {
+		m.BaseURL = cfg.BaseURL
+	}
+	if cfg.RequestTimeout != 0 {
+		m.RequestTimeout = cfg.RequestTimeout
+	}
+	if cfg.RequestInterval != 0 {
+		m.RequestInterval = cfg.RequestInterval
+	}
+	if cfg.JobTimeout != 0 {
+		m.JobTimeout = cfg.JobTimeout
+	}
+	if cfg.UserAgent != "" {
+		m.UserAgent = cfg.UserAgent
+	}
+
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Manager) validate() error {
+	if m.Token == "" {
+		return fmt.Errorf("invalid config: token is empty, set it in the profile or %s", EnvToken)
+	}
+
+	u, err := url.Parse(m.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid config: base url %q must be an absolute http(s) URL", m.BaseURL)
+	}
+
+	if m.RequestTimeout <= 0 {
+		return fmt.Errorf("invalid config: request timeout must be positive, got %s", m.RequestTimeout)
+	}
+	if m.RequestInterval <= 0 || m.RequestInterval > m.RequestTimeout {
+		return fmt.Errorf("invalid config: request interval must be positive and not above the request timeout, got %s", m.RequestInterval)
+	}
+	if m.JobTimeout < 0 {
+		return fmt.Errorf("invalid config: job timeout must not be negative, got %s", m.JobTimeout)
+	}
+	if m.UserAgent == "" {
+		return fmt.Errorf("invalid config: user agent is empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package bcc_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+const testConfigFile = `
+default_profile: prod
+profiles:
+  prod:
+    token: prod-token
+    base_url: https://cp.example.com
+    request_timeout: 20m
+    user_agent: deployer/1.0
+  stage:
+    token: stage-token
+    base_url: https://stage.example.com
+    insecure: true
+    job_timeout: 90s
+`
+
+// clearConfigEnv isolates a test from BCC_* variables set by the caller.
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+
+	for _, env := range []string{
+		bcc.EnvToken, bcc.EnvBaseURL, bcc.EnvCACert, bcc.EnvClientCert, bcc.EnvClientKey,
+		bcc.EnvInsecure, bcc.EnvRequestTimeout, bcc.EnvRequestInterval, bcc.EnvJobTimeout,
+		bcc.EnvProfile, bcc.EnvConfigFile,
+	} {
+		t.Setenv(env, "")
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		profile string
+		env     map[string]string
+		want    bcc.Config
+		wantErr bool
+	}{
+		{
+			name: "default profile",
+			file: testConfigFile,
+			want: bcc.Config{Token: "prod-token", BaseURL: "https://cp.example.com", RequestTimeout: 20 * time.Minute, UserAgent: "deployer/1.0"},
+		},
+		{
+			name:    "explicit profile",
+			file:    testConfigFile,
+			profile: "stage",
+			want:    bcc.Config{Token: "stage-token", BaseURL: "https://stage.example.com", Insecure: true, JobTimeout: 90 * time.Second},
+		},
+		{
+			name: "profile from environment",
+			file: testConfigFile,
+			env:  map[string]string{bcc.EnvProfile: "stage"},
+			want: bcc.Config{Token: "stage-token", BaseURL: "https://stage.example.com", Insecure: true, JobTimeout: 90 * time.Second},
+		},
+		{
+			name: "environment overrides profile",
+			file: testConfigFile,
+			env:  map[string]string{bcc.EnvToken: "env-token", bcc.EnvRequestTimeout: "5m", bcc.EnvInsecure: "true"},
+			want: bcc.Config{Token: "env-token", BaseURL: "https://cp.example.com", Insecure: true, RequestTimeout: 5 * time.Minute, UserAgent: "deployer/1.0"},
+		},
+		{
+			name: "missing file",
+			env:  map[string]string{bcc.EnvToken: "env-token"},
+			want: bcc.Config{Token: "env-token"},
+		},
+		{name: "missing file with profile", profile: "prod", wantErr: true},
+		{name: "unknown profile", file: testConfigFile, profile: "dev", wantErr: true},
+		{name: "malformed file", file: "profiles: [", wantErr: true},
+		{name: "invalid duration", file: testConfigFile, env: map[string]string{bcc.EnvJobTimeout: "soon"}, wantErr: true},
+		{name: "invalid insecure", file: testConfigFile, env: map[string]string{bcc.EnvInsecure: "maybe"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearConfigEnv(t)
+			for env, value := range tt.env {
+				t.Setenv(env, value)
+			}
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if tt.file != "" {
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, err := bcc.LoadConfigFile(path, tt.profile)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("LoadConfigFile = %+v, want an error", cfg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfigFile: %s", err)
+			}
+			if *cfg != tt.want {
+				t.Errorf("LoadConfigFile = %+v, want %+v", *cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewManagerFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *bcc.Config
+		opts    []bcc.Option
+		wantErr bool
+		check   func(t *testing.T, m *bcc.Manager)
+	}{
+		{
+			name: "defaults",
+			cfg:  &bcc.Config{Token: "token"},
+			check: func(t *testing.T, m *bcc.Manager) {
+				if m.UserAgent != bcc.DefaultUserAgent || m.RequestTimeout != bcc.DefaultRequestTimeout || m.JobTimeout != bcc.DefaultJobTimeout {
+					t.Errorf("manager = %q %s %s, want the defaults", m.UserAgent, m.RequestTimeout, m.JobTimeout)
+				}
+			},
+		},
+		{
+			name: "options win over config",
+			cfg:  &bcc.Config{Token: "token", UserAgent: "from-config", JobTimeout: time.Minute},
+			opts: []bcc.Option{bcc.WithUserAgent("from-option"), bcc.WithTimeouts(0, time.Hour)},
+			check: func(t *testing.T, m *bcc.Manager) {
+				if m.UserAgent != "from-option" || m.JobTimeout != time.Hour {
+					t.Errorf("manager = %q %s, want from-option and 1h", m.UserAgent, m.JobTimeout)
+				}
+			},
+		},
+		{name: "no token", cfg: &bcc.Config{}, wantErr: true},
+		{name: "nil config", wantErr: true},
+		{name: "relative base url", cfg: &bcc.Config{Token: "token", BaseURL: "cp.example.com"}, wantErr: true},
+		{name: "interval above timeout", cfg: &bcc.Config{Token: "token", RequestTimeout: time.Second, RequestInterval: time.Minute}, wantErr: true},
+		{name: "negative job timeout", cfg: &bcc.Config{Token: "token", JobTimeout: -time.Second}, wantErr: true},
+		{name: "empty user agent", cfg: &bcc.Config{Token: "token"}, opts: []bcc.Option{bcc.WithUserAgent("")}, wantErr: true},
+		{name: "nil http client", cfg: &bcc.Config{Token: "token"}, opts: []bcc.Option{bcc.WithHTTPClient(nil)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := bcc.NewManagerFromConfig(tt.cfg, tt.opts...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("NewManagerFromConfig succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewManagerFromConfig: %s", err)
+			}
+			tt.check(t, m)
+		})
+	}
+}
+
+func TestUserAgentSent(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []bcc.Option
+		want string
+	}{
+		{name: "default", want: bcc.DefaultUserAgent},
+		{name: "configured", opts: []bcc.Option{bcc.WithUserAgent("terraform-provider-bcc/2.1")}, want: "terraform-provider-bcc/2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := bcctest.NewServer()
+			defer s.Close()
+			s.Add("vdc", bcctest.Object{"id": "vdc1", "name": "main"})
+			s.Add("vm", bcctest.Object{"id": "vm1", "name": "web", "vdc": "vdc1"})
+
+			opts := append([]bcc.Option{bcc.WithHTTPClient(s.Client())}, tt.opts...)
+			m, err := bcc.NewManagerFromConfig(&bcc.Config{Token: s.Token, BaseURL: s.URL}, opts...)
+			if err != nil {
+				t.Fatalf("NewManagerFromConfig: %s", err)
+			}
+
+			vm, err := m.GetVm("vm1")
+			if err != nil {
+				t.Fatalf("GetVm: %s", err)
+			}
+			if err := vm.PowerOff(); err != nil {
+				t.Fatalf("PowerOff: %s", err)
+			}
+
+			for _, r := range s.Requests() {
+				if got := r.Header.Get("User-Agent"); got != tt.want {
+					t.Errorf("%s %s sent User-Agent %q, want %q", r.Method, r.Path, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,9 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"iter"
 	"net/url"
 )
 
@@ -15,6 +16,7 @@ type TmpVm struct {
 	Power    bool    `json:"power"`
 	Platform string  `json:"platform,omitempty"`
 	Vdc      *Vdc    `json:"vdc"`
+	Locked   bool    `json:"locked,omitempty"`
 }
 
 type Disk struct {
@@ -38,35 +40,61 @@ func NewDisk(name string, size int, storageProfile *StorageProfile) Disk {
 }
 
 func (m *Manager) GetDisks(extraArgs ...Arguments) (disks []*Disk, err error) {
-	path := "v1/disk"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetDisksCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &disks); err != nil {
-		log.Printf("[REQUEST-ERROR] get-disks was failed: %s", err)
-	} else {
-		for i := range disks {
-			disks[i].manager = m
-		}
+func (m *Manager) GetDisksCtx(ctx context.Context, extraArgs ...Arguments) (disks []*Disk, err error) {
+	if disks, err = Collect(m.AllDisksCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-disks was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllDisks(extraArgs ...ListOptions) iter.Seq2[*Disk, error] {
+	return m.AllDisksCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllDisksCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Disk, error] {
+	path := "v1/disk"
+	return listItems(m.WithContext(ctx), path, typedQuery[DiskListOptions](extraArgs), func(disk *Disk) {
+		disk.manager = m
+	})
+}
+
 func (v *Vdc) GetDisks(extraArgs ...Arguments) (disks []*Disk, err error) {
+	return v.GetDisksCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetDisksCtx(ctx context.Context, extraArgs ...Arguments) (disks []*Disk, err error) {
 	args := Arguments{
 		"vdc": v.ID,
 	}
 	args.merge(extraArgs)
-	disks, err = v.manager.GetDisks(args)
+	disks, err = v.manager.GetDisksCtx(ctx, args)
 	return
 }
 
+func (v *Vdc) AllDisks(extraArgs ...ListOptions) iter.Seq2[*Disk, error] {
+	return v.AllDisksCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllDisksCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Disk, error] {
+	args := Arguments{
+		"vdc": v.ID,
+	}
+	return v.manager.AllDisksCtx(ctx, args, listQuery(extraArgs))
+}
+
 func (m *Manager) GetDisk(id string) (disk *Disk, err error) {
+	return m.GetDiskCtx(m.ctx, id)
+}
+
+func (m *Manager) GetDiskCtx(ctx context.Context, id string) (disk *Disk, err error) {
 	path, _ := url.JoinPath("v1/disk", id)
 
-	if err = m.Get(path, Defaults(), &disk); err != nil {
-		log.Printf("[REQUEST-ERROR]: getting disk with id='%s' was failed: %s]", id, err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &disk); err != nil {
+		m.log("[REQUEST-ERROR]: getting disk with id='%s' was failed: %s]", id, err)
 	} else {
 		disk.manager = m
 	}
@@ -75,6 +103,10 @@ func (m *Manager) GetDisk(id string) (disk *Disk, err error) {
 }
 
 func (v *Vdc) CreateDisk(disk *Disk) (err error) {
+	return v.CreateDiskCtx(v.manager.ctx, disk)
+}
+
+func (v *Vdc) CreateDiskCtx(ctx context.Context, disk *Disk) (err error) {
 	path := "v1/disk"
 	args := &struct {
 		Name           string   `json:"name"`
@@ -97,8 +129,8 @@ func (v *Vdc) CreateDisk(disk *Disk) (err error) {
 		args.Vdc = nil
 	}
 
-	if err = v.manager.Request("POST", path, args, &disk); err != nil {
-		log.Printf("[REQUEST-ERROR] disk create was failed: %s", err)
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, &disk); err != nil {
+		v.manager.log("[REQUEST-ERROR] disk create was failed: %s", err)
 	} else {
 		disk.manager = v.manager
 	}
@@ -107,6 +139,10 @@ func (v *Vdc) CreateDisk(disk *Disk) (err error) {
 }
 
 func (v *Vm) AttachDisk(disk *Disk) (err error) {
+	return v.AttachDiskCtx(v.manager.ctx, disk)
+}
+
+func (v *Vm) AttachDiskCtx(ctx context.Context, disk *Disk) (err error) {
 	path := fmt.Sprintf("v1/disk/%s/attach", disk.ID)
 
 	args := &struct {
@@ -115,8 +151,8 @@ func (v *Vm) AttachDisk(disk *Disk) (err error) {
 		Vm: v.ID,
 	}
 
-	if err = v.manager.Request("POST", path, args, nil); err != nil {
-		log.Printf("[REQUEST-ERROR] disk attach with id ='%s' was failed : %s", disk.ID, err)
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, nil); err != nil {
+		v.manager.log("[REQUEST-ERROR] disk attach with id ='%s' was failed : %s", disk.ID, err)
 	} else {
 		v.Disks = append(v.Disks, disk)
 	}
@@ -125,10 +161,14 @@ func (v *Vm) AttachDisk(disk *Disk) (err error) {
 }
 
 func (v *Vm) DetachDisk(disk *Disk) (err error) {
+	return v.DetachDiskCtx(v.manager.ctx, disk)
+}
+
+func (v *Vm) DetachDiskCtx(ctx context.Context, disk *Disk) (err error) {
 	path := fmt.Sprintf("v1/disk/%s/detach", disk.ID)
 
-	if err = v.manager.Request("POST", path, nil, nil); err != nil {
-		log.Printf("[REQUEST-ERROR] disk detach with id='%s' was failed: %s", disk.ID, err)
+	if err = v.manager.WithContext(ctx).Request("POST", path, nil, nil); err != nil {
+		v.manager.log("[REQUEST-ERROR] disk detach with id='%s' was failed: %s", disk.ID, err)
 	} else {
 		for i, vmDisk := range v.Disks {
 			if vmDisk == disk {
@@ -142,16 +182,24 @@ func (v *Vm) DetachDisk(disk *Disk) (err error) {
 }
 
 func (d *Disk) UpdateStorageProfile(storageProfile StorageProfile) (err error) {
+	return d.UpdateStorageProfileCtx(d.manager.ctx, storageProfile)
+}
+
+func (d *Disk) UpdateStorageProfileCtx(ctx context.Context, storageProfile StorageProfile) (err error) {
 	d.StorageProfile = &storageProfile
 
-	if err = d.Update(); err != nil {
-		log.Printf("[REQUEST-ERROR]: storage-profile update was failed %s", err)
+	if err = d.UpdateCtx(ctx); err != nil {
+		d.manager.log("[REQUEST-ERROR]: storage-profile update was failed %s", err)
 	}
 
 	return nil
 }
 
 func (d *Disk) Update() (err error) {
+	return d.UpdateCtx(d.manager.ctx)
+}
+
+func (d *Disk) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/disk", d.ID)
 
 	args := &struct {
@@ -166,43 +214,59 @@ func (d *Disk) Update() (err error) {
 		Tags:           convertTagsToNames(d.Tags),
 	}
 
-	if err = d.manager.Request("PUT", path, args, d); err != nil {
-		log.Printf("[REQUEST-ERROR] disk update with id='%s' was failed: %s", d.ID, err)
+	if err = d.manager.WithContext(ctx).Request("PUT", path, args, d); err != nil {
+		d.manager.log("[REQUEST-ERROR] disk update with id='%s' was failed: %s", d.ID, err)
 	}
 
 	return nil
 }
 
 func (d *Disk) Rename(name string) error {
+	return d.RenameCtx(d.manager.ctx, name)
+}
+
+func (d *Disk) RenameCtx(ctx context.Context, name string) error {
 	d.Name = name
-	return d.Update()
+	return d.UpdateCtx(ctx)
 }
 
 func (d *Disk) Resize(size int) (err error) {
+	return d.ResizeCtx(d.manager.ctx, size)
+}
+
+func (d *Disk) ResizeCtx(ctx context.Context, size int) (err error) {
 	d.Size = size
 
-	if err = d.Update(); err != nil {
-		log.Printf("[REQUEST-ERROR] disk-resize with id='%s' was failed: %s", d.ID, err)
+	if err = d.UpdateCtx(ctx); err != nil {
+		d.manager.log("[REQUEST-ERROR] disk-resize with id='%s' was failed: %s", d.ID, err)
 	}
 
 	return
 }
 
 func (d *Disk) Delete() (err error) {
+	return d.DeleteCtx(d.manager.ctx)
+}
+
+func (d *Disk) DeleteCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/disk", d.ID)
 
-	if err = d.manager.Delete(path, Defaults(), nil); err != nil {
-		log.Printf("[REQUEST-ERROR] disk-delete with id='%s' was failed: %s", d.ID, err)
+	if err = d.manager.WithContext(ctx).Delete(path, Defaults(), nil); err != nil {
+		d.manager.log("[REQUEST-ERROR] disk-delete with id='%s' was failed: %s", d.ID, err)
 	}
 
 	return
 }
 
 func (d Disk) WaitLock() (err error) {
+	return d.WaitLockCtx(d.manager.ctx)
+}
+
+func (d Disk) WaitLockCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/disk", d.ID)
 
-	if err = loopWaitLock(d.manager, path); err != nil {
-		log.Printf("[REQUEST-ERROR] disk waitlock with id='%s' was failed: %s", d.ID, err)
+	if err = loopWaitLock(d.manager.WithContext(ctx), path); err != nil {
+		d.manager.log("[REQUEST-ERROR] disk waitlock with id='%s' was failed: %s", d.ID, err)
 	}
 
 	return
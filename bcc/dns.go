@@ -1,7 +1,8 @@
 package bcc
 
 import (
-	"log"
+	"context"
+	"iter"
 	"net/url"
 )
 
@@ -19,36 +20,63 @@ func NewDns(name string) Dns {
 }
 
 func (m *Manager) GetDnss(extraArgs ...Arguments) (dnss []*Dns, err error) {
-	path := "v1/dns"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetDnssCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &dnss); err != nil {
-		log.Printf("[REQUEST-ERROR] get-dns's was failed: %s", err)
-	} else {
-		for i := range dnss {
-			dnss[i].manager = m
-		}
+func (m *Manager) GetDnssCtx(ctx context.Context, extraArgs ...Arguments) (dnss []*Dns, err error) {
+	if dnss, err = Collect(m.AllDnssCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-dns's was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllDnss(extraArgs ...ListOptions) iter.Seq2[*Dns, error] {
+	return m.AllDnssCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllDnssCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Dns, error] {
+	path := "v1/dns"
+	return listItems(m.WithContext(ctx), path, typedQuery[DnsListOptions](extraArgs), func(dns *Dns) {
+		dns.manager = m
+	})
+}
+
 func (p *Project) GetDnss(extraArgs ...Arguments) (dns []*Dns, err error) {
+	return p.GetDnssCtx(p.manager.ctx, extraArgs...)
+}
+
+func (p *Project) GetDnssCtx(ctx context.Context, extraArgs ...Arguments) (dns []*Dns, err error) {
 	args := Arguments{
 		"project": p.ID,
 	}
 
 	args.merge(extraArgs)
-	dns, err = p.manager.GetDnss(args)
+	dns, err = p.manager.GetDnssCtx(ctx, args)
 	return
 }
 
+func (p *Project) AllDnss(extraArgs ...ListOptions) iter.Seq2[*Dns, error] {
+	return p.AllDnssCtx(p.manager.ctx, extraArgs...)
+}
+
+func (p *Project) AllDnssCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Dns, error] {
+	args := Arguments{
+		"project": p.ID,
+	}
+
+	return p.manager.AllDnssCtx(ctx, args, listQuery(extraArgs))
+}
+
 func (m *Manager) GetDns(id string) (dns *Dns, err error) {
+	return m.GetDnsCtx(m.ctx, id)
+}
+
+func (m *Manager) GetDnsCtx(ctx context.Context, id string) (dns *Dns, err error) {
 	path, _ := url.JoinPath("v1/dns", id)
 
-	if err = m.Get(path, Defaults(), &dns); err != nil {
-		log.Printf("[REQUEST-ERROR] get-dns with id='%s' was failed: %s", id, err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &dns); err != nil {
+		m.log("[REQUEST-ERROR] get-dns with id='%s' was failed: %s", id, err)
 	} else {
 		dns.manager = m
 	}
@@ -57,6 +85,10 @@ func (m *Manager) GetDns(id string) (dns *Dns, err error) {
 }
 
 func (p *Project) CreateDns(dns *Dns) (err error) {
+	return p.CreateDnsCtx(p.manager.ctx, dns)
+}
+
+func (p *Project) CreateDnsCtx(ctx context.Context, dns *Dns) (err error) {
 	path := "v1/dns"
 	args := &struct {
 		manager *Manager
@@ -71,8 +103,8 @@ func (p *Project) CreateDns(dns *Dns) (err error) {
 		Tags:    convertTagsToNames(dns.Tags),
 	}
 
-	if err = p.manager.Request("POST", path, args, &dns); err != nil {
-		log.Printf("[REQUEST-ERROR] create-dns failed: %s", err)
+	if err = p.manager.WithContext(ctx).Request("POST", path, args, &dns); err != nil {
+		p.manager.log("[REQUEST-ERROR] create-dns failed: %s", err)
 	} else {
 		dns.manager = p.manager
 	}
@@ -81,11 +113,19 @@ func (p *Project) CreateDns(dns *Dns) (err error) {
 }
 
 func (d *Dns) Delete() error {
+	return d.DeleteCtx(d.manager.ctx)
+}
+
+func (d *Dns) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/dns", d.ID)
-	return d.manager.Delete(path, Defaults(), nil)
+	return d.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (d *Dns) Update() (err error) {
+	return d.UpdateCtx(d.manager.ctx)
+}
+
+func (d *Dns) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/dns", d.ID)
 
 	args := &struct {
@@ -98,8 +138,8 @@ func (d *Dns) Update() (err error) {
 		Tags:    convertTagsToNames(d.Tags),
 	}
 
-	if err := d.manager.Request("PUT", path, args, d); err != nil {
-		log.Printf("[REQUEST-ERROR] update-dns failed: %s", err)
+	if err := d.manager.WithContext(ctx).Request("PUT", path, args, d); err != nil {
+		d.manager.log("[REQUEST-ERROR] update-dns failed: %s", err)
 	}
 
 	return
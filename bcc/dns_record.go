@@ -1,8 +1,9 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"iter"
 )
 
 type DnsRecord struct {
@@ -26,27 +27,50 @@ func NewDnsRecord(data string, flag int, host string, port int, priority int, ta
 }
 
 func (m *Manager) GetDnsRecords(dnsId string, extraArgs ...Arguments) (dnsRecord []*DnsRecord, err error) {
-	path := fmt.Sprintf("v1/dns/%s/dns_record", dnsId)
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetDnsRecordsCtx(m.ctx, dnsId, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &dnsRecord); err != nil {
-		log.Printf("[REQUEST-ERROR] get-dnsRecord's for dns with id='%s' was failed: %s", dnsId, err)
-	} else {
-		for i := range dnsRecord {
-			dnsRecord[i].manager = m
-		}
+func (m *Manager) GetDnsRecordsCtx(ctx context.Context, dnsId string, extraArgs ...Arguments) (dnsRecord []*DnsRecord, err error) {
+	if dnsRecord, err = Collect(m.AllDnsRecordsCtx(ctx, dnsId, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-dnsRecord's for dns with id='%s' was failed: %s", dnsId, err)
 	}
 
 	return
 }
 
+func (m *Manager) AllDnsRecords(dnsId string, extraArgs ...ListOptions) iter.Seq2[*DnsRecord, error] {
+	return m.AllDnsRecordsCtx(m.ctx, dnsId, extraArgs...)
+}
+
+func (m *Manager) AllDnsRecordsCtx(ctx context.Context, dnsId string, extraArgs ...ListOptions) iter.Seq2[*DnsRecord, error] {
+	path := fmt.Sprintf("v1/dns/%s/dns_record", dnsId)
+	return listItems(m.WithContext(ctx), path, typedQuery[DnsRecordListOptions](extraArgs), func(dnsRecord *DnsRecord) {
+		dnsRecord.manager = m
+	})
+}
+
 func (d *Dns) GetDnsRecords(extraArgs ...Arguments) (dnsRecord []*DnsRecord, err error) {
-	dnsRecord, err = d.manager.GetDnsRecords(d.ID, extraArgs...)
+	return d.GetDnsRecordsCtx(d.manager.ctx, extraArgs...)
+}
+
+func (d *Dns) GetDnsRecordsCtx(ctx context.Context, extraArgs ...Arguments) (dnsRecord []*DnsRecord, err error) {
+	dnsRecord, err = d.manager.GetDnsRecordsCtx(ctx, d.ID, extraArgs...)
 	return
 }
 
+func (d *Dns) AllDnsRecords(extraArgs ...ListOptions) iter.Seq2[*DnsRecord, error] {
+	return d.AllDnsRecordsCtx(d.manager.ctx, extraArgs...)
+}
+
+func (d *Dns) AllDnsRecordsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*DnsRecord, error] {
+	return d.manager.AllDnsRecordsCtx(ctx, d.ID, extraArgs...)
+}
+
 func (d *Dns) CreateDnsRecord(dnsRecord *DnsRecord) (err error) {
+	return d.CreateDnsRecordCtx(d.manager.ctx, dnsRecord)
+}
+
+func (d *Dns) CreateDnsRecordCtx(ctx context.Context, dnsRecord *DnsRecord) (err error) {
 	path := fmt.Sprintf("v1/dns/%s/record", d.ID)
 	args := &struct {
 		manager  *Manager
@@ -84,8 +108,8 @@ func (d *Dns) CreateDnsRecord(dnsRecord *DnsRecord) (err error) {
 		args.Port = &dnsRecord.Port
 	}
 
-	if err = d.manager.Request("POST", path, args, &dnsRecord); err != nil {
-		log.Printf("[REQUEST-ERROR] create-dnsRecord's was failed: %s", err)
+	if err = d.manager.WithContext(ctx).Request("POST", path, args, &dnsRecord); err != nil {
+		d.manager.log("[REQUEST-ERROR] create-dnsRecord's was failed: %s", err)
 	} else {
 		dnsRecord.manager = d.manager
 		dnsRecord.DnsZone = d.ID
@@ -95,10 +119,14 @@ func (d *Dns) CreateDnsRecord(dnsRecord *DnsRecord) (err error) {
 }
 
 func (d *Dns) GetDnsRecord(id string) (dnsRecord *DnsRecord, err error) {
+	return d.GetDnsRecordCtx(d.manager.ctx, id)
+}
+
+func (d *Dns) GetDnsRecordCtx(ctx context.Context, id string) (dnsRecord *DnsRecord, err error) {
 	path := fmt.Sprintf("v1/dns/%s/record/%s", d.ID, id)
 
-	if err = d.manager.Get(path, Defaults(), &dnsRecord); err != nil {
-		log.Printf("[REQUEST-ERROR] get-dnsRecord with id='%s' was failed: %s", id, err)
+	if err = d.manager.WithContext(ctx).Get(path, Defaults(), &dnsRecord); err != nil {
+		d.manager.log("[REQUEST-ERROR] get-dnsRecord with id='%s' was failed: %s", id, err)
 	} else {
 		dnsRecord.manager = d.manager
 		dnsRecord.DnsZone = d.ID
@@ -108,6 +136,10 @@ func (d *Dns) GetDnsRecord(id string) (dnsRecord *DnsRecord, err error) {
 }
 
 func (d *DnsRecord) Update() (err error) {
+	return d.UpdateCtx(d.manager.ctx)
+}
+
+func (d *DnsRecord) UpdateCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/dns/%s/record/%s", d.DnsZone, d.ID)
 	args := &struct {
 		Data     string  `json:"data"`
@@ -142,14 +174,18 @@ func (d *DnsRecord) Update() (err error) {
 		args.Port = &d.Port
 	}
 
-	if err = d.manager.Request("PUT", path, args, d); err != nil {
-		log.Printf("[REQUEST-ERROR] update-dnsRecord's was failed: %s", err)
+	if err = d.manager.WithContext(ctx).Request("PUT", path, args, d); err != nil {
+		d.manager.log("[REQUEST-ERROR] update-dnsRecord's was failed: %s", err)
 	}
 
 	return
 }
 
 func (d *DnsRecord) Delete() error {
+	return d.DeleteCtx(d.manager.ctx)
+}
+
+func (d *DnsRecord) DeleteCtx(ctx context.Context) error {
 	path := fmt.Sprintf("v1/dns/%s/record/%s", d.DnsZone, d.ID)
-	return d.manager.Delete(path, Defaults(), nil)
+	return d.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
@@ -2,31 +2,77 @@ package bcc
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 )
 
+// Sentinel errors matched by ApiError through errors.Is, e.g.
+//
+//	if errors.Is(err, bcc.ErrNotFound) { ... }
+var (
+	ErrNotFound      = errors.New("bcc: object not found")
+	ErrLocked        = errors.New("bcc: object locked")
+	ErrQuotaExceeded = errors.New("bcc: quota exceeded")
+	ErrValidation    = errors.New("bcc: validation failed")
+	ErrUnauthorized  = errors.New("bcc: unauthorized")
+	// ErrUnknownJobStatus is returned by Job.Wait for a status it cannot
+	// classify, see JobStatusDone.
+	ErrUnknownJobStatus = errors.New("bcc: unknown job status")
+)
+
+// quotaAliases lists the error aliases the API answers with when a request
+// would exceed a quota of the client, the project or the vdc.
+var quotaAliases = []string{
+	"quota_exceeded",
+	"client_quota_exceeded",
+	"project_quota_exceeded",
+	"vdc_quota_exceeded",
+}
+
 type ApiError struct {
-	msg          string
-	code         int
-	body         []byte
-	errorAliases []string
+	msg            string
+	code           int
+	body           []byte
+	errorAliases   []string
+	fieldErrors    map[string][]string
+	nonFieldErrors []string
 }
 
 func NewApiError(url string, resp *http.Response) error {
 	body, _ := io.ReadAll(resp.Body)
-	msg := fmt.Sprintf("HTTP request failure on %s:\n%d: %s", url, resp.StatusCode, string(body))
-	var parsedBody struct {
-		ErrorAliases []string `json:"error_alias"`
+	return newApiError(url, resp.StatusCode, body)
+}
+
+func newApiError(url string, code int, body []byte) *ApiError {
+	msg := fmt.Sprintf("HTTP request failure on %s:\n%d: %s", url, code, string(body))
+	e := &ApiError{
+		msg:         msg,
+		code:        code,
+		body:        body,
+		fieldErrors: make(map[string][]string),
 	}
-	json.Unmarshal(body, &parsedBody)
-	return &ApiError{
-		msg:          msg,
-		code:         resp.StatusCode,
-		body:         body,
-		errorAliases: parsedBody.ErrorAliases,
+
+	var parsedBody map[string]interface{}
+	if json.Unmarshal(body, &parsedBody) != nil {
+		return e
 	}
+
+	for key, value := range parsedBody {
+		switch key {
+		case "error_alias":
+			e.errorAliases = toStrings(value)
+		case "non_field_errors":
+			e.nonFieldErrors = toStrings(value)
+		case "detail", "details":
+		default:
+			collectFieldErrors(e.fieldErrors, key, value)
+		}
+	}
+
+	return e
 }
 
 func (e *ApiError) Error() string          { return e.msg }
@@ -34,3 +80,100 @@ func (e *ApiError) Message() string        { return e.msg }
 func (e *ApiError) Code() int              { return e.code }
 func (e *ApiError) Body() []byte           { return e.body }
 func (e *ApiError) ErrorAliases() []string { return e.errorAliases }
+
+// FieldErrors returns the validation messages of the response keyed by
+// field. Nested fields are joined with dots, e.g. "disks.0.size".
+func (e *ApiError) FieldErrors() map[string][]string { return e.fieldErrors }
+
+// NonFieldErrors returns the validation messages not bound to a field.
+func (e *ApiError) NonFieldErrors() []string { return e.nonFieldErrors }
+
+func (e *ApiError) HasErrorAlias(alias string) bool {
+	for _, a := range e.errorAliases {
+		if a == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// Is maps the status code and error aliases of the response to the
+// package sentinel errors.
+func (e *ApiError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.code == http.StatusNotFound || e.HasErrorAlias("not_found")
+	case ErrLocked:
+		return e.HasErrorAlias("object_locked")
+	case ErrQuotaExceeded:
+		for _, alias := range quotaAliases {
+			if e.HasErrorAlias(alias) {
+				return true
+			}
+		}
+		return false
+	case ErrValidation:
+		return e.code == http.StatusBadRequest || e.code == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.code == http.StatusUnauthorized || e.code == http.StatusForbidden
+	}
+
+	return false
+}
+
+func IsNotFound(err error) bool      { return errors.Is(err, ErrNotFound) }
+func IsLocked(err error) bool        { return errors.Is(err, ErrLocked) }
+func IsQuotaExceeded(err error) bool { return errors.Is(err, ErrQuotaExceeded) }
+func IsValidation(err error) bool    { return errors.Is(err, ErrValidation) }
+func IsUnauthorized(err error) bool  { return errors.Is(err, ErrUnauthorized) }
+
+// FieldErrors returns the field validation messages carried by err, or nil
+// when err is not an ApiError.
+func FieldErrors(err error) map[string][]string {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.FieldErrors()
+	}
+	return nil
+}
+
+func collectFieldErrors(fieldErrors map[string][]string, field string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectFieldErrors(fieldErrors, field+"."+key, v[key])
+		}
+	case []interface{}:
+		for i, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				collectFieldErrors(fieldErrors, fmt.Sprintf("%s.%d", field, i), item)
+			default:
+				fieldErrors[field] = append(fieldErrors[field], fmt.Sprint(item))
+			}
+		}
+	case nil:
+	default:
+		fieldErrors[field] = append(fieldErrors[field], fmt.Sprint(v))
+	}
+}
+
+func toStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return items
+	case string:
+		return []string{v}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package bcc_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+)
+
+var sentinels = map[string]error{
+	"not_found":    bcc.ErrNotFound,
+	"locked":       bcc.ErrLocked,
+	"quota":        bcc.ErrQuotaExceeded,
+	"validation":   bcc.ErrValidation,
+	"unauthorized": bcc.ErrUnauthorized,
+}
+
+func TestApiError(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		want         []string
+		wantAliases  []string
+		wantFields   map[string][]string
+		wantNonField []string
+	}{
+		{
+			name:        "not found",
+			status:      http.StatusNotFound,
+			body:        `{"detail": "Not found.", "error_alias": "not_found"}`,
+			want:        []string{"not_found"},
+			wantAliases: []string{"not_found"},
+		},
+		{
+			name:   "not found without a json body",
+			status: http.StatusNotFound,
+			body:   `<h1>Not Found</h1>`,
+			want:   []string{"not_found"},
+		},
+		{
+			name:        "not found alias on a bad request",
+			status:      http.StatusBadRequest,
+			body:        `{"error_alias": ["not_found"], "vdc": ["Object does not exist."]}`,
+			want:        []string{"not_found", "validation"},
+			wantAliases: []string{"not_found"},
+			wantFields:  map[string][]string{"vdc": {"Object does not exist."}},
+		},
+		{
+			name:   "nested field errors",
+			status: http.StatusBadRequest,
+			body: `{
+				"name": ["This field is required."],
+				"disks": [{}, {"size": ["Ensure this value is greater than or equal to 1."], "storage_profile": ["Object does not exist."]}],
+				"ports": [{"network": ["Object does not exist."]}],
+				"autoscaler": {"min_nodes": ["Must not exceed max_nodes."]},
+				"cpu": "A valid integer is required.",
+				"floating": null,
+				"non_field_errors": ["Either vm or disk is required."]
+			}`,
+			want: []string{"validation"},
+			wantFields: map[string][]string{
+				"name":                    {"This field is required."},
+				"disks.1.size":            {"Ensure this value is greater than or equal to 1."},
+				"disks.1.storage_profile": {"Object does not exist."},
+				"ports.0.network":         {"Object does not exist."},
+				"autoscaler.min_nodes":    {"Must not exceed max_nodes."},
+				"cpu":                     {"A valid integer is required."},
+			},
+			wantNonField: []string{"Either vm or disk is required."},
+		},
+		{
+			name:         "non field errors only",
+			status:       http.StatusBadRequest,
+			body:         `{"non_field_errors": "The fields vdc, name must make a unique set."}`,
+			want:         []string{"validation"},
+			wantNonField: []string{"The fields vdc, name must make a unique set."},
+		},
+		{
+			name:       "unprocessable",
+			status:     http.StatusUnprocessableEntity,
+			body:       `{"ram": ["Ram must be a multiple of 0.5."]}`,
+			want:       []string{"validation"},
+			wantFields: map[string][]string{"ram": {"Ram must be a multiple of 0.5."}},
+		},
+		{
+			name:   "unauthorized",
+			status: http.StatusUnauthorized,
+			body:   `{"detail": "Invalid token."}`,
+			want:   []string{"unauthorized"},
+		},
+		{
+			name:   "forbidden",
+			status: http.StatusForbidden,
+			body:   `{"detail": "You do not have permission to perform this action."}`,
+			want:   []string{"unauthorized"},
+		},
+		{
+			name:        "locked",
+			status:      http.StatusConflict,
+			body:        `{"error_alias": "object_locked", "detail": "Object is locked, try again later."}`,
+			want:        []string{"locked"},
+			wantAliases: []string{"object_locked"},
+		},
+		{
+			name:         "quota",
+			status:       http.StatusBadRequest,
+			body:         `{"error_alias": ["quota_exceeded"], "non_field_errors": ["Not enough cpu in the quota."]}`,
+			want:         []string{"quota", "validation"},
+			wantAliases:  []string{"quota_exceeded"},
+			wantNonField: []string{"Not enough cpu in the quota."},
+		},
+		{
+			name:        "project quota",
+			status:      http.StatusConflict,
+			body:        `{"error_alias": ["vm_state_conflict", "project_quota_exceeded"]}`,
+			want:        []string{"quota"},
+			wantAliases: []string{"vm_state_conflict", "project_quota_exceeded"},
+		},
+		{
+			name:        "alias mentioning quota",
+			status:      http.StatusConflict,
+			body:        `{"error_alias": "quota_change_forbidden"}`,
+			wantAliases: []string{"quota_change_forbidden"},
+		},
+		{
+			name:   "server error",
+			status: http.StatusInternalServerError,
+			body:   `<html><body>Internal Server Error</body></html>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bcc.NewApiError("https://api.example/v1/vm", &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader(tt.body))})
+
+			var apiErr *bcc.ApiError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("NewApiError returned %T, want an *bcc.ApiError", err)
+			}
+			if apiErr.Code() != tt.status || string(apiErr.Body()) != tt.body {
+				t.Errorf("code and body = %d %s, want them kept", apiErr.Code(), apiErr.Body())
+			}
+			if !strings.Contains(err.Error(), "https://api.example/v1/vm") {
+				t.Errorf("error %q does not name the url", err)
+			}
+
+			for name, sentinel := range sentinels {
+				want := false
+				for _, w := range tt.want {
+					want = want || w == name
+				}
+				if got := errors.Is(err, sentinel); got != want {
+					t.Errorf("errors.Is(err, %s) = %t, want %t", name, got, want)
+				}
+			}
+
+			if !reflect.DeepEqual(apiErr.ErrorAliases(), tt.wantAliases) {
+				t.Errorf("aliases = %q, want %q", apiErr.ErrorAliases(), tt.wantAliases)
+			}
+			wantFields := tt.wantFields
+			if wantFields == nil {
+				wantFields = map[string][]string{}
+			}
+			if got := bcc.FieldErrors(err); !reflect.DeepEqual(got, wantFields) {
+				t.Errorf("field errors = %q, want %q", got, wantFields)
+			}
+			if !reflect.DeepEqual(apiErr.NonFieldErrors(), tt.wantNonField) {
+				t.Errorf("non field errors = %q, want %q", apiErr.NonFieldErrors(), tt.wantNonField)
+			}
+		})
+	}
+}
+
+func TestFieldErrorsOfOtherErrors(t *testing.T) {
+	if got := bcc.FieldErrors(errors.New("connection refused")); got != nil {
+		t.Errorf("FieldErrors = %v, want nil", got)
+	}
+}
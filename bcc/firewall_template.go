@@ -1,8 +1,9 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"iter"
 	"net/url"
 )
 
@@ -18,10 +19,14 @@ type FirewallTemplate struct {
 }
 
 func (m *Manager) GetFirewallTemplate(id string) (firewallTemplate *FirewallTemplate, err error) {
+	return m.GetFirewallTemplateCtx(m.ctx, id)
+}
+
+func (m *Manager) GetFirewallTemplateCtx(ctx context.Context, id string) (firewallTemplate *FirewallTemplate, err error) {
 	path, _ := url.JoinPath("v1/firewall/", id)
 
-	if err = m.Get(path, Defaults(), &firewallTemplate); err != nil {
-		log.Printf("[REQUEST-ERROR] get-FirewallTemplate with id='%s' was failed: %s", id, err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &firewallTemplate); err != nil {
+		m.log("[REQUEST-ERROR] get-FirewallTemplate with id='%s' was failed: %s", id, err)
 	} else {
 		firewallTemplate.manager = m
 	}
@@ -30,31 +35,44 @@ func (m *Manager) GetFirewallTemplate(id string) (firewallTemplate *FirewallTemp
 }
 
 func (v *Vdc) GetFirewallTemplates(extraArgs ...Arguments) (firewallTemplate []*FirewallTemplate, err error) {
-	path := "v1/firewall"
-	args := Arguments{"vdc": v.ID}
-	args.merge(extraArgs)
+	return v.GetFirewallTemplatesCtx(v.manager.ctx, extraArgs...)
+}
 
-	if err = v.manager.GetItems(path, args, &firewallTemplate); err != nil {
-		log.Printf("[REQUEST-ERROR] get-FirewallTemplates failed: %s", err)
-	} else {
-		for i, _ := range firewallTemplate {
-			firewallTemplate[i].manager = v.manager
-		}
+func (v *Vdc) GetFirewallTemplatesCtx(ctx context.Context, extraArgs ...Arguments) (firewallTemplate []*FirewallTemplate, err error) {
+	if firewallTemplate, err = Collect(v.AllFirewallTemplatesCtx(ctx, arguments(extraArgs))); err != nil {
+		v.manager.log("[REQUEST-ERROR] get-FirewallTemplates failed: %s", err)
 	}
 
 	return
 }
 
+func (v *Vdc) AllFirewallTemplates(extraArgs ...ListOptions) iter.Seq2[*FirewallTemplate, error] {
+	return v.AllFirewallTemplatesCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllFirewallTemplatesCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*FirewallTemplate, error] {
+	path := "v1/firewall"
+	args := Arguments{"vdc": v.ID}
+
+	return listItems(v.manager.WithContext(ctx), path, typedQuery[FirewallTemplateListOptions]{args, listQuery(extraArgs)}, func(firewallTemplate *FirewallTemplate) {
+		firewallTemplate.manager = v.manager
+	})
+}
+
 func NewFirewallTemplate(name string) (firewallTemplate FirewallTemplate) {
 	d := FirewallTemplate{Name: name}
 	return d
 }
 
 func (f *FirewallTemplate) Update(firewallRule *FirewallRule) (err error) {
+	return f.UpdateCtx(f.manager.ctx, firewallRule)
+}
+
+func (f *FirewallTemplate) UpdateCtx(ctx context.Context, firewallRule *FirewallRule) (err error) {
 	path := fmt.Sprintf("v1/firewall/%s/rule", f.ID)
 
-	if err = f.manager.Request("POST", path, firewallRule, &firewallRule); err != nil {
-		log.Printf("[REQUEST-ERROR] update-FirewallTemplate failed: %s", err)
+	if err = f.manager.WithContext(ctx).Request("POST", path, firewallRule, &firewallRule); err != nil {
+		f.manager.log("[REQUEST-ERROR] update-FirewallTemplate failed: %s", err)
 	} else {
 		firewallRule.manager = f.manager
 	}
@@ -63,16 +81,28 @@ func (f *FirewallTemplate) Update(firewallRule *FirewallRule) (err error) {
 }
 
 func (f *FirewallTemplate) Delete() error {
+	return f.DeleteCtx(f.manager.ctx)
+}
+
+func (f *FirewallTemplate) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/firewall", f.ID)
-	return f.manager.Delete(path, Defaults(), nil)
+	return f.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (f *FirewallTemplate) Rename(name string) error {
+	return f.RenameCtx(f.manager.ctx, name)
+}
+
+func (f *FirewallTemplate) RenameCtx(ctx context.Context, name string) error {
 	f.Name = name
-	return f.UpdateFirewallTemplate()
+	return f.UpdateFirewallTemplateCtx(ctx)
 }
 
 func (f *FirewallTemplate) UpdateFirewallTemplate() (err error) {
+	return f.UpdateFirewallTemplateCtx(f.manager.ctx)
+}
+
+func (f *FirewallTemplate) UpdateFirewallTemplateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/firewall", f.ID)
 	args := &struct {
 		Name        string   `json:"name"`
@@ -84,14 +114,18 @@ func (f *FirewallTemplate) UpdateFirewallTemplate() (err error) {
 		Tags:        convertTagsToNames(f.Tags),
 	}
 
-	if err = f.manager.Request("PUT", path, args, &f); err != nil {
-		log.Printf("[REQUEST-ERROR] update-FirewallTemplate failed: %s", err)
+	if err = f.manager.WithContext(ctx).Request("PUT", path, args, &f); err != nil {
+		f.manager.log("[REQUEST-ERROR] update-FirewallTemplate failed: %s", err)
 	}
 
 	return
 }
 
 func (v *Vdc) CreateFirewallTemplate(firewallTemplate *FirewallTemplate) (err error) {
+	return v.CreateFirewallTemplateCtx(v.manager.ctx, firewallTemplate)
+}
+
+func (v *Vdc) CreateFirewallTemplateCtx(ctx context.Context, firewallTemplate *FirewallTemplate) (err error) {
 	path := "v1/firewall"
 	args := &struct {
 		Name        string   `json:"name"`
@@ -105,8 +139,8 @@ func (v *Vdc) CreateFirewallTemplate(firewallTemplate *FirewallTemplate) (err er
 		Tags:        convertTagsToNames(firewallTemplate.Tags),
 	}
 
-	if err = v.manager.Request("POST", path, args, &firewallTemplate); err != nil {
-		log.Printf("[REQUEST-ERROR] create-FirewallTemplate failed: %s", err)
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, &firewallTemplate); err != nil {
+		v.manager.log("[REQUEST-ERROR] create-FirewallTemplate failed: %s", err)
 	} else {
 		firewallTemplate.manager = v.manager
 	}
@@ -115,6 +149,10 @@ func (v *Vdc) CreateFirewallTemplate(firewallTemplate *FirewallTemplate) (err er
 }
 
 func (f FirewallTemplate) WaitLock() error {
+	return f.WaitLockCtx(f.manager.ctx)
+}
+
+func (f FirewallTemplate) WaitLockCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/firewall", f.ID)
-	return loopWaitLock(f.manager, path)
+	return loopWaitLock(f.manager.WithContext(ctx), path)
 }
@@ -1,8 +1,8 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
 )
 
 type FirewallRule struct {
@@ -31,6 +31,10 @@ func NewFirewallRule(name string, destinationIp string, direction string, protoc
 }
 
 func (f *FirewallTemplate) CreateFirewallRule(firewallRule *FirewallRule) (err error) {
+	return f.CreateFirewallRuleCtx(f.manager.ctx, firewallRule)
+}
+
+func (f *FirewallTemplate) CreateFirewallRuleCtx(ctx context.Context, firewallRule *FirewallRule) (err error) {
 	path := fmt.Sprintf("v1/firewall/%s/rule", f.ID)
 	args := &struct {
 		manager         *Manager
@@ -56,8 +60,8 @@ func (f *FirewallTemplate) CreateFirewallRule(firewallRule *FirewallRule) (err e
 		args.DstPortRangeMin = firewallRule.DstPortRangeMin
 	}
 
-	if err = f.manager.Request("POST", path, args, &firewallRule); err != nil {
-		log.Printf("[REQUEST-ERROR] create-FirewallRule was failed: %s", err)
+	if err = f.manager.WithContext(ctx).Request("POST", path, args, &firewallRule); err != nil {
+		f.manager.log("[REQUEST-ERROR] create-FirewallRule was failed: %s", err)
 	} else {
 		firewallRule.manager = f.manager
 		firewallRule.TemplateId = f.ID
@@ -67,10 +71,14 @@ func (f *FirewallTemplate) CreateFirewallRule(firewallRule *FirewallRule) (err e
 }
 
 func (f *FirewallTemplate) GetRuleById(firewallRuleId string) (firewallRule *FirewallRule, err error) {
+	return f.GetRuleByIdCtx(f.manager.ctx, firewallRuleId)
+}
+
+func (f *FirewallTemplate) GetRuleByIdCtx(ctx context.Context, firewallRuleId string) (firewallRule *FirewallRule, err error) {
 	path := fmt.Sprintf("v1/firewall/%s/rule/%s", f.ID, firewallRuleId)
 
-	if err = f.manager.Get(path, Defaults(), &firewallRule); err != nil {
-		log.Printf("[REQUEST-ERROR] get-Firewall rule was failed: %s", err)
+	if err = f.manager.WithContext(ctx).Get(path, Defaults(), &firewallRule); err != nil {
+		f.manager.log("[REQUEST-ERROR] get-Firewall rule was failed: %s", err)
 	} else {
 		firewallRule.manager = f.manager
 		firewallRule.TemplateId = f.ID
@@ -80,38 +88,54 @@ func (f *FirewallTemplate) GetRuleById(firewallRuleId string) (firewallRule *Fir
 }
 
 func (m *Manager) GetFirewallRules(id string, extraArgs ...Arguments) (firewallRules []*FirewallRule, err error) {
+	return m.GetFirewallRulesCtx(m.ctx, id, extraArgs...)
+}
+
+func (m *Manager) GetFirewallRulesCtx(ctx context.Context, id string, extraArgs ...Arguments) (firewallRules []*FirewallRule, err error) {
 	path := fmt.Sprintf("v1/firewall/%s/rule", id)
 	args := Defaults()
 	args.merge(extraArgs)
 
-	if err = m.Get(path, args, &firewallRules); err != nil {
-		log.Printf("[REQUEST-ERROR] get-Firewall rules was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, args, &firewallRules); err != nil {
+		m.log("[REQUEST-ERROR] get-Firewall rules was failed: %s", err)
 	}
 
 	return
 }
 
 func (f *FirewallRule) Update() (err error) {
+	return f.UpdateCtx(f.manager.ctx)
+}
+
+func (f *FirewallRule) UpdateCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/firewall/%s/rule/%s", f.TemplateId, f.ID)
 
-	if err = f.manager.Request("PUT", path, f, &f); err != nil {
-		log.Printf("[REQUEST-ERROR] update-FirewallRule was failed: %s", err)
+	if err = f.manager.WithContext(ctx).Request("PUT", path, f, &f); err != nil {
+		f.manager.log("[REQUEST-ERROR] update-FirewallRule was failed: %s", err)
 	}
 
 	return
 }
 
 func (f *FirewallRule) Delete() (err error) {
+	return f.DeleteCtx(f.manager.ctx)
+}
+
+func (f *FirewallRule) DeleteCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/firewall/%s/rule/%s", f.TemplateId, f.ID)
 
-	if err = f.manager.Delete(path, Defaults(), nil); err != nil {
-		log.Printf("[REQUEST-ERROR] delete-FirewallRule was failed: %s", err)
+	if err = f.manager.WithContext(ctx).Delete(path, Defaults(), nil); err != nil {
+		f.manager.log("[REQUEST-ERROR] delete-FirewallRule was failed: %s", err)
 	}
 
 	return
 }
 
 func (f FirewallRule) WaitLock() (err error) {
+	return f.WaitLockCtx(f.manager.ctx)
+}
+
+func (f FirewallRule) WaitLockCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/firewall/%s/rule/%s", f.TemplateId, f.ID)
-	return loopWaitLock(f.manager, path)
+	return loopWaitLock(f.manager.WithContext(ctx), path)
 }
@@ -1,8 +1,8 @@
 package bcc
 
 import (
+	"context"
 	"errors"
-	"log"
 	"net/url"
 )
 
@@ -12,31 +12,37 @@ type Floating struct {
 }
 
 func (m *Manager) GetFloating(id string) (fip *Floating, err error) {
+	return m.GetFloatingCtx(m.ctx, id)
+}
+
+func (m *Manager) GetFloatingCtx(ctx context.Context, id string) (fip *Floating, err error) {
 	path, _ := url.JoinPath("v1/floating", id)
 
-	if err = m.Get(path, Defaults(), &fip); err != nil {
-		log.Printf("[REQUEST-ERROR] get-floating with id='%s' was failed: %s", id, err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &fip); err != nil {
+		m.log("[REQUEST-ERROR] get-floating with id='%s' was failed: %s", id, err)
 	}
 
 	return
 }
 
 func (v *Vdc) GetFloatingByAddress(address string) (fip *Floating, err error) {
+	return v.GetFloatingByAddressCtx(v.manager.ctx, address)
+}
+
+func (v *Vdc) GetFloatingByAddressCtx(ctx context.Context, address string) (fip *Floating, err error) {
 	path := "v1/port"
 	args := Arguments{
 		"vdc":         v.ID,
 		"filter_type": "external",
 	}
-	var items []*Floating
-
-	if err = v.manager.GetItems(path, args, &items); err != nil {
-		log.Printf("[REQUEST-ERROR] get-floating by address '%s' was failed: %s", address, err)
-	} else {
-		for i := 0; i < len(items); i++ {
-			if items[i].IpAddress == address {
-				fip = items[i]
-				return
-			}
+
+	for item, err := range listItems(v.manager.WithContext(ctx), path, args, func(*Floating) {}) {
+		if err != nil {
+			v.manager.log("[REQUEST-ERROR] get-floating by address '%s' was failed: %s", address, err)
+			return nil, err
+		}
+		if item.IpAddress == address {
+			return item, nil
 		}
 	}
 
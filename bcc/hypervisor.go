@@ -1,7 +1,7 @@
 package bcc
 
 import (
-	"log"
+	"context"
 	"net/url"
 )
 
@@ -17,6 +17,10 @@ type Hypervisor struct {
 }
 
 func (p *Project) GetAvailableHypervisors(extraArgs ...Arguments) (hypervisors []*Hypervisor, err error) {
+	return p.GetAvailableHypervisorsCtx(p.manager.ctx, extraArgs...)
+}
+
+func (p *Project) GetAvailableHypervisorsCtx(ctx context.Context, extraArgs ...Arguments) (hypervisors []*Hypervisor, err error) {
 	path, _ := url.JoinPath("v1/project", p.ID)
 	type tempType struct {
 		Client struct {
@@ -28,8 +32,8 @@ func (p *Project) GetAvailableHypervisors(extraArgs ...Arguments) (hypervisors [
 	args := Defaults()
 	args.merge(extraArgs)
 
-	if err = p.manager.Get(path, args, &target); err != nil {
-		log.Printf("[REQUEST-ERROR] get-projects for hypervisor was failed: %s", err)
+	if err = p.manager.WithContext(ctx).cached(CacheHypervisors).Get(path, args, &target); err != nil {
+		p.manager.log("[REQUEST-ERROR] get-projects for hypervisor was failed: %s", err)
 	} else {
 		hypervisors = target.Client.AllowedHypervisors
 
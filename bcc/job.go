@@ -0,0 +1,380 @@
+package bcc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Statuses reported by v1/job. The API does not publish the full set: the
+// client has always treated "error" as a failure, "processing" and "done"
+// are the running and finished values the control panel returns (and
+// bcctest mimics), and "cancelled" follows Job.Cancel. "new", "pending" and
+// "in_progress" are accepted as running as well.
+//
+// Job.Wait stops with ErrUnknownJobStatus on any other status instead of
+// polling until Manager.JobTimeout, as it cannot tell whether such a job is
+// still running.
+const (
+	JobStatusProcessing = "processing"
+	JobStatusDone       = "done"
+	JobStatusError      = "error"
+	JobStatusCancelled  = "cancelled"
+)
+
+var jobPendingStatuses = map[string]bool{
+	"":                  true,
+	"new":               true,
+	"pending":           true,
+	"in_progress":       true,
+	JobStatusProcessing: true,
+}
+
+// Job is an asynchronous task started by a mutating API call. Its ID is
+// reported by the API in the X-Esu-Tasks response header and its state is
+// served by v1/job/{id}.
+type Job struct {
+	manager   *Manager
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JobError is returned when a job ends in the error status. Step is the
+// name of the step the job failed on.
+type JobError struct {
+	Job  *Job
+	Step string
+}
+
+func (e *JobError) Error() string {
+	return fmt.Sprintf("Task %s in error status, step: %s", e.Job.ID, e.Step)
+}
+
+func (m *Manager) newJob(id string) *Job {
+	return &Job{manager: m, ID: id}
+}
+
+// GetJob fetches the current state of the job with the given id.
+func (m *Manager) GetJob(id string) (job *Job, err error) {
+	job = m.newJob(id)
+	if err = job.Poll(); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Poll refreshes the job state once.
+func (j *Job) Poll() error {
+	return j.poll(j.manager)
+}
+
+func (j *Job) poll(m *Manager) error {
+	path, _ := url.JoinPath("v1/job", j.ID)
+	return m.Get(path, Defaults(), j)
+}
+
+// Done reports whether the job has finished, successfully or not.
+func (j *Job) Done() bool {
+	return j.Status == JobStatusDone || j.Failed()
+}
+
+func (j *Job) Failed() bool {
+	return j.Status == JobStatusError || j.Status == JobStatusCancelled
+}
+
+// Pending reports whether the job is still running.
+func (j *Job) Pending() bool {
+	return jobPendingStatuses[j.Status]
+}
+
+// Cancel asks the API to stop the job and refreshes its state. Jobs that can
+// no longer be stopped are refused with an *ApiError. A cancelled job ends in
+// JobStatusCancelled, which Wait reports as a *JobError.
+func (j *Job) Cancel(ctx context.Context) error {
+	m := j.manager.WithContext(ctx)
+	path := fmt.Sprintf("v1/job/%s/cancel", j.ID)
+
+	if _, err := m.RequestJobs("POST", path, nil, nil); err != nil {
+		m.log("[REQUEST-ERROR] cancel-job was failed: %s", err)
+		return err
+	}
+
+	return j.poll(m)
+}
+
+// Wait polls the job every Manager.JobPollInterval until it is done, ctx is
+// cancelled or Manager.JobTimeout elapses. A failed or cancelled job is
+// reported as a *JobError, a status that is neither running nor final as
+// ErrUnknownJobStatus.
+func (j *Job) Wait(ctx context.Context) (err error) {
+	m, span := j.manager.WithContext(ctx).startSpan("bcc.job.wait", Attr("bcc.job.id", j.ID))
+	ctx = m.ctx
+	start := time.Now()
+	defer func() {
+		span.SetAttributes(Attr("bcc.job.name", j.Name), Attr("bcc.job.status", j.Status))
+		endSpan(span, err)
+		if m.Metrics != nil {
+			m.Metrics.ObserveJobWait(j, time.Since(start), err)
+		}
+	}()
+
+	m.log("[bcc] Start waiting task %s...", j.ID)
+
+	if m.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.JobTimeout)
+		defer cancel()
+		m = m.WithContext(ctx)
+	}
+
+	interval := m.JobPollInterval
+	if interval <= 0 {
+		interval = RetryTime * time.Millisecond
+	}
+
+	for {
+		if err := j.poll(m); err != nil {
+			if ctx.Err() != nil {
+				m.log("[bcc] Waiting task %s took more than %s", j.ID, m.JobTimeout)
+			}
+			return err
+		}
+
+		if j.Failed() {
+			return &JobError{Job: j, Step: j.Name}
+		}
+		if j.Done() {
+			m.log("[bcc] End waiting task %s", j.ID)
+			return nil
+		}
+		if !j.Pending() {
+			return fmt.Errorf("%w %q of task %s", ErrUnknownJobStatus, j.Status, j.ID)
+		}
+
+		if err := SleepWithContext(ctx, interval); err != nil {
+			m.log("[bcc] Waiting task %s took more than %s", j.ID, m.JobTimeout)
+			return err
+		}
+	}
+}
+
+func (j *Job) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID        string `json:"id"`
+		Status    string `json:"status"`
+		Name      string `json:"name"`
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	if raw.ID != "" {
+		j.ID = raw.ID
+	}
+	j.Status = raw.Status
+	j.Name = raw.Name
+	j.CreatedAt = parseJobTime(raw.CreatedAt)
+	j.UpdatedAt = parseJobTime(raw.UpdatedAt)
+
+	return nil
+}
+
+// parseJobTime accepts the timestamp formats the API is known to use and
+// yields the zero time for anything else.
+func parseJobTime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// jobs turns the X-Esu-Tasks header into jobs.
+func (m *Manager) jobs(taskIds string) []*Job {
+	var jobs []*Job
+	for _, taskId := range strings.Split(taskIds, ",") {
+		taskId := strings.TrimSpace(taskId)
+		if taskId == "" {
+			continue
+		}
+
+		jobs = append(jobs, m.newJob(taskId))
+	}
+
+	return jobs
+}
+
+// RequestJobs performs a mutating request like Request, but returns the
+// jobs started by it instead of waiting for them.
+func (m *Manager) RequestJobs(method string, path string, args interface{}, target interface{}) (jobs []*Job, err error) {
+	traced, span := m.traceOperation(method, path)
+	defer func() { endSpan(span, err) }()
+
+	taskIds, err := traced.request(method, path, args, target)
+	return m.jobs(taskIds), err
+}
+
+// DeleteJobs performs a delete like Delete, but returns the jobs started by
+// it instead of waiting for them.
+func (m *Manager) DeleteJobs(path string, args Arguments, target interface{}) (jobs []*Job, err error) {
+	traced, span := m.traceOperation("DELETE", path)
+	defer func() { endSpan(span, err) }()
+
+	taskIds, err := traced.delete(path, args, target)
+	return m.jobs(taskIds), err
+}
+
+func (m *Manager) waitJobs(jobs []*Job) error {
+	if set := jobSetFrom(m.ctx); set != nil {
+		set.add(jobs...)
+		return nil
+	}
+
+	for _, job := range jobs {
+		if err := job.Wait(m.ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type jobSetKey struct{}
+
+// JobSet collects the jobs started under a context returned by NoWait. It is
+// safe for concurrent use.
+type JobSet struct {
+	mu   sync.Mutex
+	jobs []*Job
+}
+
+// NoWait returns a context under which mutating calls, such as
+// Vm.PowerOffCtx or Disk.ResizeCtx, return as soon as the API accepted them
+// instead of waiting for the jobs they start. Those jobs are collected in the
+// returned set:
+//
+//	ctx, jobs := bcc.NoWait(ctx)
+//	if err := vm.PowerOffCtx(ctx); err != nil {
+//		return err
+//	}
+//	...
+//	if err := jobs.Wait(ctx); err != nil {
+//		return err
+//	}
+//
+// Objects refreshed by such a call may not reflect the finished jobs yet,
+// reload them once the jobs are done.
+func NoWait(ctx context.Context) (context.Context, *JobSet) {
+	set := new(JobSet)
+	return context.WithValue(ctx, jobSetKey{}, set), set
+}
+
+func jobSetFrom(ctx context.Context) *JobSet {
+	if ctx == nil {
+		return nil
+	}
+
+	set, _ := ctx.Value(jobSetKey{}).(*JobSet)
+	return set
+}
+
+func (s *JobSet) add(jobs ...*Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, jobs...)
+}
+
+// Jobs returns the jobs started so far, in order.
+func (s *JobSet) Jobs() []*Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Job(nil), s.jobs...)
+}
+
+// Wait waits for every job in turn and stops at the first failure.
+func (s *JobSet) Wait(ctx context.Context) error {
+	for _, job := range s.Jobs() {
+		if err := job.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Cancel cancels every job that is not done yet and returns the first error.
+func (s *JobSet) Cancel(ctx context.Context) error {
+	var first error
+	for _, job := range s.Jobs() {
+		if job.Done() {
+			continue
+		}
+		if err := job.Cancel(ctx); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// Job returns the job the kubernetes cluster is currently busy with, or nil.
+func (k *Kubernetes) Job() *Job {
+	if k.JobId == "" {
+		return nil
+	}
+	return k.manager.newJob(k.JobId)
+}
+
+// Job returns the job the affinity group is currently busy with, or nil.
+func (a *AffinityGroup) Job() *Job {
+	if a.JobId == "" {
+		return nil
+	}
+	return a.manager.newJob(a.JobId)
+}
+
+// Job returns the job the node group is currently busy with, or nil.
+func (g *KubernetesNodeGroup) Job() *Job {
+	if g.JobId == "" {
+		return nil
+	}
+	return g.manager.newJob(g.JobId)
+}
+
+// Job returns the job the S3 storage is currently busy with, or nil.
+func (s3 *S3Storage) Job() *Job {
+	if s3.JobId == "" {
+		return nil
+	}
+	return s3.manager.newJob(s3.JobId)
+}
+
+// Job returns the job the snapshot is currently busy with, or nil.
+func (s *Snapshot) Job() *Job {
+	if s.JobId == "" {
+		return nil
+	}
+	return s.manager.newJob(s.JobId)
+}
+
+// Job returns the job the backup policy is currently busy with, or nil.
+func (p *BackupPolicy) Job() *Job {
+	if p.JobId == "" {
+		return nil
+	}
+	return p.manager.newJob(p.JobId)
+}
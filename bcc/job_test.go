@@ -0,0 +1,140 @@
+package bcc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+func newVmServer(t *testing.T) (*bcctest.Server, *bcc.Manager, *bcc.Vm) {
+	t.Helper()
+
+	s, m := newServer(t, mainVdc, object("vm", bcctest.Object{"id": "vm1", "name": "web", "vdc": "vdc1"}))
+	m.JobTimeout = 5 * time.Second
+	vm, err := m.GetVm("vm1")
+	if err != nil {
+		t.Fatalf("GetVm: %s", err)
+	}
+
+	return s, m, vm
+}
+
+func TestJobWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(s *bcctest.Server, job *bcc.Job)
+		wantErr error
+		wantJob bool
+	}{
+		{name: "done"},
+		{
+			name:    "unknown status",
+			setup:   func(s *bcctest.Server, job *bcc.Job) { s.SetJobStatus(job.ID, "paused") },
+			wantErr: bcc.ErrUnknownJobStatus,
+		},
+		{
+			name:    "error status",
+			setup:   func(s *bcctest.Server, job *bcc.Job) { s.SetJobStatus(job.ID, bcc.JobStatusError) },
+			wantJob: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, vm := newVmServer(t)
+			s.JobPolls = 2
+
+			ctx, jobs := bcc.NoWait(context.Background())
+			if err := vm.PowerOffCtx(ctx); err != nil {
+				t.Fatalf("PowerOff: %s", err)
+			}
+			if len(jobs.Jobs()) != 1 {
+				t.Fatalf("collected %d jobs, want 1", len(jobs.Jobs()))
+			}
+			job := jobs.Jobs()[0]
+			if tt.setup != nil {
+				tt.setup(s, job)
+			}
+
+			start := time.Now()
+			err := job.Wait(context.Background())
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Wait took %s", elapsed)
+			}
+
+			var jobErr *bcc.JobError
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Wait error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantJob:
+				if !errors.As(err, &jobErr) {
+					t.Errorf("Wait error = %v, want a *bcc.JobError", err)
+				}
+			case err != nil:
+				t.Errorf("Wait: %s", err)
+			}
+		})
+	}
+}
+
+func TestNoWait(t *testing.T) {
+	s, _, vm := newVmServer(t)
+	s.JobPolls = 3
+
+	ctx, jobs := bcc.NoWait(context.Background())
+	if err := vm.PowerOffCtx(ctx); err != nil {
+		t.Fatalf("PowerOff: %s", err)
+	}
+
+	started := jobs.Jobs()
+	if len(started) != 1 {
+		t.Fatalf("collected %d jobs, want 1", len(started))
+	}
+	for _, r := range s.Requests() {
+		if r.Path == "/v1/job/"+started[0].ID {
+			t.Fatal("PowerOff polled its job under NoWait")
+		}
+	}
+
+	if err := jobs.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait: %s", err)
+	}
+	if started[0].Status != bcc.JobStatusDone {
+		t.Errorf("job status = %q, want done", started[0].Status)
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	s, _, vm := newVmServer(t)
+	s.JobPolls = 1000
+
+	ctx, jobs := bcc.NoWait(context.Background())
+	if err := vm.PowerOffCtx(ctx); err != nil {
+		t.Fatalf("PowerOff: %s", err)
+	}
+	if err := jobs.Cancel(context.Background()); err != nil {
+		t.Fatalf("Cancel: %s", err)
+	}
+
+	job := jobs.Jobs()[0]
+	if job.Status != bcc.JobStatusCancelled {
+		t.Errorf("job status = %q, want cancelled", job.Status)
+	}
+
+	var jobErr *bcc.JobError
+	if err := job.Wait(context.Background()); !errors.As(err, &jobErr) {
+		t.Errorf("Wait error = %v, want a *bcc.JobError", err)
+	}
+
+	// a finished job can no longer be cancelled
+	var apiErr *bcc.ApiError
+	if err := job.Cancel(context.Background()); !errors.As(err, &apiErr) {
+		t.Errorf("second Cancel error = %v, want an *bcc.ApiError", err)
+	}
+}
@@ -0,0 +1,294 @@
+package bcc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/pkg/errors"
+)
+
+// Kubeconfig is the parsed form of a kubeconfig file. Keys the client does
+// not model are kept in the Extra maps, so a file survives a round trip
+// through ParseKubeconfig and Marshal.
+type Kubeconfig struct {
+	APIVersion     string                 `yaml:"apiVersion"`
+	Kind           string                 `yaml:"kind"`
+	Clusters       []*KubeconfigCluster   `yaml:"clusters"`
+	Contexts       []*KubeconfigContext   `yaml:"contexts"`
+	Users          []*KubeconfigUser      `yaml:"users"`
+	CurrentContext string                 `yaml:"current-context"`
+	Extra          map[string]interface{} `yaml:",inline"`
+}
+
+type KubeconfigCluster struct {
+	Name    string                `yaml:"name"`
+	Cluster KubeconfigClusterInfo `yaml:"cluster"`
+}
+
+type KubeconfigClusterInfo struct {
+	Server                   string                 `yaml:"server"`
+	CertificateAuthorityData string                 `yaml:"certificate-authority-data,omitempty"`
+	InsecureSkipTLSVerify    bool                   `yaml:"insecure-skip-tls-verify,omitempty"`
+	Extra                    map[string]interface{} `yaml:",inline"`
+}
+
+type KubeconfigContext struct {
+	Name    string                `yaml:"name"`
+	Context KubeconfigContextInfo `yaml:"context"`
+}
+
+type KubeconfigContextInfo struct {
+	Cluster   string                 `yaml:"cluster"`
+	User      string                 `yaml:"user"`
+	Namespace string                 `yaml:"namespace,omitempty"`
+	Extra     map[string]interface{} `yaml:",inline"`
+}
+
+type KubeconfigUser struct {
+	Name string             `yaml:"name"`
+	User KubeconfigUserInfo `yaml:"user"`
+}
+
+type KubeconfigUserInfo struct {
+	ClientCertificateData string                 `yaml:"client-certificate-data,omitempty"`
+	ClientKeyData         string                 `yaml:"client-key-data,omitempty"`
+	Token                 string                 `yaml:"token,omitempty"`
+	Extra                 map[string]interface{} `yaml:",inline"`
+}
+
+func ParseKubeconfig(b []byte) (*Kubeconfig, error) {
+	config := new(Kubeconfig)
+	if err := yaml.Unmarshal(b, config); err != nil {
+		return nil, errors.Wrapf(err, "Yaml decode of kubeconfig failed")
+	}
+
+	return config, nil
+}
+
+func (c *Kubeconfig) Marshal() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// Merge adds the clusters, contexts and users of other to c, replacing the
+// entries that have the same name. The current context of c is only taken
+// from other when c has none.
+func (c *Kubeconfig) Merge(other *Kubeconfig) {
+	if c.APIVersion == "" {
+		c.APIVersion = other.APIVersion
+	}
+	if c.Kind == "" {
+		c.Kind = other.Kind
+	}
+	if c.CurrentContext == "" {
+		c.CurrentContext = other.CurrentContext
+	}
+
+	c.Clusters = mergeNamed(c.Clusters, other.Clusters, func(e *KubeconfigCluster) string { return e.Name })
+	c.Contexts = mergeNamed(c.Contexts, other.Contexts, func(e *KubeconfigContext) string { return e.Name })
+	c.Users = mergeNamed(c.Users, other.Users, func(e *KubeconfigUser) string { return e.Name })
+}
+
+func mergeNamed[T any](entries []T, others []T, name func(T) string) []T {
+	for _, other := range others {
+		replaced := false
+		for i, entry := range entries {
+			if name(entry) == name(other) {
+				entries[i] = other
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			entries = append(entries, other)
+		}
+	}
+
+	return entries
+}
+
+// DefaultKubeconfigPath returns the first file listed in KUBECONFIG, or
+// ~/.kube/config.
+func DefaultKubeconfigPath() (string, error) {
+	if paths := filepath.SplitList(os.Getenv("KUBECONFIG")); len(paths) > 0 && paths[0] != "" {
+		return paths[0], nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrapf(err, "Cannot find home directory")
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// GetKubeconfig downloads the kubeconfig of the cluster as it is served by
+// the API.
+func (k *Kubernetes) GetKubeconfig(ctx context.Context) ([]byte, error) {
+	path := fmt.Sprintf("/v1/kubernetes/%s/config", k.ID)
+
+	var config []byte
+	if err := k.manager.WithContext(ctx).Get(path, Defaults(), &config); err != nil {
+		k.manager.log("[REQUEST-ERROR] get-kubernetes-config was failed: %s", err)
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// GetParsedKubeconfig downloads the kubeconfig of the cluster and parses it.
+func (k *Kubernetes) GetParsedKubeconfig(ctx context.Context) (*Kubeconfig, error) {
+	b, err := k.GetKubeconfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKubeconfig(b)
+}
+
+// WriteKubeconfig saves the kubeconfig of the cluster to path. A zero mode
+// stands for 0600, as the file holds the cluster credentials.
+func (k *Kubernetes) WriteKubeconfig(path string, mode os.FileMode) error {
+	return k.WriteKubeconfigCtx(k.manager.ctx, path, mode)
+}
+
+func (k *Kubernetes) WriteKubeconfigCtx(ctx context.Context, path string, mode os.FileMode) error {
+	b, err := k.GetKubeconfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ParseKubeconfig(b); err != nil {
+		return err
+	}
+
+	if mode == 0 {
+		mode = 0600
+	}
+	if path, err = resolveSymlinks(path); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b, mode)
+}
+
+// KubeconfigOption adjusts how MergeKubeconfig updates a kubeconfig.
+type KubeconfigOption func(*kubeconfigMerge)
+
+type kubeconfigMerge struct {
+	useContext bool
+}
+
+// WithCurrentContext makes the context of the merged cluster the current
+// context of the file.
+func WithCurrentContext() KubeconfigOption {
+	return func(merge *kubeconfigMerge) {
+		merge.useContext = true
+	}
+}
+
+// MergeKubeconfig adds the cluster to the kubeconfig at path, or to
+// DefaultKubeconfigPath when path is empty, creating the file with mode 0600
+// if needed. Entries with the same names are replaced and the current
+// context is left alone unless the file has none or WithCurrentContext is
+// given. An existing file keeps its mode, and a symlink is followed rather
+// than replaced.
+func (k *Kubernetes) MergeKubeconfig(path string, opts ...KubeconfigOption) error {
+	return k.MergeKubeconfigCtx(k.manager.ctx, path, opts...)
+}
+
+func (k *Kubernetes) MergeKubeconfigCtx(ctx context.Context, path string, opts ...KubeconfigOption) (err error) {
+	var merge kubeconfigMerge
+	for _, opt := range opts {
+		opt(&merge)
+	}
+
+	if path == "" {
+		if path, err = DefaultKubeconfigPath(); err != nil {
+			return err
+		}
+	}
+	if path, err = resolveSymlinks(path); err != nil {
+		return err
+	}
+
+	config, err := k.GetParsedKubeconfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing := &Kubeconfig{APIVersion: "v1", Kind: "Config"}
+	mode := os.FileMode(0600)
+	if b, err := os.ReadFile(path); err == nil {
+		if existing, err = ParseKubeconfig(b); err != nil {
+			return errors.Wrapf(err, "Cannot merge into %s", path)
+		}
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrapf(err, "Cannot read %s", path)
+	}
+
+	existing.Merge(config)
+	if merge.useContext && config.CurrentContext != "" {
+		existing.CurrentContext = config.CurrentContext
+	}
+
+	b, err := existing.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrapf(err, "Cannot create directory for %s", path)
+	}
+	return writeFileAtomic(path, b, mode)
+}
+
+// resolveSymlinks follows path to the file it links to, which need not exist
+// yet, so that writing it keeps the links in place.
+func resolveSymlinks(path string) (string, error) {
+	for i := 0; i < 255; i++ {
+		info, err := os.Lstat(path)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", errors.Wrapf(err, "Cannot follow %s", path)
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+
+	return "", errors.Errorf("Cannot follow %s: too many levels of symbolic links", path)
+}
+
+// writeFileAtomic replaces path with data, so readers never see a partially
+// written file. path must not be a symlink, see resolveSymlinks.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.Wrapf(err, "Cannot write %s", path)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "Cannot write %s", path)
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "Cannot write %s", path)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrapf(err, "Cannot write %s", path)
+	}
+
+	return errors.Wrapf(os.Rename(f.Name(), path), "Cannot write %s", path)
+}
@@ -0,0 +1,298 @@
+package bcc_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+)
+
+// prodKubeconfig holds a cluster other than the one served for k8s1, plus
+// a stale entry for k8s1 itself when stale is set.
+func prodKubeconfig(stale bool) string {
+	config := `apiVersion: v1
+kind: Config
+preferences:
+  colors: true
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example:6443
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: prod-admin
+    namespace: web
+users:
+- name: prod-admin
+  user:
+    token: prod-token
+current-context: prod
+`
+	if stale {
+		config = strings.Replace(config, "clusters:\n", "clusters:\n- name: apps\n  cluster:\n    server: https://old.example:6443\n", 1)
+		config = strings.Replace(config, "users:\n", "users:\n- name: apps-admin\n  user:\n    token: old-token\n", 1)
+	}
+
+	return config
+}
+
+func names[T any](entries []T, name func(T) string) string {
+	var all []string
+	for _, entry := range entries {
+		all = append(all, name(entry))
+	}
+
+	return strings.Join(all, ",")
+}
+
+func TestMergeKubeconfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     string
+		mode         os.FileMode
+		opts         []bcc.KubeconfigOption
+		wantClusters string
+		wantUsers    string
+		wantContexts string
+		wantCurrent  string
+		wantMode     os.FileMode
+	}{
+		{
+			name:         "new file",
+			wantClusters: "apps",
+			wantUsers:    "apps-admin",
+			wantContexts: "apps",
+			wantCurrent:  "apps",
+			wantMode:     0600,
+		},
+		{
+			name:         "appended",
+			existing:     prodKubeconfig(false),
+			mode:         0600,
+			wantClusters: "prod,apps",
+			wantUsers:    "prod-admin,apps-admin",
+			wantContexts: "prod,apps",
+			wantCurrent:  "prod",
+			wantMode:     0600,
+		},
+		{
+			name:         "replaced",
+			existing:     prodKubeconfig(true),
+			mode:         0600,
+			wantClusters: "apps,prod",
+			wantUsers:    "apps-admin,prod-admin",
+			wantContexts: "prod,apps",
+			wantCurrent:  "prod",
+			wantMode:     0600,
+		},
+		{
+			name:         "current context requested",
+			existing:     prodKubeconfig(false),
+			mode:         0600,
+			opts:         []bcc.KubeconfigOption{bcc.WithCurrentContext()},
+			wantClusters: "prod,apps",
+			wantUsers:    "prod-admin,apps-admin",
+			wantContexts: "prod,apps",
+			wantCurrent:  "apps",
+			wantMode:     0600,
+		},
+		{
+			name:         "existing mode kept",
+			existing:     prodKubeconfig(false),
+			mode:         0640,
+			wantClusters: "prod,apps",
+			wantUsers:    "prod-admin,apps-admin",
+			wantContexts: "prod,apps",
+			wantCurrent:  "prod",
+			wantMode:     0640,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, k := newKubernetesServer(t, 2, nil)
+			path := filepath.Join(t.TempDir(), ".kube", "config")
+			if tt.existing != "" {
+				writeFile(t, path, tt.existing, tt.mode)
+			}
+
+			if err := k.MergeKubeconfig(path, tt.opts...); err != nil {
+				t.Fatalf("MergeKubeconfig: %s", err)
+			}
+
+			config := readKubeconfig(t, path)
+			if got := names(config.Clusters, func(e *bcc.KubeconfigCluster) string { return e.Name }); got != tt.wantClusters {
+				t.Errorf("clusters = %s, want %s", got, tt.wantClusters)
+			}
+			if got := names(config.Users, func(e *bcc.KubeconfigUser) string { return e.Name }); got != tt.wantUsers {
+				t.Errorf("users = %s, want %s", got, tt.wantUsers)
+			}
+			if got := names(config.Contexts, func(e *bcc.KubeconfigContext) string { return e.Name }); got != tt.wantContexts {
+				t.Errorf("contexts = %s, want %s", got, tt.wantContexts)
+			}
+			if config.CurrentContext != tt.wantCurrent {
+				t.Errorf("current-context = %s, want %s", config.CurrentContext, tt.wantCurrent)
+			}
+
+			for _, cluster := range config.Clusters {
+				if cluster.Name == "apps" && cluster.Cluster.Server != "https://k8s1.kubernetes.test:6443" {
+					t.Errorf("apps server = %s, want the served one", cluster.Cluster.Server)
+				}
+			}
+			for _, user := range config.Users {
+				if user.Name == "apps-admin" && user.User.Token != "token-k8s1" {
+					t.Errorf("apps-admin token = %s, want the served one", user.User.Token)
+				}
+			}
+			if tt.existing != "" {
+				if config.Extra["preferences"] == nil {
+					t.Error("preferences of the existing file were dropped")
+				}
+				if config.Contexts[0].Context.Namespace != "web" {
+					t.Error("namespace of the existing context was dropped")
+				}
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != tt.wantMode {
+				t.Errorf("mode = %s, want %s", info.Mode().Perm(), tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestMergeKubeconfigSymlink(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing bool
+		relative bool
+	}{
+		{name: "absolute link", existing: true},
+		{name: "relative link", existing: true, relative: true},
+		{name: "dangling link"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, k := newKubernetesServer(t, 2, nil)
+			dir := t.TempDir()
+			target := filepath.Join(dir, "dotfiles", "kubeconfig")
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				t.Fatal(err)
+			}
+			if tt.existing {
+				writeFile(t, target, prodKubeconfig(false), 0640)
+			}
+
+			link := filepath.Join(dir, "config")
+			linkTarget := target
+			if tt.relative {
+				linkTarget = filepath.Join("dotfiles", "kubeconfig")
+			}
+			if err := os.Symlink(linkTarget, link); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := k.MergeKubeconfig(link); err != nil {
+				t.Fatalf("MergeKubeconfig: %s", err)
+			}
+
+			info, err := os.Lstat(link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode()&os.ModeSymlink == 0 {
+				t.Fatalf("%s was replaced by a regular file", link)
+			}
+			config := readKubeconfig(t, target)
+			if !strings.HasSuffix(names(config.Clusters, func(e *bcc.KubeconfigCluster) string { return e.Name }), "apps") {
+				t.Errorf("the link target was not merged into")
+			}
+			if info, err := os.Stat(target); err != nil || (tt.existing && info.Mode().Perm() != 0640) {
+				t.Errorf("target mode = %v, %v, want it kept", info.Mode().Perm(), err)
+			}
+		})
+	}
+}
+
+func TestMergeKubeconfigDefaultPath(t *testing.T) {
+	_, k := newKubernetesServer(t, 2, nil)
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv("KUBECONFIG", path+string(os.PathListSeparator)+filepath.Join(t.TempDir(), "other"))
+
+	if err := k.MergeKubeconfig(""); err != nil {
+		t.Fatalf("MergeKubeconfig: %s", err)
+	}
+	if config := readKubeconfig(t, path); config.CurrentContext != "apps" {
+		t.Errorf("current-context = %s, want apps", config.CurrentContext)
+	}
+}
+
+func TestWriteKubeconfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     os.FileMode
+		wantMode os.FileMode
+	}{
+		{name: "default mode", wantMode: 0600},
+		{name: "given mode", mode: 0640, wantMode: 0640},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, k := newKubernetesServer(t, 2, nil)
+			path := filepath.Join(t.TempDir(), "apps.yaml")
+
+			if err := k.WriteKubeconfig(path, tt.mode); err != nil {
+				t.Fatalf("WriteKubeconfig: %s", err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != tt.wantMode {
+				t.Errorf("mode = %s, want %s", info.Mode().Perm(), tt.wantMode)
+			}
+			if config := readKubeconfig(t, path); len(config.Clusters) != 1 || config.Clusters[0].Name != "apps" {
+				t.Errorf("wrote %+v, want the apps cluster", config.Clusters)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path string, content string, mode os.FileMode) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readKubeconfig(t *testing.T, path string) *bcc.Kubeconfig {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := bcc.ParseKubeconfig(b)
+	if err != nil {
+		t.Fatalf("ParseKubeconfig: %s", err)
+	}
+
+	return config
+}
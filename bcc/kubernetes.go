@@ -1,8 +1,9 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"iter"
 	"net/url"
 )
 
@@ -31,6 +32,11 @@ type Kubernetes struct {
 	NodeDiskSize       int             `json:"node_disk_size"`
 	NodeStorageProfile *StorageProfile `json:"node_storage_profile"`
 
+	// NodeGroups are extra worker pools next to the one described by the
+	// Node... fields.
+	NodeGroups []*KubernetesNodeGroup `json:"node_groups,omitempty"`
+	Autoscaler *KubernetesAutoscaler  `json:"autoscaler,omitempty"`
+
 	Locked bool   `json:"locked"`
 	JobId  string `json:"job_id"`
 	Tags   []Tag  `json:"tags"`
@@ -49,86 +55,128 @@ func NewKubernetes(name string, nodeCpu int, nodeRam int, nodesCount int, nodeDi
 }
 
 func (m *Manager) ListKubernetes(extraArgs ...Arguments) (k8s []*Kubernetes, err error) {
-	path := "v1/kubernetes"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.ListKubernetesCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &k8s); err != nil {
-		log.Printf("[REQUEST-ERROR] list-kubernetes was failed: %s", err)
-	} else {
-		for i := range k8s {
-			k8s[i].manager = m
-			for x := range k8s[i].Vms {
-				k8s[i].Vms[x].manager = m
-			}
-		}
+func (m *Manager) ListKubernetesCtx(ctx context.Context, extraArgs ...Arguments) (k8s []*Kubernetes, err error) {
+	if k8s, err = Collect(m.AllKubernetesCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] list-kubernetes was failed: %s", err)
 	}
 
 	return
 }
 
-func (k *Kubernetes) GetKubernetesConfigUrl() (err error) {
-	path := fmt.Sprintf("/v1/kubernetes/%s/config", k.ID)
-	var config *string
+func (m *Manager) AllKubernetes(extraArgs ...ListOptions) iter.Seq2[*Kubernetes, error] {
+	return m.AllKubernetesCtx(m.ctx, extraArgs...)
+}
 
-	if err = k.manager.Get(path, Defaults(), &config); err != nil {
-		log.Printf("[REQUEST-ERROR] get-kubernetes-config was failed: %s", err)
-	}
+func (m *Manager) AllKubernetesCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Kubernetes, error] {
+	path := "v1/kubernetes"
+	return listItems(m.WithContext(ctx), path, typedQuery[KubernetesListOptions](extraArgs), func(k8s *Kubernetes) {
+		k8s.manager = m
+		for x := range k8s.Vms {
+			k8s.Vms[x].manager = m
+		}
+		for x := range k8s.NodeGroups {
+			k8s.NodeGroups[x].bind(m, k8s.ID)
+		}
+	})
+}
 
-	return
+// Deprecated: use GetKubeconfig or WriteKubeconfig. GetKubernetesConfigUrl
+// saves the kubeconfig as kubectl-<id>.yaml in the working directory.
+func (k *Kubernetes) GetKubernetesConfigUrl() (err error) {
+	return k.GetKubernetesConfigUrlCtx(k.manager.ctx)
+}
+
+// Deprecated: use GetKubeconfig or WriteKubeconfigCtx.
+func (k *Kubernetes) GetKubernetesConfigUrlCtx(ctx context.Context) (err error) {
+	return k.WriteKubeconfigCtx(ctx, fmt.Sprintf("kubectl-%s.yaml", k.ID), 0)
 }
 
 func (k *Kubernetes) GetKubernetesDashBoardUrl() (dashboardUrl *KubernetesDashBoardUrl, err error) {
+	return k.GetKubernetesDashBoardUrlCtx(k.manager.ctx)
+}
+
+func (k *Kubernetes) GetKubernetesDashBoardUrlCtx(ctx context.Context) (dashboardUrl *KubernetesDashBoardUrl, err error) {
 	path := fmt.Sprintf("/v1/kubernetes/%s/dashboard", k.ID)
 
-	if err = k.manager.Get(path, Defaults(), &dashboardUrl); err != nil {
-		log.Printf("[REQUEST-ERROR] get-kubernetes-dashboard was failed: %s", err)
+	if err = k.manager.WithContext(ctx).Get(path, Defaults(), &dashboardUrl); err != nil {
+		k.manager.log("[REQUEST-ERROR] get-kubernetes-dashboard was failed: %s", err)
 	}
 
 	return
 }
 
 func (m *Manager) GetKubernetes(id string) (k8s *Kubernetes, err error) {
+	return m.GetKubernetesCtx(m.ctx, id)
+}
+
+func (m *Manager) GetKubernetesCtx(ctx context.Context, id string) (k8s *Kubernetes, err error) {
 	path, _ := url.JoinPath("/v1/kubernetes", id)
 
-	if err = m.Get(path, Defaults(), &k8s); err != nil {
-		log.Printf("[REQUEST-ERROR] get-kubernetes was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &k8s); err != nil {
+		m.log("[REQUEST-ERROR] get-kubernetes was failed: %s", err)
 	} else {
 		k8s.Vdc.manager = m
 		k8s.manager = m
+		for x := range k8s.NodeGroups {
+			k8s.NodeGroups[x].bind(m, k8s.ID)
+		}
 	}
 
 	return
 }
 
 func (v *Vdc) GetKubernetes(extraArgs ...Arguments) (k8s []*Kubernetes, err error) {
+	return v.GetKubernetesCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetKubernetesCtx(ctx context.Context, extraArgs ...Arguments) (k8s []*Kubernetes, err error) {
 	args := Arguments{
 		"vdc": v.ID,
 	}
 	args.merge(extraArgs)
-	k8s, err = v.manager.ListKubernetes(args)
+	k8s, err = v.manager.ListKubernetesCtx(ctx, args)
 	return
 }
 
+func (v *Vdc) AllKubernetes(extraArgs ...ListOptions) iter.Seq2[*Kubernetes, error] {
+	return v.AllKubernetesCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllKubernetesCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Kubernetes, error] {
+	args := Arguments{
+		"vdc": v.ID,
+	}
+	return v.manager.AllKubernetesCtx(ctx, args, listQuery(extraArgs))
+}
+
 func (v *Vdc) CreateKubernetes(k8s *Kubernetes) (err error) {
+	return v.CreateKubernetesCtx(v.manager.ctx, k8s)
+}
+
+func (v *Vdc) CreateKubernetesCtx(ctx context.Context, k8s *Kubernetes) (err error) {
 	path := "/v1/kubernetes"
 	type TempPortCreate struct {
 		ID string `json:"id"`
 	}
 
 	args := &struct {
-		Name               string   `json:"name"`
-		NodeCpu            int      `json:"node_cpu"`
-		NodeRam            int      `json:"node_ram"`
-		NodeDiskSize       int      `json:"node_disk_size"`
-		NodesCount         int      `json:"nodes_count"`
-		NodeStorageProfile *string  `json:"node_storage_profile"`
-		Vdc                *string  `json:"vdc"`
-		Template           *string  `json:"template"`
-		Floating           *string  `json:"floating"`
-		UserPublicKey      string   `json:"user_public_key"`
-		NodePlatform       *string  `json:"node_platform,omitempty"`
-		Tags               []string `json:"tags"`
+		Name               string                 `json:"name"`
+		NodeCpu            int                    `json:"node_cpu"`
+		NodeRam            int                    `json:"node_ram"`
+		NodeDiskSize       int                    `json:"node_disk_size"`
+		NodesCount         int                    `json:"nodes_count"`
+		NodeStorageProfile *string                `json:"node_storage_profile"`
+		Vdc                *string                `json:"vdc"`
+		Template           *string                `json:"template"`
+		Floating           *string                `json:"floating"`
+		UserPublicKey      string                 `json:"user_public_key"`
+		NodePlatform       *string                `json:"node_platform,omitempty"`
+		NodeGroups         []*nodeGroupCreateArgs `json:"node_groups,omitempty"`
+		Autoscaler         *KubernetesAutoscaler  `json:"autoscaler,omitempty"`
+		Tags               []string               `json:"tags"`
 	}{
 		Name:               k8s.Name,
 		NodeCpu:            k8s.NodeCpu,
@@ -141,9 +189,14 @@ func (v *Vdc) CreateKubernetes(k8s *Kubernetes) (err error) {
 		UserPublicKey:      k8s.UserPublicKey,
 		Floating:           nil,
 		NodePlatform:       nil,
+		Autoscaler:         k8s.Autoscaler,
 		Tags:               convertTagsToNames(k8s.Tags),
 	}
 
+	if err = k8s.Autoscaler.validate(); err != nil {
+		return
+	}
+
 	if k8s.Floating != nil {
 		args.Floating = k8s.Floating.IpAddress
 	}
@@ -152,30 +205,45 @@ func (v *Vdc) CreateKubernetes(k8s *Kubernetes) (err error) {
 		args.NodePlatform = &k8s.NodePlatform.ID
 	}
 
-	if err = v.manager.Request("POST", path, args, &k8s); err != nil {
-		log.Printf("[REQUEST-ERROR] create-kubernetes was failed: %s", err)
+	for _, nodeGroup := range k8s.NodeGroups {
+		if err = nodeGroup.validate(); err != nil {
+			return
+		}
+		args.NodeGroups = append(args.NodeGroups, nodeGroup.createArgs())
+	}
+
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, &k8s); err != nil {
+		v.manager.log("[REQUEST-ERROR] create-kubernetes was failed: %s", err)
 	} else {
 		k8s.manager = v.manager
 		for idx := range k8s.Vms {
 			k8s.Vms[idx].manager = v.manager
 		}
+		for idx := range k8s.NodeGroups {
+			k8s.NodeGroups[idx].bind(v.manager, k8s.ID)
+		}
 	}
 
 	return
 }
 
 func (k *Kubernetes) Update() (err error) {
+	return k.UpdateCtx(k.manager.ctx)
+}
+
+func (k *Kubernetes) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("/v1/kubernetes", k.ID)
 	args := &struct {
-		Name               string   `json:"name"`
-		Floating           *string  `json:"floating"`
-		NodesCount         int      `json:"nodes_count"`
-		NodesRam           int      `json:"node_ram"`
-		NodesCpu           int      `json:"node_cpu"`
-		NodeDiskSize       int      `json:"node_disk_size"`
-		NodeStorageProfile string   `json:"node_storage_profile"`
-		UserPublicKey      string   `json:"user_public_key"`
-		Tags               []string `json:"tags"`
+		Name               string                `json:"name"`
+		Floating           *string               `json:"floating"`
+		NodesCount         int                   `json:"nodes_count"`
+		NodesRam           int                   `json:"node_ram"`
+		NodesCpu           int                   `json:"node_cpu"`
+		NodeDiskSize       int                   `json:"node_disk_size"`
+		NodeStorageProfile string                `json:"node_storage_profile"`
+		UserPublicKey      string                `json:"user_public_key"`
+		Autoscaler         *KubernetesAutoscaler `json:"autoscaler,omitempty"`
+		Tags               []string              `json:"tags"`
 	}{
 		Name:               k.Name,
 		Floating:           nil,
@@ -185,9 +253,14 @@ func (k *Kubernetes) Update() (err error) {
 		NodeDiskSize:       k.NodeDiskSize,
 		NodeStorageProfile: k.NodeStorageProfile.ID,
 		UserPublicKey:      k.UserPublicKey,
+		Autoscaler:         k.Autoscaler,
 		Tags:               convertTagsToNames(k.Tags),
 	}
 
+	if err = k.Autoscaler.validate(); err != nil {
+		return
+	}
+
 	if k.Floating != nil {
 		if k.Floating.ID != "" {
 			args.Floating = &k.Floating.ID
@@ -196,19 +269,27 @@ func (k *Kubernetes) Update() (err error) {
 		}
 	}
 
-	if err = k.manager.Request("PUT", path, args, k); err != nil {
-		log.Printf("[REQUEST-ERROR] update-kubernetes was failed: %s", err)
+	if err = k.manager.WithContext(ctx).Request("PUT", path, args, k); err != nil {
+		k.manager.log("[REQUEST-ERROR] update-kubernetes was failed: %s", err)
 	}
 
 	return
 }
 
 func (k *Kubernetes) Delete() error {
+	return k.DeleteCtx(k.manager.ctx)
+}
+
+func (k *Kubernetes) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/kubernetes", k.ID)
-	return k.manager.Delete(path, Defaults(), nil)
+	return k.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (k Kubernetes) WaitLock() error {
+	return k.WaitLockCtx(k.manager.ctx)
+}
+
+func (k Kubernetes) WaitLockCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/kubernetes", k.ID)
-	return loopWaitLock(k.manager, path)
+	return loopWaitLock(k.manager.WithContext(ctx), path)
 }
@@ -0,0 +1,208 @@
+package bcc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// KubernetesAutoscaler is the autoscaling policy of the default node pool
+// of a cluster, applied by the platform itself.
+type KubernetesAutoscaler struct {
+	Enabled  bool
+	MinNodes int
+	MaxNodes int
+	// ScaleDownDelay is how long a node has to be unneeded before it is
+	// removed. The API works in whole seconds.
+	ScaleDownDelay time.Duration
+}
+
+type kubernetesAutoscalerJSON struct {
+	Enabled        bool `json:"enabled"`
+	MinNodes       int  `json:"min_nodes"`
+	MaxNodes       int  `json:"max_nodes"`
+	ScaleDownDelay int  `json:"scale_down_delay"`
+}
+
+func (a KubernetesAutoscaler) MarshalJSON() ([]byte, error) {
+	return json.Marshal(kubernetesAutoscalerJSON{
+		Enabled:        a.Enabled,
+		MinNodes:       a.MinNodes,
+		MaxNodes:       a.MaxNodes,
+		ScaleDownDelay: int(a.ScaleDownDelay / time.Second),
+	})
+}
+
+func (a *KubernetesAutoscaler) UnmarshalJSON(b []byte) error {
+	var raw kubernetesAutoscalerJSON
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	a.Enabled = raw.Enabled
+	a.MinNodes = raw.MinNodes
+	a.MaxNodes = raw.MaxNodes
+	a.ScaleDownDelay = time.Duration(raw.ScaleDownDelay) * time.Second
+	return nil
+}
+
+func (a *KubernetesAutoscaler) validate() error {
+	if a == nil || !a.Enabled {
+		return nil
+	}
+
+	if a.MinNodes < 1 {
+		return fmt.Errorf("%w: autoscaler min nodes must be at least 1, got %d", ErrValidation, a.MinNodes)
+	}
+	if a.MaxNodes < a.MinNodes {
+		return fmt.Errorf("%w: autoscaler max nodes %d is below min nodes %d", ErrValidation, a.MaxNodes, a.MinNodes)
+	}
+	if a.ScaleDownDelay < 0 {
+		return fmt.Errorf("%w: autoscaler scale down delay must not be negative, got %s", ErrValidation, a.ScaleDownDelay)
+	}
+
+	return nil
+}
+
+// SetAutoscaler stores the autoscaling policy of the cluster. A nil policy
+// leaves the current one untouched, use Enabled: false to switch it off.
+func (k *Kubernetes) SetAutoscaler(autoscaler *KubernetesAutoscaler) error {
+	return k.SetAutoscalerCtx(k.manager.ctx, autoscaler)
+}
+
+func (k *Kubernetes) SetAutoscalerCtx(ctx context.Context, autoscaler *KubernetesAutoscaler) error {
+	previous := k.Autoscaler
+	k.Autoscaler = autoscaler
+	if err := k.UpdateCtx(ctx); err != nil {
+		k.Autoscaler = previous
+		return err
+	}
+
+	return nil
+}
+
+// KubernetesReconciler resizes the default node pool of a cluster from the
+// client side. On every Reconcile it asks Metric for the current load and
+// sets NodesCount to ceil(load / TargetPerNode), kept within MinNodes and
+// MaxNodes. A resize is skipped while the cluster is locked, and until the
+// cooldown of its direction has passed since the previous resize in either
+// direction: a scale down waits ScaleDownCooldown after any resize, a scale
+// up ScaleUpCooldown. Clusters with the platform autoscaler enabled are
+// refused, the two would fight over NodesCount.
+type KubernetesReconciler struct {
+	Kubernetes *Kubernetes
+	// Metric returns the total load of the cluster, e.g. requested cpu
+	// cores or queued jobs.
+	Metric        func(ctx context.Context) (float64, error)
+	TargetPerNode float64
+
+	MinNodes          int
+	MaxNodes          int
+	ScaleUpCooldown   time.Duration
+	ScaleDownCooldown time.Duration
+
+	lastResize time.Time
+}
+
+func (r *KubernetesReconciler) validate() error {
+	switch {
+	case r.Kubernetes == nil:
+		return fmt.Errorf("%w: reconciler needs a cluster", ErrValidation)
+	case r.Metric == nil:
+		return fmt.Errorf("%w: reconciler needs a metric", ErrValidation)
+	case r.TargetPerNode <= 0:
+		return fmt.Errorf("%w: reconciler target per node must be positive, got %g", ErrValidation, r.TargetPerNode)
+	case r.MinNodes < 1 || r.MaxNodes < r.MinNodes:
+		return fmt.Errorf("%w: reconciler bounds [%d, %d] are invalid", ErrValidation, r.MinNodes, r.MaxNodes)
+	}
+
+	return nil
+}
+
+// Desired returns the node count for the given load.
+func (r *KubernetesReconciler) Desired(load float64) int {
+	desired := int(math.Ceil(load / r.TargetPerNode))
+	return min(max(desired, r.MinNodes), r.MaxNodes)
+}
+
+// Reconcile performs a single step and reports whether the cluster was
+// resized.
+func (r *KubernetesReconciler) Reconcile(ctx context.Context) (bool, error) {
+	if err := r.validate(); err != nil {
+		return false, err
+	}
+
+	k := r.Kubernetes
+	m := k.manager
+
+	current, err := m.GetKubernetesCtx(ctx, k.ID)
+	if err != nil {
+		return false, err
+	}
+	*k = *current
+	if k.Autoscaler != nil && k.Autoscaler.Enabled {
+		return false, fmt.Errorf("%w: kubernetes %s is scaled by the platform autoscaler, disable it before reconciling", ErrValidation, k.ID)
+	}
+	if k.Locked {
+		m.log("[bcc] Kubernetes %s is locked, skip reconcile", k.ID)
+		return false, nil
+	}
+
+	load, err := r.Metric(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	desired := r.Desired(load)
+	if desired == k.NodesCount {
+		return false, nil
+	}
+
+	cooldown := r.ScaleDownCooldown
+	if desired > k.NodesCount {
+		cooldown = r.ScaleUpCooldown
+	}
+	if !r.lastResize.IsZero() && time.Since(r.lastResize) < cooldown {
+		m.log("[bcc] Kubernetes %s wants %d nodes, cooling down", k.ID, desired)
+		return false, nil
+	}
+
+	m.log("[bcc] Resize kubernetes %s from %d to %d nodes", k.ID, k.NodesCount, desired)
+	previous := k.NodesCount
+	k.NodesCount = desired
+	if err := k.UpdateCtx(ctx); err != nil {
+		k.NodesCount = previous
+		return false, err
+	}
+
+	r.lastResize = time.Now()
+	return true, nil
+}
+
+// Run calls Reconcile every interval until ctx is done. Errors are logged
+// and do not stop the loop.
+func (r *KubernetesReconciler) Run(ctx context.Context, interval time.Duration) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return fmt.Errorf("%w: reconcile interval must be positive, got %s", ErrValidation, interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Reconcile(ctx); err != nil {
+			r.Kubernetes.manager.log("[REQUEST-ERROR] reconcile of kubernetes %s was failed: %s", r.Kubernetes.ID, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package bcc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+func newKubernetesServer(t *testing.T, nodes int, autoscaler bcctest.Object) (*bcctest.Server, *bcc.Kubernetes) {
+	t.Helper()
+
+	s, m := newServer(t,
+		mainVdc,
+		object("storage_profile", bcctest.Object{"id": "sp1", "name": "ssd"}),
+		object("kubernetes_template", bcctest.Object{"id": "k8s-tpl1", "name": "1.30"}),
+		object("kubernetes", bcctest.Object{
+			"id":                   "k8s1",
+			"name":                 "apps",
+			"vdc":                  "vdc1",
+			"template":             "k8s-tpl1",
+			"node_storage_profile": "sp1",
+			"nodes_count":          nodes,
+			"autoscaler":           autoscaler,
+		}),
+	)
+
+	k, err := m.GetKubernetes("k8s1")
+	if err != nil {
+		t.Fatalf("GetKubernetes: %s", err)
+	}
+
+	return s, k
+}
+
+func load(value float64) func(context.Context) (float64, error) {
+	return func(context.Context) (float64, error) { return value, nil }
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name        string
+		nodes       int
+		load        float64
+		locked      bool
+		autoscaler  bcctest.Object
+		wantResized bool
+		wantNodes   int
+		wantErr     error
+	}{
+		{name: "scale up", nodes: 2, load: 4.5, wantResized: true, wantNodes: 5},
+		{name: "scale down", nodes: 6, load: 2, wantResized: true, wantNodes: 2},
+		{name: "capped at max", nodes: 2, load: 100, wantResized: true, wantNodes: 8},
+		{name: "kept at min", nodes: 3, load: 0, wantResized: true, wantNodes: 1},
+		{name: "steady", nodes: 3, load: 3, wantNodes: 3},
+		{name: "locked", nodes: 2, load: 5, locked: true, wantNodes: 2},
+		{name: "autoscaler disabled", nodes: 2, load: 3, autoscaler: bcctest.Object{"enabled": false}, wantResized: true, wantNodes: 3},
+		{
+			name:       "autoscaler enabled",
+			nodes:      2,
+			load:       5,
+			autoscaler: bcctest.Object{"enabled": true, "min_nodes": 1, "max_nodes": 4},
+			wantNodes:  2,
+			wantErr:    bcc.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, k := newKubernetesServer(t, tt.nodes, tt.autoscaler)
+			if tt.locked {
+				s.Lock("kubernetes", "k8s1")
+			}
+
+			r := &bcc.KubernetesReconciler{Kubernetes: k, Metric: load(tt.load), TargetPerNode: 1, MinNodes: 1, MaxNodes: 8}
+			resized, err := r.Reconcile(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Reconcile error = %v, want %v", err, tt.wantErr)
+			}
+			if resized != tt.wantResized {
+				t.Errorf("resized = %t, want %t", resized, tt.wantResized)
+			}
+			if got := s.Get("kubernetes", "k8s1")["nodes_count"]; got != float64(tt.wantNodes) && got != tt.wantNodes {
+				t.Errorf("server has %v nodes, want %d", got, tt.wantNodes)
+			}
+		})
+	}
+}
+
+func TestReconcileCooldown(t *testing.T) {
+	type step struct {
+		load        float64
+		wantResized bool
+		wantNodes   int
+	}
+
+	tests := []struct {
+		name         string
+		nodes        int
+		upCooldown   time.Duration
+		downCooldown time.Duration
+		steps        []step
+	}{
+		{
+			name:         "scale down waits after a scale up",
+			nodes:        2,
+			downCooldown: time.Hour,
+			steps: []step{
+				{load: 4, wantResized: true, wantNodes: 4},
+				{load: 1, wantNodes: 4},
+				// scaling up again has no cooldown
+				{load: 6, wantResized: true, wantNodes: 6},
+				{load: 1, wantNodes: 6},
+			},
+		},
+		{
+			name:       "scale up waits after a scale down",
+			nodes:      6,
+			upCooldown: time.Hour,
+			steps: []step{
+				{load: 2, wantResized: true, wantNodes: 2},
+				{load: 5, wantNodes: 2},
+				// scaling down again has no cooldown
+				{load: 1, wantResized: true, wantNodes: 1},
+				{load: 5, wantNodes: 1},
+			},
+		},
+		{
+			name:         "same direction waits too",
+			nodes:        2,
+			upCooldown:   time.Hour,
+			downCooldown: time.Hour,
+			steps: []step{
+				{load: 4, wantResized: true, wantNodes: 4},
+				{load: 6, wantNodes: 4},
+				{load: 1, wantNodes: 4},
+			},
+		},
+		{
+			name:  "no cooldown",
+			nodes: 2,
+			steps: []step{
+				{load: 4, wantResized: true, wantNodes: 4},
+				{load: 1, wantResized: true, wantNodes: 1},
+				{load: 3, wantResized: true, wantNodes: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, k := newKubernetesServer(t, tt.nodes, nil)
+
+			current := 0.0
+			r := &bcc.KubernetesReconciler{
+				Kubernetes:        k,
+				Metric:            func(context.Context) (float64, error) { return current, nil },
+				TargetPerNode:     1,
+				MinNodes:          1,
+				MaxNodes:          10,
+				ScaleUpCooldown:   tt.upCooldown,
+				ScaleDownCooldown: tt.downCooldown,
+			}
+
+			for i, step := range tt.steps {
+				current = step.load
+				resized, err := r.Reconcile(context.Background())
+				if err != nil {
+					t.Fatalf("step %d: Reconcile: %s", i, err)
+				}
+				if resized != step.wantResized || k.NodesCount != step.wantNodes {
+					t.Errorf("step %d: resized = %t with %d nodes, want %t with %d", i, resized, k.NodesCount, step.wantResized, step.wantNodes)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcilerRunRejectsInterval(t *testing.T) {
+	_, k := newKubernetesServer(t, 2, nil)
+	r := &bcc.KubernetesReconciler{Kubernetes: k, Metric: load(2), TargetPerNode: 1, MinNodes: 1, MaxNodes: 4}
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := r.Run(ctx, interval)
+		cancel()
+		if !errors.Is(err, bcc.ErrValidation) {
+			t.Errorf("Run(%s) error = %v, want ErrValidation", interval, err)
+		}
+	}
+}
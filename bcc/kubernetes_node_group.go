@@ -0,0 +1,279 @@
+package bcc
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+)
+
+const (
+	TaintEffectNoSchedule       = "NoSchedule"
+	TaintEffectPreferNoSchedule = "PreferNoSchedule"
+	TaintEffectNoExecute        = "NoExecute"
+)
+
+// KubernetesNodeGroup is a pool of identical worker nodes of a cluster.
+// Labels and taints are applied to every node of the pool.
+type KubernetesNodeGroup struct {
+	manager      *Manager
+	ID           string `json:"id"`
+	KubernetesId string
+
+	Name               string            `json:"name"`
+	NodeCpu            int               `json:"node_cpu"`
+	NodeRam            int               `json:"node_ram"`
+	NodeDiskSize       int               `json:"node_disk_size"`
+	NodesCount         int               `json:"nodes_count"`
+	NodePlatform       *Platform         `json:"node_platform"`
+	NodeStorageProfile *StorageProfile   `json:"node_storage_profile"`
+	Labels             map[string]string `json:"labels"`
+	Taints             []KubernetesTaint `json:"taints"`
+	Vms                []*TmpVm          `json:"vms"`
+
+	Locked bool   `json:"locked"`
+	JobId  string `json:"job_id"`
+}
+
+type KubernetesTaint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Effect string `json:"effect"`
+}
+
+func NewKubernetesNodeGroup(name string, nodeCpu int, nodeRam int, nodesCount int, nodeDiskSize int, nodeStorageProfile *StorageProfile, nodePlatform *Platform) KubernetesNodeGroup {
+	return KubernetesNodeGroup{
+		Name:               name,
+		NodeCpu:            nodeCpu,
+		NodeRam:            nodeRam,
+		NodesCount:         nodesCount,
+		NodeDiskSize:       nodeDiskSize,
+		NodeStorageProfile: nodeStorageProfile,
+		NodePlatform:       nodePlatform,
+	}
+}
+
+func (m *Manager) GetKubernetesNodeGroups(k8sId string, extraArgs ...Arguments) (nodeGroups []*KubernetesNodeGroup, err error) {
+	return m.GetKubernetesNodeGroupsCtx(m.ctx, k8sId, extraArgs...)
+}
+
+func (m *Manager) GetKubernetesNodeGroupsCtx(ctx context.Context, k8sId string, extraArgs ...Arguments) (nodeGroups []*KubernetesNodeGroup, err error) {
+	if nodeGroups, err = Collect(m.AllKubernetesNodeGroupsCtx(ctx, k8sId, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-kubernetes-node-groups was failed: %s", err)
+	}
+
+	return
+}
+
+func (m *Manager) AllKubernetesNodeGroups(k8sId string, extraArgs ...ListOptions) iter.Seq2[*KubernetesNodeGroup, error] {
+	return m.AllKubernetesNodeGroupsCtx(m.ctx, k8sId, extraArgs...)
+}
+
+func (m *Manager) AllKubernetesNodeGroupsCtx(ctx context.Context, k8sId string, extraArgs ...ListOptions) iter.Seq2[*KubernetesNodeGroup, error] {
+	path := fmt.Sprintf("v1/kubernetes/%s/node_group", k8sId)
+	return listItems(m.WithContext(ctx), path, typedQuery[KubernetesNodeGroupListOptions](extraArgs), func(nodeGroup *KubernetesNodeGroup) {
+		nodeGroup.bind(m, k8sId)
+	})
+}
+
+func (k *Kubernetes) GetNodeGroups(extraArgs ...Arguments) (nodeGroups []*KubernetesNodeGroup, err error) {
+	return k.GetNodeGroupsCtx(k.manager.ctx, extraArgs...)
+}
+
+func (k *Kubernetes) GetNodeGroupsCtx(ctx context.Context, extraArgs ...Arguments) (nodeGroups []*KubernetesNodeGroup, err error) {
+	nodeGroups, err = k.manager.GetKubernetesNodeGroupsCtx(ctx, k.ID, extraArgs...)
+	return
+}
+
+func (k *Kubernetes) AllNodeGroups(extraArgs ...ListOptions) iter.Seq2[*KubernetesNodeGroup, error] {
+	return k.AllNodeGroupsCtx(k.manager.ctx, extraArgs...)
+}
+
+func (k *Kubernetes) AllNodeGroupsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*KubernetesNodeGroup, error] {
+	return k.manager.AllKubernetesNodeGroupsCtx(ctx, k.ID, extraArgs...)
+}
+
+func (k *Kubernetes) GetNodeGroup(id string) (nodeGroup *KubernetesNodeGroup, err error) {
+	return k.GetNodeGroupCtx(k.manager.ctx, id)
+}
+
+func (k *Kubernetes) GetNodeGroupCtx(ctx context.Context, id string) (nodeGroup *KubernetesNodeGroup, err error) {
+	path := fmt.Sprintf("v1/kubernetes/%s/node_group/%s", k.ID, id)
+
+	if err = k.manager.WithContext(ctx).Get(path, Defaults(), &nodeGroup); err != nil {
+		k.manager.log("[REQUEST-ERROR] get-kubernetes-node-group was failed: %s", err)
+	} else {
+		nodeGroup.bind(k.manager, k.ID)
+	}
+
+	return
+}
+
+func (k *Kubernetes) CreateNodeGroup(nodeGroup *KubernetesNodeGroup) (err error) {
+	return k.CreateNodeGroupCtx(k.manager.ctx, nodeGroup)
+}
+
+func (k *Kubernetes) CreateNodeGroupCtx(ctx context.Context, nodeGroup *KubernetesNodeGroup) (err error) {
+	if err = nodeGroup.validate(); err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("v1/kubernetes/%s/node_group", k.ID)
+	args := nodeGroup.createArgs()
+
+	if err = k.manager.WithContext(ctx).Request("POST", path, args, &nodeGroup); err != nil {
+		k.manager.log("[REQUEST-ERROR] create-kubernetes-node-group was failed: %s", err)
+	} else {
+		nodeGroup.bind(k.manager, k.ID)
+	}
+
+	return
+}
+
+// Resize changes the number of nodes in the pool.
+func (g *KubernetesNodeGroup) Resize(nodesCount int) error {
+	return g.ResizeCtx(g.manager.ctx, nodesCount)
+}
+
+func (g *KubernetesNodeGroup) ResizeCtx(ctx context.Context, nodesCount int) error {
+	nodeGroup := *g
+	nodeGroup.NodesCount = nodesCount
+	if err := nodeGroup.UpdateCtx(ctx); err != nil {
+		return err
+	}
+
+	*g = nodeGroup
+	return nil
+}
+
+// Update applies the name, size, labels and taints of the pool. Node
+// resources are fixed once the pool is created.
+func (g *KubernetesNodeGroup) Update() (err error) {
+	return g.UpdateCtx(g.manager.ctx)
+}
+
+func (g *KubernetesNodeGroup) UpdateCtx(ctx context.Context) (err error) {
+	if err = g.validate(); err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("v1/kubernetes/%s/node_group/%s", g.KubernetesId, g.ID)
+	args := &struct {
+		Name       string            `json:"name"`
+		NodesCount int               `json:"nodes_count"`
+		Labels     map[string]string `json:"labels"`
+		Taints     []KubernetesTaint `json:"taints"`
+	}{
+		Name:       g.Name,
+		NodesCount: g.NodesCount,
+		Labels:     g.labels(),
+		Taints:     g.taints(),
+	}
+
+	if err = g.manager.WithContext(ctx).Request("PUT", path, args, g); err != nil {
+		g.manager.log("[REQUEST-ERROR] update-kubernetes-node-group was failed: %s", err)
+	} else {
+		g.bind(g.manager, g.KubernetesId)
+	}
+
+	return
+}
+
+func (g *KubernetesNodeGroup) Delete() error {
+	return g.DeleteCtx(g.manager.ctx)
+}
+
+func (g *KubernetesNodeGroup) DeleteCtx(ctx context.Context) error {
+	path := fmt.Sprintf("v1/kubernetes/%s/node_group/%s", g.KubernetesId, g.ID)
+	return g.manager.WithContext(ctx).Delete(path, Defaults(), nil)
+}
+
+func (g KubernetesNodeGroup) WaitLock() error {
+	return g.WaitLockCtx(g.manager.ctx)
+}
+
+func (g KubernetesNodeGroup) WaitLockCtx(ctx context.Context) error {
+	path := fmt.Sprintf("v1/kubernetes/%s/node_group/%s", g.KubernetesId, g.ID)
+	return loopWaitLock(g.manager.WithContext(ctx), path)
+}
+
+type nodeGroupCreateArgs struct {
+	Name               string            `json:"name"`
+	NodeCpu            int               `json:"node_cpu"`
+	NodeRam            int               `json:"node_ram"`
+	NodeDiskSize       int               `json:"node_disk_size"`
+	NodesCount         int               `json:"nodes_count"`
+	NodeStorageProfile *string           `json:"node_storage_profile"`
+	NodePlatform       *string           `json:"node_platform,omitempty"`
+	Labels             map[string]string `json:"labels"`
+	Taints             []KubernetesTaint `json:"taints"`
+}
+
+func (g *KubernetesNodeGroup) createArgs() *nodeGroupCreateArgs {
+	args := &nodeGroupCreateArgs{
+		Name:         g.Name,
+		NodeCpu:      g.NodeCpu,
+		NodeRam:      g.NodeRam,
+		NodeDiskSize: g.NodeDiskSize,
+		NodesCount:   g.NodesCount,
+		Labels:       g.labels(),
+		Taints:       g.taints(),
+	}
+
+	if g.NodeStorageProfile != nil {
+		args.NodeStorageProfile = &g.NodeStorageProfile.ID
+	}
+	if g.NodePlatform != nil {
+		args.NodePlatform = &g.NodePlatform.ID
+	}
+
+	return args
+}
+
+func (g *KubernetesNodeGroup) bind(m *Manager, k8sId string) {
+	g.manager = m
+	g.KubernetesId = k8sId
+	for x := range g.Vms {
+		g.Vms[x].manager = m
+	}
+}
+
+func (g *KubernetesNodeGroup) validate() error {
+	if g.NodesCount < 0 {
+		return fmt.Errorf("%w: nodes count must not be negative, got %d", ErrValidation, g.NodesCount)
+	}
+
+	for key := range g.Labels {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("%w: label keys must not be blank", ErrValidation)
+		}
+	}
+
+	for _, taint := range g.Taints {
+		if strings.TrimSpace(taint.Key) == "" {
+			return fmt.Errorf("%w: taint keys must not be blank", ErrValidation)
+		}
+		switch taint.Effect {
+		case TaintEffectNoSchedule, TaintEffectPreferNoSchedule, TaintEffectNoExecute:
+		default:
+			return fmt.Errorf("%w: taint %q has unknown effect %q", ErrValidation, taint.Key, taint.Effect)
+		}
+	}
+
+	return nil
+}
+
+// labels and taints never send null, so an empty pool clears them.
+func (g *KubernetesNodeGroup) labels() map[string]string {
+	if g.Labels == nil {
+		return map[string]string{}
+	}
+	return g.Labels
+}
+
+func (g *KubernetesNodeGroup) taints() []KubernetesTaint {
+	if g.Taints == nil {
+		return []KubernetesTaint{}
+	}
+	return g.Taints
+}
@@ -1,7 +1,8 @@
 package bcc
 
 import (
-	"log"
+	"context"
+	"iter"
 	"net/url"
 )
 
@@ -15,28 +16,41 @@ type KubernetesTemplate struct {
 }
 
 func (v *Vdc) GetKubernetesTemplates(extraArgs ...Arguments) (templates []*KubernetesTemplate, err error) {
+	return v.GetKubernetesTemplatesCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetKubernetesTemplatesCtx(ctx context.Context, extraArgs ...Arguments) (templates []*KubernetesTemplate, err error) {
+	if templates, err = Collect(v.AllKubernetesTemplatesCtx(ctx, arguments(extraArgs))); err != nil {
+		v.manager.log("[REQUEST-ERROR] get-KubernetesTemplates failed: %s", err)
+	}
+
+	return
+}
+
+func (v *Vdc) AllKubernetesTemplates(extraArgs ...ListOptions) iter.Seq2[*KubernetesTemplate, error] {
+	return v.AllKubernetesTemplatesCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllKubernetesTemplatesCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*KubernetesTemplate, error] {
 	path := "/v1/kubernetes_template"
 	args := Arguments{
 		"vdc": v.ID,
 	}
-	args.merge(extraArgs)
-
-	if err = v.manager.GetItems(path, args, &templates); err != nil {
-		log.Printf("[REQUEST-ERROR] get-KubernetesTemplates failed: %s", err)
-	} else {
-		for i := range templates {
-			templates[i].manager = v.manager
-		}
-	}
 
-	return
+	return listItems(v.manager.WithContext(ctx).cached(CacheKubernetesTemplates), path, typedQuery[KubernetesTemplateListOptions]{args, listQuery(extraArgs)}, func(template *KubernetesTemplate) {
+		template.manager = v.manager
+	})
 }
 
 func (m *Manager) GetKubernetesTemplate(id string) (template *KubernetesTemplate, err error) {
+	return m.GetKubernetesTemplateCtx(m.ctx, id)
+}
+
+func (m *Manager) GetKubernetesTemplateCtx(ctx context.Context, id string) (template *KubernetesTemplate, err error) {
 	path, _ := url.JoinPath("v1/kubernetes_template", id)
 
-	if err = m.Get(path, Defaults(), &template); err != nil {
-		log.Printf("[REQUEST-ERROR] get-KubernetesTemplate was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &template); err != nil {
+		m.log("[REQUEST-ERROR] get-KubernetesTemplate was failed: %s", err)
 	} else {
 		template.manager = m
 	}
@@ -0,0 +1,178 @@
+package bcc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KubernetesUpgrade follows a cluster moving to another template, which the
+// API does by replacing the nodes one after another.
+type KubernetesUpgrade struct {
+	Kubernetes *Kubernetes
+	Template   *KubernetesTemplate
+	// Job is the task carrying out the upgrade. It is nil when the API did
+	// not report one, in which case the cluster lock is followed instead.
+	Job *Job
+}
+
+// CheckNodes reports whether nodes of the given size satisfy the minimal
+// requirements of the template.
+func (t *KubernetesTemplate) CheckNodes(nodeCpu int, nodeRam int, nodeDiskSize int) error {
+	switch {
+	case nodeCpu < t.MinNodeCpu:
+		return fmt.Errorf("%w: template %s needs at least %d cpu per node, got %d", ErrValidation, t.Name, t.MinNodeCpu, nodeCpu)
+	case nodeRam < t.MinNodeRam:
+		return fmt.Errorf("%w: template %s needs at least %d ram per node, got %d", ErrValidation, t.Name, t.MinNodeRam, nodeRam)
+	case nodeDiskSize < t.MinNodeHdd:
+		return fmt.Errorf("%w: template %s needs at least %d disk per node, got %d", ErrValidation, t.Name, t.MinNodeHdd, nodeDiskSize)
+	}
+
+	return nil
+}
+
+// UpgradeTo moves the cluster to template once the nodes of the cluster and
+// of all its node groups were checked against the template requirements.
+// When the names of both templates carry a version, like "1.30", the new
+// one must be the higher. The upgrade runs in the background, see
+// KubernetesUpgrade.Wait.
+func (k *Kubernetes) UpgradeTo(template *KubernetesTemplate) (*KubernetesUpgrade, error) {
+	return k.UpgradeToCtx(k.manager.ctx, template)
+}
+
+func (k *Kubernetes) UpgradeToCtx(ctx context.Context, template *KubernetesTemplate) (*KubernetesUpgrade, error) {
+	if template == nil {
+		return nil, fmt.Errorf("%w: template is required", ErrValidation)
+	}
+	if k.Template != nil && k.Template.ID == template.ID {
+		return nil, fmt.Errorf("%w: cluster %s already runs template %s", ErrValidation, k.Name, template.Name)
+	}
+	if k.Template != nil {
+		current, target := templateVersion(k.Template.Name), templateVersion(template.Name)
+		if current != nil && target != nil && slices.Compare(target, current) <= 0 {
+			return nil, fmt.Errorf("%w: cluster %s runs template %s, %s is not newer", ErrValidation, k.Name, k.Template.Name, template.Name)
+		}
+	}
+
+	if err := template.CheckNodes(k.NodeCpu, k.NodeRam, k.NodeDiskSize); err != nil {
+		return nil, err
+	}
+	for _, nodeGroup := range k.NodeGroups {
+		if err := template.CheckNodes(nodeGroup.NodeCpu, nodeGroup.NodeRam, nodeGroup.NodeDiskSize); err != nil {
+			return nil, fmt.Errorf("node group %s: %w", nodeGroup.Name, err)
+		}
+	}
+
+	path := fmt.Sprintf("v1/kubernetes/%s/upgrade", k.ID)
+	args := &struct {
+		Template string `json:"template"`
+	}{
+		Template: template.ID,
+	}
+
+	jobs, err := k.manager.WithContext(ctx).RequestJobs("POST", path, args, k)
+	if err != nil {
+		k.manager.log("[REQUEST-ERROR] upgrade-kubernetes was failed: %s", err)
+		return nil, err
+	}
+
+	upgrade := &KubernetesUpgrade{Kubernetes: k, Template: template}
+	if len(jobs) > 0 {
+		upgrade.Job = jobs[0]
+	} else {
+		upgrade.Job = k.Job()
+	}
+
+	return upgrade, nil
+}
+
+// Nodes refreshes the cluster and returns its nodes. A locked node is being
+// replaced.
+func (u *KubernetesUpgrade) Nodes(ctx context.Context) ([]*TmpVm, error) {
+	k8s, err := u.Kubernetes.manager.GetKubernetesCtx(ctx, u.Kubernetes.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	*u.Kubernetes = *k8s
+	for x := range u.Kubernetes.Vms {
+		u.Kubernetes.Vms[x].manager = u.Kubernetes.manager
+	}
+
+	return u.Kubernetes.Vms, nil
+}
+
+// Wait blocks until the upgrade is over, ctx is done or Manager.JobTimeout
+// elapses. progress, when not nil, is called with the nodes of the cluster
+// on every poll. A failed upgrade is reported as a *JobError, a job status
+// that is neither running nor final as ErrUnknownJobStatus.
+func (u *KubernetesUpgrade) Wait(ctx context.Context, progress func(nodes []*TmpVm)) error {
+	m := u.Kubernetes.manager
+	if m.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.JobTimeout)
+		defer cancel()
+	}
+
+	interval := m.JobPollInterval
+	if interval <= 0 {
+		interval = RetryTime * time.Millisecond
+	}
+
+	for {
+		if u.Job != nil {
+			if err := u.Job.poll(m.WithContext(ctx)); err != nil {
+				return err
+			}
+			if u.Job.Failed() {
+				return &JobError{Job: u.Job, Step: u.Job.Name}
+			}
+			if !u.Job.Done() && !u.Job.Pending() {
+				return fmt.Errorf("%w %q of task %s", ErrUnknownJobStatus, u.Job.Status, u.Job.ID)
+			}
+		}
+
+		nodes, err := u.Nodes(ctx)
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(nodes)
+		}
+
+		if (u.Job != nil && u.Job.Done()) || (u.Job == nil && !u.Kubernetes.Locked) {
+			return nil
+		}
+
+		if err := SleepWithContext(ctx, interval); err != nil {
+			m.log("[bcc] Waiting upgrade of kubernetes %s took more than %s", u.Kubernetes.ID, m.JobTimeout)
+			return err
+		}
+	}
+}
+
+var templateVersionRegexp = regexp.MustCompile(`[0-9]+(\.[0-9]+)*`)
+
+// templateVersion returns the version carried by the name of a template,
+// like [1 30] for "Kubernetes 1.30", or nil when it has none.
+func templateVersion(name string) []int {
+	match := templateVersionRegexp.FindString(name)
+	if match == "" {
+		return nil
+	}
+
+	var version []int
+	for _, part := range strings.Split(match, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil
+		}
+		version = append(version, n)
+	}
+
+	return version
+}
@@ -0,0 +1,277 @@
+package bcc_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+// newUpgradeServer serves k8s1 "apps" running the 1.29 template on two
+// nodes of 2 cpu, 4 ram and 20 disk, plus the node groups given, next to
+// the templates 1.28, 1.30, 1.30.2 and "edge".
+func newUpgradeServer(t *testing.T, nodeGroups ...bcctest.Object) (*bcctest.Server, *bcc.Kubernetes) {
+	t.Helper()
+
+	groups := make([]interface{}, 0, len(nodeGroups))
+	for _, group := range nodeGroups {
+		groups = append(groups, group)
+	}
+
+	s, m := newServer(t,
+		mainVdc,
+		object("storage_profile", bcctest.Object{"id": "sp1", "name": "ssd"}),
+		object("kubernetes_template", bcctest.Object{"id": "tpl-1.28", "name": "Kubernetes 1.28"}),
+		object("kubernetes_template", bcctest.Object{"id": "tpl-1.29", "name": "Kubernetes 1.29"}),
+		object("kubernetes_template", bcctest.Object{"id": "tpl-1.30", "name": "Kubernetes 1.30"}),
+		object("kubernetes_template", bcctest.Object{"id": "tpl-1.30.2", "name": "Kubernetes 1.30.2", "min_node_cpu": 4}),
+		object("kubernetes_template", bcctest.Object{"id": "tpl-edge", "name": "edge"}),
+		object("kubernetes", bcctest.Object{
+			"id":                   "k8s1",
+			"name":                 "apps",
+			"vdc":                  "vdc1",
+			"template":             "tpl-1.29",
+			"node_storage_profile": "sp1",
+			"nodes_count":          2,
+			"node_cpu":             2,
+			"node_ram":             4,
+			"node_disk_size":       20,
+			"node_groups":          groups,
+			"vms": []interface{}{
+				bcctest.Object{"id": "node0", "name": "apps-node0"},
+				bcctest.Object{"id": "node1", "name": "apps-node1"},
+			},
+		}),
+	)
+	m.JobTimeout = 5 * time.Second
+
+	k, err := m.GetKubernetes("k8s1")
+	if err != nil {
+		t.Fatalf("GetKubernetes: %s", err)
+	}
+
+	return s, k
+}
+
+func TestKubernetesTemplateCheckNodes(t *testing.T) {
+	template := &bcc.KubernetesTemplate{Name: "1.30", MinNodeCpu: 2, MinNodeRam: 4, MinNodeHdd: 20}
+
+	tests := []struct {
+		name    string
+		cpu     int
+		ram     int
+		disk    int
+		wantErr string
+	}{
+		{name: "fits", cpu: 2, ram: 4, disk: 20},
+		{name: "larger", cpu: 8, ram: 16, disk: 100},
+		{name: "cpu", cpu: 1, ram: 4, disk: 20, wantErr: "template 1.30 needs at least 2 cpu per node, got 1"},
+		{name: "ram", cpu: 2, ram: 2, disk: 20, wantErr: "template 1.30 needs at least 4 ram per node, got 2"},
+		{name: "disk", cpu: 2, ram: 4, disk: 10, wantErr: "template 1.30 needs at least 20 disk per node, got 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := template.CheckNodes(tt.cpu, tt.ram, tt.disk)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckNodes: %s", err)
+				}
+				return
+			}
+			if !errors.Is(err, bcc.ErrValidation) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckNodes = %v, want a validation error saying %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKubernetesUpgradeTo(t *testing.T) {
+	tests := []struct {
+		name       string
+		nodeGroups []bcctest.Object
+		template   *bcc.KubernetesTemplate
+		wantErr    string
+	}{
+		{
+			name:     "newer minor",
+			template: &bcc.KubernetesTemplate{ID: "tpl-1.30", Name: "Kubernetes 1.30"},
+		},
+		{
+			name:     "unversioned",
+			template: &bcc.KubernetesTemplate{ID: "tpl-edge", Name: "edge"},
+		},
+		{
+			name:     "same template",
+			template: &bcc.KubernetesTemplate{ID: "tpl-1.29", Name: "Kubernetes 1.29"},
+			wantErr:  "cluster apps already runs template Kubernetes 1.29",
+		},
+		{
+			name:     "older",
+			template: &bcc.KubernetesTemplate{ID: "tpl-1.28", Name: "Kubernetes 1.28"},
+			wantErr:  "cluster apps runs template Kubernetes 1.29, Kubernetes 1.28 is not newer",
+		},
+		{
+			name:     "same version",
+			template: &bcc.KubernetesTemplate{ID: "tpl-1.29-bis", Name: "1.29"},
+			wantErr:  "cluster apps runs template Kubernetes 1.29, 1.29 is not newer",
+		},
+		{
+			name:     "nodes too small",
+			template: &bcc.KubernetesTemplate{ID: "tpl-1.30.2", Name: "Kubernetes 1.30.2", MinNodeCpu: 4},
+			wantErr:  "template Kubernetes 1.30.2 needs at least 4 cpu per node, got 2",
+		},
+		{
+			name:       "node group too small",
+			nodeGroups: []bcctest.Object{{"id": "ng1", "name": "gpu", "node_cpu": 8, "node_ram": 2, "node_disk_size": 20}},
+			template:   &bcc.KubernetesTemplate{ID: "tpl-1.30", Name: "Kubernetes 1.30", MinNodeRam: 4},
+			wantErr:    "template Kubernetes 1.30 needs at least 4 ram per node, got 2",
+		},
+		{
+			name:    "no template",
+			wantErr: "template is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, k := newUpgradeServer(t, tt.nodeGroups...)
+
+			upgrade, err := k.UpgradeTo(tt.template)
+			if tt.wantErr != "" {
+				if !errors.Is(err, bcc.ErrValidation) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("UpgradeTo = %v, want a validation error saying %q", err, tt.wantErr)
+				}
+				for _, r := range s.Requests() {
+					if r.Path == "/v1/kubernetes/k8s1/upgrade" {
+						t.Errorf("sent %s %s, want no upgrade", r.Method, r.Path)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpgradeTo: %s", err)
+			}
+
+			if upgrade.Job == nil {
+				t.Fatal("the upgrade carries no job")
+			}
+			if err := upgrade.Wait(context.Background(), nil); err != nil {
+				t.Fatalf("Wait: %s", err)
+			}
+			if k.Template.ID != tt.template.ID {
+				t.Errorf("cluster runs %s after the upgrade, want %s", k.Template.ID, tt.template.ID)
+			}
+		})
+	}
+}
+
+func TestKubernetesUpgradeWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		wantErr error
+		wantJob bool
+	}{
+		{name: "done"},
+		{name: "failed", status: bcc.JobStatusError, wantJob: true},
+		{name: "cancelled", status: bcc.JobStatusCancelled, wantJob: true},
+		{name: "unknown status", status: "paused", wantErr: bcc.ErrUnknownJobStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, k := newUpgradeServer(t)
+			s.JobPolls = 2
+
+			upgrade, err := k.UpgradeTo(&bcc.KubernetesTemplate{ID: "tpl-1.30", Name: "Kubernetes 1.30"})
+			if err != nil {
+				t.Fatalf("UpgradeTo: %s", err)
+			}
+			if tt.status != "" {
+				s.SetJobStatus(upgrade.Job.ID, tt.status)
+			}
+
+			start := time.Now()
+			err = upgrade.Wait(context.Background(), nil)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Wait took %s", elapsed)
+			}
+
+			var jobErr *bcc.JobError
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Wait error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantJob:
+				if !errors.As(err, &jobErr) {
+					t.Errorf("Wait error = %v, want a *bcc.JobError", err)
+				}
+			case err != nil:
+				t.Errorf("Wait: %s", err)
+			}
+		})
+	}
+}
+
+func TestKubernetesUpgradeProgress(t *testing.T) {
+	s, k := newUpgradeServer(t)
+	s.JobPolls = 3
+	s.Lock("vm", "node0")
+
+	upgrade, err := k.UpgradeTo(&bcc.KubernetesTemplate{ID: "tpl-1.30", Name: "Kubernetes 1.30"})
+	if err != nil {
+		t.Fatalf("UpgradeTo: %s", err)
+	}
+
+	var replacing []string
+	err = upgrade.Wait(context.Background(), func(nodes []*bcc.TmpVm) {
+		if len(nodes) != 2 {
+			t.Errorf("progress reported %d nodes, want 2", len(nodes))
+		}
+		var locked []string
+		for _, node := range nodes {
+			if node.Locked {
+				locked = append(locked, node.Name)
+			}
+		}
+		replacing = append(replacing, strings.Join(locked, ","))
+
+		// the first node is replaced, then the second one
+		switch len(replacing) {
+		case 2:
+			s.Unlock("vm", "node0")
+			s.Lock("vm", "node1")
+		case 3:
+			s.Unlock("vm", "node1")
+		}
+	})
+	if err != nil {
+		t.Fatalf("Wait: %s", err)
+	}
+
+	if got := strings.Join(replacing, "|"); got != "apps-node0|apps-node0|apps-node1|" {
+		t.Errorf("replaced nodes by poll = %q, want apps-node0|apps-node0|apps-node1|", got)
+	}
+}
+
+func TestKubernetesUpgradeWaitLock(t *testing.T) {
+	s, k := newUpgradeServer(t)
+	s.Lock("kubernetes", "k8s1")
+	unlock := time.AfterFunc(50*time.Millisecond, func() { s.Unlock("kubernetes", "k8s1") })
+	defer unlock.Stop()
+
+	upgrade := &bcc.KubernetesUpgrade{Kubernetes: k}
+	start := time.Now()
+	if err := upgrade.Wait(context.Background(), nil); err != nil {
+		t.Fatalf("Wait: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Wait returned after %s, before the cluster was unlocked", elapsed)
+	}
+}
@@ -0,0 +1,580 @@
+package bcc
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxPageSize is the largest page the API serves.
+const MaxPageSize = 1000
+
+// ListOptions narrows down a list request. It is implemented by the raw
+// Arguments map and by the typed ...ListOptions structs below. The All...
+// list methods accept Arguments and the options struct of their own resource,
+// any other options fail with ErrValidation. The Get... list methods keep
+// taking Arguments only.
+//
+// Values validates the options and encodes them as query parameters.
+type ListOptions interface {
+	Values() (url.Values, error)
+}
+
+func (args Arguments) Values() (url.Values, error) {
+	return args.ToURLValues(), nil
+}
+
+// listQuery combines several options. Keys set by a later option replace the
+// same keys set by an earlier one.
+type listQuery []ListOptions
+
+func (q listQuery) Values() (url.Values, error) {
+	values := url.Values{}
+	for _, opts := range q {
+		if opts == nil {
+			continue
+		}
+
+		v, err := opts.Values()
+		if err != nil {
+			return nil, err
+		}
+		for key, items := range v {
+			values[key] = items
+		}
+	}
+
+	return values, nil
+}
+
+// typedQuery is a listQuery that only admits Arguments and options of type T,
+// so that options meant for another resource are refused instead of silently
+// sending the wrong filters.
+type typedQuery[T ListOptions] listQuery
+
+func (q typedQuery[T]) Values() (url.Values, error) {
+	if err := checkListOptions[T](q); err != nil {
+		return nil, err
+	}
+	return listQuery(q).Values()
+}
+
+func checkListOptions[T ListOptions](opts []ListOptions) error {
+	for _, o := range opts {
+		switch o := o.(type) {
+		case nil, Arguments, T:
+		case listQuery:
+			if err := checkListOptions[T](o); err != nil {
+				return err
+			}
+		default:
+			var want T
+			return fmt.Errorf("%w: %T cannot be used here, expected %T or Arguments", ErrValidation, o, want)
+		}
+	}
+	return nil
+}
+
+// arguments adapts the Arguments taken by the Get... list methods.
+func arguments(extraArgs []Arguments) listQuery {
+	q := make(listQuery, len(extraArgs))
+	for i, args := range extraArgs {
+		q[i] = args
+	}
+	return q
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// query accumulates the parameters of a typed list option struct and the
+// first validation error found while doing so.
+type query struct {
+	values url.Values
+	err    error
+}
+
+func newQuery() *query {
+	return &query{values: url.Values{}}
+}
+
+func (q *query) fail(format string, args ...interface{}) {
+	if q.err == nil {
+		q.err = fmt.Errorf("%w: %s", ErrValidation, fmt.Sprintf(format, args...))
+	}
+}
+
+func (q *query) id(key string, id string) {
+	if id == "" {
+		return
+	}
+	if !uuidRegexp.MatchString(id) {
+		q.fail("%s must be a UUID, got %q", key, id)
+		return
+	}
+	q.values.Set(key, id)
+}
+
+func (q *query) str(key string, value string) {
+	if value != "" {
+		q.values.Set(key, value)
+	}
+}
+
+func (q *query) oneOf(key string, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	if !slices.Contains(allowed, value) {
+		q.fail("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value)
+		return
+	}
+	q.values.Set(key, value)
+}
+
+func (q *query) boolean(key string, value *bool) {
+	if value != nil {
+		q.values.Set(key, strconv.FormatBool(*value))
+	}
+}
+
+func (q *query) tags(tags []string) {
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			q.fail("tags must not be blank")
+			return
+		}
+		q.values.Add("tags", tag)
+	}
+}
+
+// page encodes the ordering and page size shared by every list. Sort fields
+// may be prefixed with "-" for descending order.
+func (q *query) page(sort []string, pageSize int, sortable ...string) {
+	for _, field := range sort {
+		if !slices.Contains(sortable, strings.TrimPrefix(field, "-")) {
+			q.fail("cannot sort by %q, expected one of %s", field, strings.Join(sortable, ", "))
+			return
+		}
+		q.values.Add("sort", field)
+	}
+
+	if pageSize < 0 || pageSize > MaxPageSize {
+		q.fail("page size must be between 0 and %d, got %d", MaxPageSize, pageSize)
+		return
+	}
+	if pageSize > 0 {
+		q.values.Set("limit", strconv.Itoa(pageSize))
+	}
+}
+
+func (q *query) result() (url.Values, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	return q.values, nil
+}
+
+type ProjectListOptions struct {
+	Client   string
+	Name     string
+	Tags     []string
+	Sort     []string
+	PageSize int
+}
+
+func (o ProjectListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("client", o.Client)
+	q.str("name", o.Name)
+	q.tags(o.Tags)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type VdcListOptions struct {
+	Project  string
+	Name     string
+	Tags     []string
+	Sort     []string
+	PageSize int
+}
+
+func (o VdcListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("project", o.Project)
+	q.str("name", o.Name)
+	q.tags(o.Tags)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type VmListOptions struct {
+	Vdc      string
+	Project  string
+	Tags     []string
+	Name     string
+	Power    *bool
+	Sort     []string
+	PageSize int
+}
+
+func (o VmListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.id("project", o.Project)
+	q.tags(o.Tags)
+	q.str("name", o.Name)
+	q.boolean("power", o.Power)
+	q.page(o.Sort, o.PageSize, "name", "cpu", "ram", "power")
+	return q.result()
+}
+
+type DiskListOptions struct {
+	Vdc      string
+	Project  string
+	Vm       string
+	Tags     []string
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o DiskListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.id("project", o.Project)
+	q.id("vm", o.Vm)
+	q.tags(o.Tags)
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name", "size")
+	return q.result()
+}
+
+type NetworkListOptions struct {
+	Vdc      string
+	Project  string
+	Tags     []string
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o NetworkListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.id("project", o.Project)
+	q.tags(o.Tags)
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+const (
+	PortFilterInternal = "internal"
+	PortFilterExternal = "external"
+)
+
+type PortListOptions struct {
+	Vdc     string
+	Network string
+	// FilterType is PortFilterInternal or PortFilterExternal.
+	FilterType string
+	Tags       []string
+	Sort       []string
+	PageSize   int
+}
+
+func (o PortListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.id("network", o.Network)
+	q.oneOf("filter_type", o.FilterType, PortFilterInternal, PortFilterExternal)
+	q.tags(o.Tags)
+	q.page(o.Sort, o.PageSize, "ip_address")
+	return q.result()
+}
+
+type RouterListOptions struct {
+	Vdc      string
+	Project  string
+	Tags     []string
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o RouterListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.id("project", o.Project)
+	q.tags(o.Tags)
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type KubernetesListOptions struct {
+	Vdc      string
+	Project  string
+	Tags     []string
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o KubernetesListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.id("project", o.Project)
+	q.tags(o.Tags)
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type AffinityGroupListOptions struct {
+	Vdc      string
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o AffinityGroupListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type BackupPolicyListOptions struct {
+	Vdc      string
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o BackupPolicyListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type RestorePointListOptions struct {
+	BackupPolicy string
+	Vm           string
+	Disk         string
+	Sort         []string
+	PageSize     int
+}
+
+func (o RestorePointListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("backup_policy", o.BackupPolicy)
+	q.id("vm", o.Vm)
+	q.id("disk", o.Disk)
+	q.page(o.Sort, o.PageSize, "created_at", "size")
+	return q.result()
+}
+
+type ClientListOptions struct {
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o ClientListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type DnsListOptions struct {
+	Project  string
+	Name     string
+	Tags     []string
+	Sort     []string
+	PageSize int
+}
+
+func (o DnsListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("project", o.Project)
+	q.str("name", o.Name)
+	q.tags(o.Tags)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type DnsRecordListOptions struct {
+	Host     string
+	Type     string
+	Sort     []string
+	PageSize int
+}
+
+func (o DnsRecordListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.str("host", o.Host)
+	q.oneOf("type", o.Type, "A", "AAAA", "CAA", "CNAME", "MX", "NS", "SRV", "TXT")
+	q.page(o.Sort, o.PageSize, "host", "type")
+	return q.result()
+}
+
+type FirewallTemplateListOptions struct {
+	Vdc      string
+	Name     string
+	Tags     []string
+	Sort     []string
+	PageSize int
+}
+
+func (o FirewallTemplateListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.str("name", o.Name)
+	q.tags(o.Tags)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type KubernetesNodeGroupListOptions struct {
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o KubernetesNodeGroupListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name", "nodes_count")
+	return q.result()
+}
+
+type KubernetesTemplateListOptions struct {
+	Vdc      string
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o KubernetesTemplateListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type LoadBalancerListOptions struct {
+	Vdc      string
+	Project  string
+	Tags     []string
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o LoadBalancerListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.id("project", o.Project)
+	q.tags(o.Tags)
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type SubnetListOptions struct {
+	Sort     []string
+	PageSize int
+}
+
+func (o SubnetListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.page(o.Sort, o.PageSize, "cidr")
+	return q.result()
+}
+
+type PaasTemplateListOptions struct {
+	Vdc      string
+	Name     string
+	Tags     []string
+	Sort     []string
+	PageSize int
+}
+
+func (o PaasTemplateListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc_id", o.Vdc)
+	q.str("name", o.Name)
+	q.tags(o.Tags)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type S3StorageListOptions struct {
+	Project  string
+	Name     string
+	Tags     []string
+	Sort     []string
+	PageSize int
+}
+
+func (o S3StorageListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("project", o.Project)
+	q.str("name", o.Name)
+	q.tags(o.Tags)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type BucketListOptions struct {
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o BucketListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
+
+type SnapshotListOptions struct {
+	Vm       string
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o SnapshotListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vm", o.Vm)
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name", "created_at")
+	return q.result()
+}
+
+type StorageProfileListOptions struct {
+	Vdc      string
+	Name     string
+	Sort     []string
+	PageSize int
+}
+
+func (o StorageProfileListOptions) Values() (url.Values, error) {
+	q := newQuery()
+	q.id("vdc", o.Vdc)
+	q.str("name", o.Name)
+	q.page(o.Sort, o.PageSize, "name")
+	return q.result()
+}
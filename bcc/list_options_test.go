@@ -0,0 +1,161 @@
+package bcc_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+const (
+	vdcA = "5b1a8f0e-2a0c-4c2e-9a51-1f3c2d4e5f60"
+	vdcB = "7d3c0a2e-4c2e-4e4a-bc73-3f5e4f607182"
+)
+
+func TestListOptionsValues(t *testing.T) {
+	power := false
+
+	tests := []struct {
+		name    string
+		opts    bcc.ListOptions
+		want    url.Values
+		wantErr bool
+	}{
+		{
+			name: "vm filters",
+			opts: bcc.VmListOptions{Vdc: vdcA, Tags: []string{"web", "prod"}, Power: &power, Sort: []string{"-cpu"}, PageSize: 50},
+			want: url.Values{"vdc": {vdcA}, "tags": {"web", "prod"}, "power": {"false"}, "sort": {"-cpu"}, "limit": {"50"}},
+		},
+		{
+			name: "port filter type",
+			opts: bcc.PortListOptions{FilterType: bcc.PortFilterExternal},
+			want: url.Values{"filter_type": {"external"}},
+		},
+		{
+			name: "paas template vdc key",
+			opts: bcc.PaasTemplateListOptions{Vdc: vdcA},
+			want: url.Values{"vdc_id": {vdcA}},
+		},
+		{name: "vdc is not a uuid", opts: bcc.VmListOptions{Vdc: "main"}, wantErr: true},
+		{name: "unknown sort field", opts: bcc.DiskListOptions{Sort: []string{"cpu"}}, wantErr: true},
+		{name: "page size too large", opts: bcc.SnapshotListOptions{PageSize: bcc.MaxPageSize + 1}, wantErr: true},
+		{name: "blank tag", opts: bcc.DnsListOptions{Tags: []string{" "}}, wantErr: true},
+		{name: "unknown filter type", opts: bcc.PortListOptions{FilterType: "public"}, wantErr: true},
+		{name: "unknown record type", opts: bcc.DnsRecordListOptions{Type: "PTR"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.Values()
+			if tt.wantErr {
+				if !errors.Is(err, bcc.ErrValidation) {
+					t.Fatalf("Values error = %v, want ErrValidation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Values: %s", err)
+			}
+			if got.Encode() != tt.want.Encode() {
+				t.Errorf("Values = %s, want %s", got.Encode(), tt.want.Encode())
+			}
+		})
+	}
+}
+
+func newTwoVdcServer(t *testing.T) (*bcctest.Server, *bcc.Manager) {
+	t.Helper()
+
+	return newServer(t,
+		object("vdc", bcctest.Object{"id": vdcA, "name": "a"}),
+		object("vdc", bcctest.Object{"id": vdcB, "name": "b"}),
+		object("vm", bcctest.Object{"id": "vm1", "name": "web", "vdc": vdcA}),
+		object("vm", bcctest.Object{"id": "vm2", "name": "db", "vdc": vdcA}),
+		object("vm", bcctest.Object{"id": "vm3", "name": "web", "vdc": vdcB}),
+	)
+}
+
+func TestListOptionsAcceptedType(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []bcc.ListOptions
+		wantVms int
+		wantErr bool
+	}{
+		{name: "no options", wantVms: 3},
+		{name: "own options", opts: []bcc.ListOptions{bcc.VmListOptions{Vdc: vdcA}}, wantVms: 2},
+		{name: "arguments", opts: []bcc.ListOptions{bcc.Arguments{"name": "web"}}, wantVms: 2},
+		{name: "arguments and own options", opts: []bcc.ListOptions{bcc.Arguments{"name": "web"}, bcc.VmListOptions{Vdc: vdcB}}, wantVms: 1},
+		{name: "options of another resource", opts: []bcc.ListOptions{bcc.PortListOptions{Vdc: vdcA}}, wantErr: true},
+		{name: "another resource after own options", opts: []bcc.ListOptions{bcc.VmListOptions{}, bcc.DiskListOptions{}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newTwoVdcServer(t)
+
+			vms, err := bcc.Collect(m.AllVms(tt.opts...))
+			if tt.wantErr {
+				if !errors.Is(err, bcc.ErrValidation) {
+					t.Fatalf("AllVms error = %v, want ErrValidation", err)
+				}
+				if n := listRequests(s, "/v1/vm"); n != 0 {
+					t.Errorf("sent %d list requests with rejected options", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AllVms: %s", err)
+			}
+			if len(vms) != tt.wantVms {
+				t.Errorf("got %d vms, want %d", len(vms), tt.wantVms)
+			}
+		})
+	}
+}
+
+func TestListOptionsThroughParent(t *testing.T) {
+	_, m := newTwoVdcServer(t)
+
+	vdc, err := m.GetVdc(vdcA)
+	if err != nil {
+		t.Fatalf("GetVdc: %s", err)
+	}
+
+	vms, err := bcc.Collect(vdc.AllVms(bcc.VmListOptions{Name: "web"}))
+	if err != nil {
+		t.Fatalf("AllVms: %s", err)
+	}
+	if len(vms) != 1 || vms[0].ID != "vm1" {
+		t.Errorf("got %d vms, want vm1 only", len(vms))
+	}
+
+	if _, err := bcc.Collect(vdc.AllVms(bcc.RouterListOptions{})); !errors.Is(err, bcc.ErrValidation) {
+		t.Errorf("AllVms with router options error = %v, want ErrValidation", err)
+	}
+}
+
+func TestGetListArguments(t *testing.T) {
+	s, m := newTwoVdcServer(t)
+
+	// spreading a []Arguments must keep compiling and filtering
+	filters := []bcc.Arguments{{"vdc": vdcA}, {"name": "db"}}
+	vms, err := m.GetVms(filters...)
+	if err != nil {
+		t.Fatalf("GetVms: %s", err)
+	}
+	if len(vms) != 1 || vms[0].ID != "vm2" {
+		t.Errorf("got %d vms, want vm2 only", len(vms))
+	}
+
+	for _, r := range s.Requests() {
+		if r.Method == http.MethodGet && r.Path == "/v1/vm" {
+			if q, _ := url.ParseQuery(r.Query); q.Get("vdc") != vdcA || q.Get("name") != "db" {
+				t.Errorf("list query = %s, want both filters", r.Query)
+			}
+		}
+	}
+}
@@ -1,8 +1,9 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"iter"
 	"net/url"
 )
 
@@ -85,40 +86,66 @@ func NewLoadBalancerPoolMember(port int, weight int, vm *TmpVm) PoolMember {
 }
 
 func (m *Manager) GetLoadBalancers(extraArgs ...Arguments) (lbaasList []*LoadBalancer, err error) {
-	path := "v1/lbaas"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetLoadBalancersCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &lbaasList); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-lbaas was failed: %s", err)
-	} else {
-		for i := range lbaasList {
-			lbaasList[i].manager = m
-			lbaasList[i].Port.manager = m
-			lbaasList[i].Vdc.manager = m
-			if lbaasList[i].Floating != nil {
-				lbaasList[i].Floating.manager = m
-			}
-		}
+func (m *Manager) GetLoadBalancersCtx(ctx context.Context, extraArgs ...Arguments) (lbaasList []*LoadBalancer, err error) {
+	if lbaasList, err = Collect(m.AllLoadBalancersCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR]: get-lbaas was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllLoadBalancers(extraArgs ...ListOptions) iter.Seq2[*LoadBalancer, error] {
+	return m.AllLoadBalancersCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllLoadBalancersCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*LoadBalancer, error] {
+	path := "v1/lbaas"
+	return listItems(m.WithContext(ctx), path, typedQuery[LoadBalancerListOptions](extraArgs), func(lbaas *LoadBalancer) {
+		lbaas.manager = m
+		lbaas.Port.manager = m
+		lbaas.Vdc.manager = m
+		if lbaas.Floating != nil {
+			lbaas.Floating.manager = m
+		}
+	})
+}
+
 func (v *Vdc) GetLoadBalancers(extraArgs ...Arguments) (lbaasList []*LoadBalancer, err error) {
+	return v.GetLoadBalancersCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetLoadBalancersCtx(ctx context.Context, extraArgs ...Arguments) (lbaasList []*LoadBalancer, err error) {
 	args := Arguments{
 		"vdc": v.ID,
 	}
 	args.merge(extraArgs)
-	lbaasList, err = v.manager.GetLoadBalancers(args)
+	lbaasList, err = v.manager.GetLoadBalancersCtx(ctx, args)
 	return
 }
 
+func (v *Vdc) AllLoadBalancers(extraArgs ...ListOptions) iter.Seq2[*LoadBalancer, error] {
+	return v.AllLoadBalancersCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllLoadBalancersCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*LoadBalancer, error] {
+	args := Arguments{
+		"vdc": v.ID,
+	}
+	return v.manager.AllLoadBalancersCtx(ctx, args, listQuery(extraArgs))
+}
+
 func (m *Manager) GetLoadBalancer(id string) (lbaas *LoadBalancer, err error) {
+	return m.GetLoadBalancerCtx(m.ctx, id)
+}
+
+func (m *Manager) GetLoadBalancerCtx(ctx context.Context, id string) (lbaas *LoadBalancer, err error) {
 	path, _ := url.JoinPath("v1/lbaas", id)
 
-	if err = m.Get(path, Defaults(), &lbaas); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-lbaas was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &lbaas); err != nil {
+		m.log("[REQUEST-ERROR]: get-lbaas was failed: %s", err)
 	} else {
 		lbaas.manager = m
 		lbaas.Port.manager = m
@@ -132,6 +159,10 @@ func (m *Manager) GetLoadBalancer(id string) (lbaas *LoadBalancer, err error) {
 }
 
 func (lb *LoadBalancer) Create() (err error) {
+	return lb.CreateCtx(lb.manager.ctx)
+}
+
+func (lb *LoadBalancer) CreateCtx(ctx context.Context) (err error) {
 	path := "v1/lbaas"
 	type customPort struct {
 		ID                string     `json:"id"`
@@ -170,14 +201,18 @@ func (lb *LoadBalancer) Create() (err error) {
 			lbCreate.Floating = lb.Floating.IpAddress
 		}
 	}
-	if err = lb.manager.Request("POST", path, lbCreate, &lb); err != nil {
-		log.Printf("[REQUEST-ERROR] lbaas.create was failed: %s", err)
+	if err = lb.manager.WithContext(ctx).Request("POST", path, lbCreate, &lb); err != nil {
+		lb.manager.log("[REQUEST-ERROR] lbaas.create was failed: %s", err)
 	}
 
 	return
 }
 
 func (v Vdc) CreateLoadBalancer(lb *LoadBalancer) (err error) {
+	return v.CreateLoadBalancerCtx(v.manager.ctx, lb)
+}
+
+func (v Vdc) CreateLoadBalancerCtx(ctx context.Context, lb *LoadBalancer) (err error) {
 	path := "v1/lbaas"
 	type customPort struct {
 		ID                string     `json:"id"`
@@ -214,8 +249,8 @@ func (v Vdc) CreateLoadBalancer(lb *LoadBalancer) (err error) {
 		lbCreate.Floating = &lb.Floating.ID
 	}
 
-	if err = lb.manager.Request("POST", path, lbCreate, &lb); err != nil {
-		log.Printf("[REQUEST-ERROR] create-lbaas was failed: %s", err)
+	if err = lb.manager.WithContext(ctx).Request("POST", path, lbCreate, &lb); err != nil {
+		v.manager.log("[REQUEST-ERROR] create-lbaas was failed: %s", err)
 	} else {
 		lb.manager = v.manager
 	}
@@ -224,6 +259,10 @@ func (v Vdc) CreateLoadBalancer(lb *LoadBalancer) (err error) {
 }
 
 func (lb *LoadBalancer) Update() (err error) {
+	return lb.UpdateCtx(lb.manager.ctx)
+}
+
+func (lb *LoadBalancer) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/lbaas", lb.ID)
 
 	args := &struct {
@@ -244,38 +283,50 @@ func (lb *LoadBalancer) Update() (err error) {
 			args.Floating = lb.Floating.IpAddress
 		}
 	}
-	if err = lb.manager.Request("PUT", path, args, lb); err != nil {
-		log.Printf("[REQUEST-ERROR] update-lbaas was failed: %s", err)
+	if err = lb.manager.WithContext(ctx).Request("PUT", path, args, lb); err != nil {
+		lb.manager.log("[REQUEST-ERROR] update-lbaas was failed: %s", err)
 	} else {
-		err = lb.WaitLock()
+		err = lb.WaitLockCtx(ctx)
 	}
 
 	return
 }
 
 func (lb *LoadBalancer) Delete() error {
+	return lb.DeleteCtx(lb.manager.ctx)
+}
+
+func (lb *LoadBalancer) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/lbaas", lb.ID)
-	return lb.manager.Delete(path, Defaults(), nil)
+	return lb.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 
 }
 
 func (lb *LoadBalancer) GetPools(extraArgs ...Arguments) (pools []*LoadBalancerPool, err error) {
+	return lb.GetPoolsCtx(lb.manager.ctx, extraArgs...)
+}
+
+func (lb *LoadBalancer) GetPoolsCtx(ctx context.Context, extraArgs ...Arguments) (pools []*LoadBalancerPool, err error) {
 	path := fmt.Sprintf("v1/lbaas/%s/pool", lb.ID)
 	args := Defaults()
 	args.merge(extraArgs)
 
-	if err = lb.manager.GetSubItems(path, args, &pools); err != nil {
-		log.Printf("[REQUEST-ERROR] get-lbaas-pools was failed: %s", err)
+	if err = lb.manager.WithContext(ctx).GetSubItems(path, args, &pools); err != nil {
+		lb.manager.log("[REQUEST-ERROR] get-lbaas-pools was failed: %s", err)
 	}
 
 	return
 }
 
 func (lb *LoadBalancer) GetLoadBalancerPool(id string) (lbaas_pool LoadBalancerPool, err error) {
+	return lb.GetLoadBalancerPoolCtx(lb.manager.ctx, id)
+}
+
+func (lb *LoadBalancer) GetLoadBalancerPoolCtx(ctx context.Context, id string) (lbaas_pool LoadBalancerPool, err error) {
 	path := fmt.Sprintf("v1/lbaas/%s/pool/%s", lb.ID, id)
 
-	if err = lb.manager.Get(path, Defaults(), &lbaas_pool); err != nil {
-		log.Printf("[REQUEST-ERROR] get-lbaas-pool was failed: %s", err)
+	if err = lb.manager.WithContext(ctx).Get(path, Defaults(), &lbaas_pool); err != nil {
+		lb.manager.log("[REQUEST-ERROR] get-lbaas-pool was failed: %s", err)
 	} else {
 		lbaas_pool.manager = lb.manager
 	}
@@ -284,6 +335,10 @@ func (lb *LoadBalancer) GetLoadBalancerPool(id string) (lbaas_pool LoadBalancerP
 }
 
 func (lb *LoadBalancer) CreatePool(pool *LoadBalancerPool) (err error) {
+	return lb.CreatePoolCtx(lb.manager.ctx, pool)
+}
+
+func (lb *LoadBalancer) CreatePoolCtx(ctx context.Context, pool *LoadBalancerPool) (err error) {
 	path := fmt.Sprintf("v1/lbaas/%s/pool", lb.ID)
 	type poolMember struct {
 		Port   int    `json:"port"`
@@ -317,14 +372,18 @@ func (lb *LoadBalancer) CreatePool(pool *LoadBalancerPool) (err error) {
 		CookieName:         pool.CookieName,
 	}
 
-	if err = lb.manager.Request("POST", path, args, &pool); err != nil {
-		log.Printf("[REQUEST-ERROR] create-lbaas-pool was failed: %s", err)
+	if err = lb.manager.WithContext(ctx).Request("POST", path, args, &pool); err != nil {
+		lb.manager.log("[REQUEST-ERROR] create-lbaas-pool was failed: %s", err)
 	}
 
 	return
 }
 
 func (lb *LoadBalancer) UpdatePool(pool *LoadBalancerPool) (err error) {
+	return lb.UpdatePoolCtx(lb.manager.ctx, pool)
+}
+
+func (lb *LoadBalancer) UpdatePoolCtx(ctx context.Context, pool *LoadBalancerPool) (err error) {
 	path := fmt.Sprintf("v1/lbaas/%s/pool/%s", lb.ID, pool.ID)
 
 	type poolMember struct {
@@ -361,31 +420,39 @@ func (lb *LoadBalancer) UpdatePool(pool *LoadBalancerPool) (err error) {
 		SessionPersistence: pool.SessionPersistence,
 	}
 
-	if err := lb.manager.Request("PUT", path, lbCreatePool, &pool); err != nil {
-		log.Printf("[REQUEST-ERROR] update-lbaas-pool was failed: %s", err)
+	if err := lb.manager.WithContext(ctx).Request("PUT", path, lbCreatePool, &pool); err != nil {
+		lb.manager.log("[REQUEST-ERROR] update-lbaas-pool was failed: %s", err)
 	}
 
 	return
 }
 
 func (lb *LoadBalancer) DeletePools() error {
-	pools, err := lb.GetPools()
+	return lb.DeletePoolsCtx(lb.manager.ctx)
+}
+
+func (lb *LoadBalancer) DeletePoolsCtx(ctx context.Context) error {
+	pools, err := lb.GetPoolsCtx(ctx)
 	if err != nil {
 		return err
 	}
 	for _, pool := range pools {
-		err = lb.DeletePool(pool.ID)
+		err = lb.DeletePoolCtx(ctx, pool.ID)
 		if err != nil {
 			return err
 		}
-		lb.WaitLock()
+		lb.WaitLockCtx(ctx)
 	}
 	return nil
 }
 
 func (lb *LoadBalancer) DeletePool(id string) error {
+	return lb.DeletePoolCtx(lb.manager.ctx, id)
+}
+
+func (lb *LoadBalancer) DeletePoolCtx(ctx context.Context, id string) error {
 	path := fmt.Sprintf("v1/lbaas/%s/pool/%s", lb.ID, id)
-	if err := lb.manager.Delete(path, Defaults(), nil); err != nil {
+	if err := lb.manager.WithContext(ctx).Delete(path, Defaults(), nil); err != nil {
 		return err
 	}
 
@@ -393,6 +460,10 @@ func (lb *LoadBalancer) DeletePool(id string) error {
 }
 
 func (lb LoadBalancer) WaitLock() error {
+	return lb.WaitLockCtx(lb.manager.ctx)
+}
+
+func (lb LoadBalancer) WaitLockCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/lbaas", lb.ID)
-	return loopWaitLock(lb.manager, path)
+	return loopWaitLock(lb.manager.WithContext(ctx), path)
 }
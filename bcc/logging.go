@@ -0,0 +1,134 @@
+package bcc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys lists the JSON keys whose values are never written to logs.
+var sensitiveKeys = map[string]bool{
+	"secret_key":      true,
+	"access_key":      true,
+	"password":        true,
+	"token":           true,
+	"private_key":     true,
+	"user_data":       true,
+	"kubeconfig":      true,
+	"client-key-data": true,
+	"client_key":      true,
+}
+
+// SetLogHandler makes the manager emit structured records through h: one
+// record per HTTP request with its method, path, status, duration, request
+// ID and task IDs, plus debug records for lock and task waiting. Request and
+// response bodies are logged at debug level with secrets redacted.
+//
+// A manager has no handler by default and writes nothing.
+func (m *Manager) SetLogHandler(h slog.Handler) {
+	if h == nil {
+		m.slog = nil
+		return
+	}
+
+	m.slog = slog.New(h)
+}
+
+func (m *Manager) log(format string, args ...interface{}) {
+	if m == nil {
+		return
+	}
+
+	if m.Logger != nil {
+		m.Logger.Debugf(format, args...)
+	}
+
+	if m.slog != nil && m.slog.Enabled(m.logContext(), slog.LevelDebug) {
+		m.slog.DebugContext(m.logContext(), fmt.Sprintf(format, args...))
+	}
+}
+
+// logRequest emits the record describing one completed HTTP exchange.
+func (m *Manager) logRequest(method string, path string, status int, duration time.Duration, requestID string, taskIds string, err error) {
+	if m.slog == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("path", path),
+		slog.Int("status", status),
+		slog.Duration("duration", duration),
+	}
+	if requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	if taskIds != "" {
+		attrs = append(attrs, slog.String("task_ids", taskIds))
+	}
+
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	m.slog.LogAttrs(m.logContext(), level, "bcc request", attrs...)
+}
+
+// logBody emits a request or response body at debug level with every
+// sensitive value replaced.
+func (m *Manager) logBody(msg string, path string, body []byte) {
+	if m.slog == nil || len(body) == 0 || !m.slog.Enabled(m.logContext(), slog.LevelDebug) {
+		return
+	}
+
+	m.slog.LogAttrs(m.logContext(), slog.LevelDebug, msg,
+		slog.String("path", path),
+		slog.String("body", redactBody(body)),
+	)
+}
+
+func (m *Manager) logContext() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+	return context.Background()
+}
+
+// redactBody returns body as a string with the values of sensitive keys
+// replaced. Bodies that are not JSON, such as kubeconfig files, are never
+// logged verbatim.
+func redactBody(body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+
+	b, _ := json.Marshal(redactValue(value))
+	return string(b)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if sensitiveKeys[strings.ToLower(key)] && item != nil {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,174 @@
+package bcc_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+var kubernetesCluster = []fixture{
+	mainVdc,
+	object("storage_profile", bcctest.Object{"id": "sp1", "name": "ssd"}),
+	object("kubernetes_template", bcctest.Object{"id": "k8s-tpl1", "name": "1.30"}),
+	object("kubernetes", bcctest.Object{"id": "k8s1", "name": "apps", "vdc": "vdc1", "template": "k8s-tpl1", "node_storage_profile": "sp1", "nodes_count": 2}),
+}
+
+func TestLogRedaction(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixtures []fixture
+		call     func(m *bcc.Manager) error
+		secrets  []string
+		want     string
+	}{
+		{
+			name: "s3 keys in a response",
+			fixtures: []fixture{
+				object("s3_storage", bcctest.Object{"id": "s3", "name": "assets", "access_key": "s3-access", "secret_key": "s3-secret"}),
+			},
+			call: func(m *bcc.Manager) error {
+				_, err := m.GetS3Storage("s3")
+				return err
+			},
+			secrets: []string{"s3-access", "s3-secret"},
+			want:    `\"secret_key\":\"[REDACTED]\"`,
+		},
+		{
+			name:     "password in a request and its response",
+			fixtures: []fixture{mainVdc, vms(1, "vdc1")},
+			call: func(m *bcc.Manager) error {
+				return m.Request(http.MethodPut, "v1/vm/vm0", map[string]interface{}{"name": "web0", "password": "vm-password"}, nil)
+			},
+			secrets: []string{"vm-password"},
+			want:    `\"password\":\"[REDACTED]\"`,
+		},
+		{
+			name:     "nested token",
+			fixtures: []fixture{mainVdc, vms(1, "vdc1")},
+			call: func(m *bcc.Manager) error {
+				metadata := []map[string]interface{}{{"field": "registry", "value": "ghcr.io", "token": "registry-token"}}
+				return m.Request(http.MethodPut, "v1/vm/vm0", map[string]interface{}{"name": "web0", "metadata": metadata}, nil)
+			},
+			secrets: []string{"registry-token"},
+			want:    `\"token\":\"[REDACTED]\"`,
+		},
+		{
+			name:     "kubeconfig field",
+			fixtures: []fixture{mainVdc, vms(1, "vdc1")},
+			call: func(m *bcc.Manager) error {
+				return m.Request(http.MethodPut, "v1/vm/vm0", map[string]interface{}{"name": "web0", "kubeconfig": "users:\n- user:\n    token: field-token\n"}, nil)
+			},
+			secrets: []string{"field-token"},
+			want:    `\"kubeconfig\":\"[REDACTED]\"`,
+		},
+		{
+			name:     "kubeconfig file",
+			fixtures: kubernetesCluster,
+			call: func(m *bcc.Manager) error {
+				k, err := m.GetKubernetes("k8s1")
+				if err != nil {
+					return err
+				}
+				_, err = k.GetKubeconfig(context.Background())
+				return err
+			},
+			secrets: []string{"token-k8s1"},
+			want:    `bytes]"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, m := newServer(t, tt.fixtures...)
+			var buf bytes.Buffer
+			m.SetLogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			if err := tt.call(m); err != nil {
+				t.Fatalf("call: %s", err)
+			}
+
+			logs := buf.String()
+			for _, secret := range tt.secrets {
+				if strings.Contains(logs, secret) {
+					t.Errorf("logs carry %q:\n%s", secret, logs)
+				}
+			}
+			if !strings.Contains(logs, tt.want) {
+				t.Errorf("logs lack %s:\n%s", tt.want, logs)
+			}
+			if !strings.Contains(logs, `"msg":"bcc request"`) || !strings.Contains(logs, `"msg":"bcc response body"`) {
+				t.Errorf("logs lack the request record or the response body:\n%s", logs)
+			}
+		})
+	}
+}
+
+func TestLogRequestError(t *testing.T) {
+	_, m := newServer(t)
+	var buf bytes.Buffer
+	m.SetLogHandler(slog.NewJSONHandler(&buf, nil))
+
+	if _, err := m.GetVm("missing"); err == nil {
+		t.Fatal("GetVm of a missing vm succeeded")
+	}
+
+	logs := buf.String()
+	for _, want := range []string{`"level":"WARN"`, `"method":"GET"`, `"path":"/v1/vm/missing"`, `"status":404`, `"error":`} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs lack %s:\n%s", want, logs)
+		}
+	}
+	if strings.Contains(logs, "bcc request body") || strings.Contains(logs, "bcc response body") {
+		t.Errorf("bodies were logged above debug level:\n%s", logs)
+	}
+}
+
+func TestNoLogHandler(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(m *bcc.Manager)
+	}{
+		{name: "default", setup: func(*bcc.Manager) {}},
+		{name: "handler removed", setup: func(m *bcc.Manager) {
+			m.SetLogHandler(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+			m.SetLogHandler(nil)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+			_, m := newServer(t, append(kubernetesCluster, vms(1, "vdc1"))...)
+			tt.setup(m)
+
+			if err := m.Request(http.MethodPut, "v1/vm/vm0", map[string]interface{}{"name": "web0", "password": "vm-password"}, nil); err != nil {
+				t.Fatalf("Request: %s", err)
+			}
+			if _, err := m.GetVm("missing"); err == nil {
+				t.Fatal("GetVm of a missing vm succeeded")
+			}
+			k, err := m.GetKubernetes("k8s1")
+			if err != nil {
+				t.Fatalf("GetKubernetes: %s", err)
+			}
+			if _, err := k.GetKubeconfig(context.Background()); err != nil {
+				t.Fatalf("GetKubeconfig: %s", err)
+			}
+
+			if buf.Len() > 0 {
+				t.Errorf("a manager without a handler logged:\n%s", buf.String())
+			}
+		})
+	}
+}
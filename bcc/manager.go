@@ -8,14 +8,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
-	"strings"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -37,7 +36,22 @@ type Manager struct {
 	Token           string
 	RequestTimeout  time.Duration
 	RequestInterval time.Duration
+	JobPollInterval time.Duration
+	JobTimeout      time.Duration
+	PageSize        int
+	PagePrefetch    int
 	UserAgent       string
+	RetryPolicy     *RetryPolicy
+	RateLimiter     RateLimiter
+	Tracer          Tracer
+	Metrics         Metrics
+	Cache           Cache
+	CacheTTL        map[CacheResource]time.Duration
+	middleware      []Middleware
+	inFlight        chan struct{}
+	slog            *slog.Logger
+	span            Span
+	cacheResource   CacheResource
 	ctx             context.Context
 }
 
@@ -67,6 +81,7 @@ type ObjectLocked struct {
 	NonFieldErrors []interface{} `json:"non_field_errors"`
 }
 
+// Deprecated: use Job.
 type Task struct {
 	Status string `json:"status"`
 	Name   string `json:"name"`
@@ -163,67 +178,72 @@ func NewManager(token string, caCert string, cert string, certKey string, insecu
 
 		Client: client,
 
-		BaseURL:   DefaultBaseURL,
-		Token:     token,
-		UserAgent: "bcc-go",
-		ctx:       context.Background(),
+		BaseURL:         DefaultBaseURL,
+		Token:           token,
+		UserAgent:       DefaultUserAgent,
+		RequestTimeout:  DefaultRequestTimeout,
+		RequestInterval: DefaultRequestInterval,
+		RetryPolicy:     DefaultRetryPolicy(),
+		JobPollInterval: DefaultRequestInterval,
+		JobTimeout:      DefaultJobTimeout,
+		ctx:             context.Background(),
 	}, nil
 }
 
 func (m *Manager) WithContext(ctx context.Context) *Manager {
 	newManager := *m
 	newManager.ctx = ctx
+	newManager.span = nil
 	return &newManager
 }
 
-func (m *Manager) Request(method string, path string, args interface{}, target interface{}) error {
-	m.log("[request-info] method:%s path:%s payload:%s", method, path, args)
+func (m *Manager) Request(method string, path string, args interface{}, target interface{}) (err error) {
+	m, span := m.traceOperation(method, path)
+	defer func() { endSpan(span, err) }()
 
-	res, err := json.Marshal(args)
+	taskIds, err := m.request(method, path, args, target)
 	if err != nil {
 		return err
 	}
 
-	requestUrl, _ := url.JoinPath(m.BaseURL, path)
+	return m.waitJobs(m.jobs(taskIds))
+}
+
+func (m *Manager) request(method string, path string, args interface{}, target interface{}) (string, error) {
+	m.log("[bcc] %s %s", method, path)
 
-	req, err := http.NewRequest(method, requestUrl, bytes.NewReader(res))
+	res, err := json.Marshal(args)
 	if err != nil {
-		log.Printf("[REQUEST-ERROR] Invalid %s request %s", method, requestUrl)
-		return err
+		return "", err
 	}
+	m.logBody("bcc request body", path, res)
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", m.Token))
-	req.Header.Set("Content-Type", "application/json")
-	req = req.WithContext(m.ctx)
-
-	taskIds, err := m.do(req, requestUrl, target, res)
-	m.waitTasks(taskIds)
+	call := &Call{
+		Method:  method,
+		Path:    path,
+		Payload: res,
+		Header:  http.Header{"Content-Type": {"application/json"}},
+	}
 
-	return err
+	return m.call(call, target)
 }
 
 func (m *Manager) Get(path string, args Arguments, target interface{}) error {
-	m.log("[bcc] GET %s", path)
-
-	params := args.ToURLValues()
-
-	request_url, _ := url.JoinPath(m.BaseURL, path)
-	urlWithParams := fmt.Sprintf("%s?%s", request_url, params.Encode())
-
-	req, err := http.NewRequest("GET", urlWithParams, nil)
-	if err != nil {
-		log.Printf("Invalid GET request %s", request_url)
-		return err
-	}
+	return m.get(path, args.ToURLValues(), target)
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", m.Token))
+func (m *Manager) get(path string, params url.Values, target interface{}) (err error) {
+	m, span := m.traceOperation("GET", path)
+	defer func() { endSpan(span, err) }()
 
-	req = req.WithContext(m.ctx)
+	m.log("[bcc] GET %s", path)
 
-	_, err = m.do(req, request_url, target, nil)
+	_, err = m.call(&Call{Method: "GET", Path: path, Query: params}, target)
 	return err
 }
 
+// GetItems reads every page of the list at path into target, which must
+// point to a slice. Use the All... iterators to stream large lists instead.
 func (m *Manager) GetItems(path string, args Arguments, target interface{}) error {
 	targetValue := reflect.ValueOf(target)
 	if reflect.TypeOf(target).Kind() == reflect.Pointer {
@@ -233,154 +253,150 @@ func (m *Manager) GetItems(path string, args Arguments, target interface{}) erro
 		return errors.Errorf("target must be slice %d", reflect.TypeOf(target).Kind())
 	}
 
-	params := args.ToURLValues()
-
-	page := 1
-	for {
-		params.Set("page", fmt.Sprint(page))
-
-		m.log("[bcc] GET %s?%s", path, params.Encode())
-
-		request_url, _ := url.JoinPath(m.BaseURL, path)
-		urlWithParams := fmt.Sprintf("%s?%s", request_url, params.Encode())
-
-		req, err := http.NewRequest("GET", urlWithParams, nil)
+	itemType := targetValue.Type().Elem()
+	for page, err := range m.pages(path, args) {
 		if err != nil {
-			log.Printf("Invalid GET request %s", request_url)
 			return err
 		}
 
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", m.Token))
-
-		req = req.WithContext(m.ctx)
-
-		type tempStruct struct {
-			Total int             `json:"total"`
-			Limit int             `json:"limit"`
-			Items json.RawMessage // To future unmarshalling
-		}
-
-		temp := new(tempStruct)
-
-		_, err = m.do(req, request_url, temp, nil)
-		if err != nil {
-			break
-		}
-		currentPageSize := min(temp.Total-temp.Limit*(page-1), temp.Limit)
-		currentItemsValue := reflect.New(targetValue.Type())
-		currentItemsValue.Elem().Set(reflect.MakeSlice(targetValue.Type(), 0, currentPageSize))
-		currentItems := currentItemsValue.Interface()
-		err = json.Unmarshal(temp.Items, currentItems)
-		if err != nil {
-			return errors.Wrapf(err, "JSON items decode failed on %s, page %d:", path, page)
-		}
-		targetValue.Set(reflect.AppendSlice(targetValue, currentItemsValue.Elem()))
-		if targetValue.Len() == temp.Total {
-			break
+		for _, raw := range page.Items {
+			item := reflect.New(itemType)
+			if err := json.Unmarshal(raw, item.Interface()); err != nil {
+				return errors.Wrapf(err, "JSON items decode failed on %s:", path)
+			}
+			targetValue.Set(reflect.Append(targetValue, item.Elem()))
 		}
-		page++
 	}
-	m.log("[bcc] Retrieved items: %+v", target)
+	m.log("[bcc] Retrieved %d items from %s", targetValue.Len(), path)
 	return nil
 }
 
-func (m *Manager) GetSubItems(path string, args Arguments, target interface{}) error {
+func (m *Manager) GetSubItems(path string, args Arguments, target interface{}) (err error) {
+	m, span := m.traceOperation("GET", path)
+	defer func() { endSpan(span, err) }()
 
 	m.log("[bcc] GET %s", path)
 
-	requestUrl, _ := url.JoinPath(m.BaseURL, path)
+	_, err = m.call(&Call{Method: "GET", Path: path}, target)
+	return err
+}
+
+func (m *Manager) Delete(path string, args Arguments, target interface{}) (err error) {
+	m, span := m.traceOperation("DELETE", path)
+	defer func() { endSpan(span, err) }()
 
-	req, err := http.NewRequest("GET", requestUrl, nil)
+	taskIds, err := m.delete(path, args, target)
 	if err != nil {
-		log.Printf("Invalid GET request %s", requestUrl)
 		return err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", m.Token))
+	return m.waitJobs(m.jobs(taskIds))
+}
+
+func (m *Manager) delete(path string, args Arguments, target interface{}) (string, error) {
+	m.log("[bcc] DELETE %s", path)
 
-	req = req.WithContext(m.ctx)
+	return m.call(&Call{Method: "DELETE", Path: path}, target)
+}
 
-	_, err = m.do(req, requestUrl, target, nil)
-	if err != nil {
-		return err
+// WaitTask waits for the job with the given id to finish.
+func (m *Manager) WaitTask(taskId string) error {
+	return m.newJob(taskId).Wait(m.ctx)
+}
+
+func (m *Manager) sleep(dur time.Duration) error {
+	if m.ctx != nil {
+		return SleepWithContext(m.ctx, dur)
+	} else {
+		time.Sleep(dur)
 	}
 
 	return nil
 }
 
-func (m *Manager) Delete(path string, args Arguments, target interface{}) error {
-	m.log("[bcc] DELETE %s", path)
-
-	request_url, _ := url.JoinPath(m.BaseURL, path)
+// call passes call through the middleware chain and decodes the response
+// body into target. It returns the X-Esu-Tasks header of the response.
+func (m *Manager) call(call *Call, target interface{}) (string, error) {
+	if call.Header == nil {
+		call.Header = http.Header{}
+	}
 
-	req, err := http.NewRequest("DELETE", request_url, nil)
+	result, err := m.chain().Do(m.ctx, call)
+	m.observeCall(call, result, err)
 	if err != nil {
-		log.Printf("Invalid DELETE request %s", request_url)
-		return err
+		return "", err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", m.Token))
-
-	taskIds, err := m.do(req, request_url, target, nil)
-	m.waitTasks(taskIds)
+	requestUrl, _ := url.JoinPath(m.BaseURL, call.Path)
+	taskIds := result.Header.Get("X-Esu-Tasks")
+	m.traceCreated(call.Method, call.Path, result.Body)
 
-	return err
-}
+	// task waiter
+	if taskIds != "" {
+		m.log("[bcc] Tasks IDS: %s", taskIds)
+	}
 
-func (m *Manager) WaitTask(taskId string) error {
-	m.log("[bcc] Start waiting task %s...", taskId)
+	if len(result.Body) == 0 {
+		return taskIds, nil
+	}
 
-	path, _ := url.JoinPath("v1/job", taskId)
-	start := time.Now()
-	var task Task
+	if target == nil {
+		// Don't try to unmarshall in case target is nil
+		return taskIds, nil
+	}
 
-	for {
-		err := m.Get(path, Arguments{}, task)
-		if err != nil {
-			break
-		}
-		if task.Status == "error" {
-			return errors.New(fmt.Sprintf("Task in error status, step: %s", task.Name))
-		}
+	// Files such as kubeconfigs are handed over as they are
+	if raw, ok := target.(*[]byte); ok {
+		*raw = result.Body
+		return taskIds, nil
+	}
 
-		if err := m.sleep(RetryTime * time.Millisecond); err != nil {
-			return err
-		}
+	err = json.Unmarshal(result.Body, target)
+	if err != nil {
+		return "", errors.Wrapf(err, "JSON decode failed on %s", requestUrl)
+	}
 
-		elapsedTime := time.Since(start)
+	return taskIds, nil
+}
 
-		if elapsedTime.Seconds() > float64(TaskTimeout) {
-			m.log("[bcc] Waiting task %s took more than %ds", taskId, TaskTimeout)
-			return errors.New("Task timeout")
-		}
+// roundTrip is the innermost stage of the middleware chain, it turns call
+// into an HTTP request and performs it.
+func (m *Manager) roundTrip(ctx context.Context, call *Call) (*CallResult, error) {
+	requestUrl, _ := url.JoinPath(m.BaseURL, call.Path)
+	fullUrl := requestUrl
+	if call.Query != nil {
+		fullUrl = fmt.Sprintf("%s?%s", requestUrl, call.Query.Encode())
 	}
 
-	m.log("[bcc] End waiting task %s", taskId)
-
-	return nil
-}
+	var body io.Reader
+	if call.Payload != nil {
+		body = bytes.NewReader(call.Payload)
+	}
 
-func (m *Manager) log(format string, args ...interface{}) {
-	if m.Logger != nil {
-		m.Logger.Debugf(format, args...)
+	req, err := http.NewRequestWithContext(ctx, call.Method, fullUrl, body)
+	if err != nil {
+		m.log("[bcc] Invalid %s request %s", call.Method, requestUrl)
+		return nil, err
 	}
-}
 
-func (m *Manager) sleep(dur time.Duration) error {
-	if m.ctx != nil {
-		return SleepWithContext(m.ctx, dur)
-	} else {
-		time.Sleep(dur)
+	for key, values := range call.Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", m.Token))
+	}
+	if req.Header.Get("User-Agent") == "" && m.UserAgent != "" {
+		req.Header.Set("User-Agent", m.UserAgent)
 	}
 
-	return nil
+	return m.WithContext(ctx).do(req, requestUrl, call.Payload)
 }
 
-func (m *Manager) do(req *http.Request, url string, target interface{}, requestBody []byte) (string, error) {
+func (m *Manager) do(req *http.Request, url string, requestBody []byte) (*CallResult, error) {
 	req.Header.Set("Accept-Language", "ru-ru")
 
-	var lockedObject ObjectLocked
 	var resp *http.Response
+	start := time.Now()
 
 	ctx, cancel := context.WithTimeout(m.ctx, m.RequestTimeout)
 	defer cancel()
@@ -388,41 +404,54 @@ func (m *Manager) do(req *http.Request, url string, target interface{}, requestB
 	ticker := time.NewTicker(m.RequestInterval)
 	defer ticker.Stop()
 
-	for {
-		m.log("[bcc] Perform %s...", req.Method)
+	var lockSpan Span
+	lockRetries := 0
+	defer func() {
+		if lockSpan != nil {
+			lockSpan.SetAttributes(Attr("bcc.lock_retries", lockRetries))
+			lockSpan.End()
+		}
+	}()
 
-		req.Body = io.NopCloser(bytes.NewReader(requestBody))
-		resp_, err := m.Client.Do(req)
+	for {
+		resp_, err := m.send(req, requestBody)
 		if err != nil {
-			return "", errors.Wrapf(err, "HTTP request failure on %s", url)
+			m.logRequest(req.Method, req.URL.Path, 0, time.Since(start), "", "", err)
+			return nil, errors.Wrapf(err, "HTTP request failure on %s", url)
 		}
 
-		defer resp_.Body.Close()
-
-		if resp_.StatusCode == 409 {
-			m.log("[bcc] Object '%s' locked. Try again in %dms...", url, RetryTime)
-
+		if resp_.StatusCode == http.StatusConflict {
 			body, err := io.ReadAll(resp_.Body)
-			err = json.Unmarshal(body, &lockedObject)
-
+			resp_.Body.Close()
 			if err != nil {
-				return "", errors.Wrapf(err, "HTTP Read error on response for %s", url)
+				return nil, errors.Wrapf(err, "HTTP Read error on response for %s", url)
 			}
 
-			if lockedObject.ErrorAlias != nil {
-				errorAlias := fmt.Sprintf("%v", lockedObject.ErrorAlias[0])
-				errorDetails, _ := json.Marshal(lockedObject.Details)
-				errorData := fmt.Sprintf("%v", lockedObject.NonFieldErrors[0])
-				if errorAlias != "object_locked" {
-					errorBody := fmt.Sprintf("%s: %s", errorData, string(errorDetails))
-					return "", errors.New(errorBody)
-				}
+			result := &CallResult{Status: resp_.StatusCode, Header: resp_.Header, Body: body}
+			apiErr := newApiError(url, resp_.StatusCode, body)
+			if len(apiErr.ErrorAliases()) > 0 && !apiErr.HasErrorAlias("object_locked") {
+				m.logRequest(req.Method, req.URL.Path, resp_.StatusCode, time.Since(start), resp_.Header.Get("X-Request-Id"), "", apiErr)
+				result.Duration = time.Since(start)
+				result.LockRetries = lockRetries
+				return result, apiErr
+			}
+
+			m.log("[bcc] Object '%s' locked. Try again in %s...", url, m.RequestInterval)
+			if lockSpan == nil {
+				_, lockSpan = m.startSpan("bcc.lock_wait", Attr("url.path", req.URL.Path))
 			}
+			lockRetries++
 
 			select {
 			case <-ctx.Done():
-				m.log("[request-err] Waiting unlock for '%s' took more than %ds", url, m.RequestTimeout.Seconds())
-				return "", ctx.Err()
+				m.log("[request-err] Waiting unlock for '%s' took more than %.0fs", url, m.RequestTimeout.Seconds())
+				m.logRequest(req.Method, req.URL.Path, resp_.StatusCode, time.Since(start), resp_.Header.Get("X-Request-Id"), "", apiErr)
+				result.Duration = time.Since(start)
+				result.LockRetries = lockRetries
+				if err := m.ctx.Err(); err != nil {
+					return result, err
+				}
+				return result, apiErr
 			case <-ticker.C:
 			}
 
@@ -432,54 +461,79 @@ func (m *Manager) do(req *http.Request, url string, target interface{}, requestB
 		resp = resp_
 		break
 	}
+	defer resp.Body.Close()
+
+	requestID := resp.Header.Get("X-Request-Id")
+	taskIds := resp.Header.Get("X-Esu-Tasks")
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+	if (resp.StatusCode < 200 || resp.StatusCode > 299) && resp.StatusCode != http.StatusNotModified {
 		m.log("[bcc] Error response %d on '%s'", resp.StatusCode, url)
-		return "", NewApiError(url, resp)
+		body, _ := io.ReadAll(resp.Body)
+		err := newApiError(url, resp.StatusCode, body)
+		m.logRequest(req.Method, req.URL.Path, resp.StatusCode, time.Since(start), requestID, taskIds, err)
+		return &CallResult{Status: resp.StatusCode, Header: resp.Header, Body: body, Duration: time.Since(start), LockRetries: lockRetries}, err
 	} else {
 		m.log("[bcc] Success response on '%s'", url)
 	}
 
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", errors.Wrapf(err, "HTTP Read error on response for %s", url)
+		return nil, errors.Wrapf(err, "HTTP Read error on response for %s", url)
 	}
+	m.logRequest(req.Method, req.URL.Path, resp.StatusCode, time.Since(start), requestID, taskIds, nil)
+	m.logBody("bcc response body", req.URL.Path, b)
 
-	// task waiter
-	taskIds := resp.Header.Get("X-Esu-Tasks")
-	if taskIds != "" {
-		m.log("[bcc] Tasks IDS: %s", taskIds)
-	}
+	return &CallResult{Status: resp.StatusCode, Header: resp.Header, Body: b, Duration: time.Since(start), LockRetries: lockRetries}, nil
+}
 
-	if len(b) == 0 {
-		return taskIds, nil
-	}
+// send performs req, repeating it while the RetryPolicy considers the
+// failure transient.
+func (m *Manager) send(req *http.Request, requestBody []byte) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		m.log("[bcc] Perform %s...", req.Method)
 
-	if target == nil {
-		// Don't try to unmarshall in case target is nil
-		return taskIds, nil
-	}
+		release, err := m.acquire()
+		if err != nil {
+			return nil, err
+		}
 
-	// if we dowload file
-	if strings.Contains(url, "config") {
-		reg_url := fmt.Sprintf("%s%s", m.BaseURL, KubeCtlConfigURL)
-		err = CreateKubeCtlConfigFile(b, url, reg_url)
+		traced, span := m.startSpan("bcc.http",
+			Attr("http.request.method", req.Method),
+			Attr("url.path", req.URL.Path),
+			Attr("bcc.attempt", attempt),
+		)
+
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+		resp, err := m.Client.Do(req.WithContext(traced.ctx))
 		if err != nil {
-			return "", errors.Wrapf(err, "Error while creating config file")
+			release()
+			endSpan(span, err)
+		} else {
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+			span.SetAttributes(Attr("http.response.status_code", resp.StatusCode))
+			span.End()
 		}
-	} else {
-		err = json.Unmarshal(b, target)
-		log.Printf("Unmarshalled response: %+v", target)
-		log.Printf("%s", b)
-		log.Printf("%s", string(b))
+
+		if !m.RetryPolicy.ShouldRetry(attempt, req, resp, err) {
+			return resp, err
+		}
+
+		delay := m.RetryPolicy.Backoff(attempt, resp)
 		if err != nil {
-			return "", errors.Wrapf(err, "JSON decode failed on %s:\n%s", url, string(b))
+			m.log("[bcc] %s %s failed: %s. Retry %d in %s...", req.Method, req.URL.Path, err, attempt, delay)
+		} else {
+			m.log("[bcc] %s %s returned %d. Retry %d in %s...", req.Method, req.URL.Path, resp.StatusCode, attempt, delay)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
 		}
-	}
 
-	return taskIds, nil
+		if err := m.sleep(delay); err != nil {
+			return nil, err
+		}
+	}
 }
 
+// Deprecated: use Kubernetes.GetKubeconfig and Kubernetes.WriteKubeconfig.
 func CreateKubeCtlConfigFile(b []byte, url string, reg_url string) (err error) {
 	yamlMap := make(map[interface{}]interface{})
 	err = yaml.Unmarshal(b, yamlMap)
@@ -504,21 +558,6 @@ func CreateKubeCtlConfigFile(b []byte, url string, reg_url string) (err error) {
 	return nil
 }
 
-func (m *Manager) waitTasks(taskIds string) error {
-	for _, taskId := range strings.Split(taskIds, ",") {
-		taskId := strings.TrimSpace(taskId)
-		if taskId == "" {
-			continue
-		}
-
-		if err := m.WaitTask(taskId); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func extractIDFromURL(url string, reg string) (string, error) {
 	re := regexp.MustCompile(reg)
 	matches := re.FindStringSubmatch(url)
@@ -0,0 +1,45 @@
+package bcc_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+// fixture seeds the fake server started by newServer.
+type fixture func(s *bcctest.Server)
+
+// object seeds obj under kind.
+func object(kind string, obj bcctest.Object) fixture {
+	return func(s *bcctest.Server) { s.Add(kind, obj) }
+}
+
+// mainVdc seeds vdc1, the vdc most tests place their resources in.
+var mainVdc = object("vdc", bcctest.Object{"id": "vdc1", "name": "main"})
+
+// vms seeds n vms in vdc, with the ids vm0, vm1... and the names web0,
+// web1...
+func vms(n int, vdc string) fixture {
+	return func(s *bcctest.Server) {
+		for i := 0; i < n; i++ {
+			s.Add("vm", bcctest.Object{"id": fmt.Sprintf("vm%d", i), "name": fmt.Sprintf("web%d", i), "vdc": vdc})
+		}
+	}
+}
+
+// newServer starts a fake API server seeded with fixtures, in order, and
+// returns it with a manager talking to it. The server is closed when the
+// test ends.
+func newServer(t *testing.T, fixtures ...fixture) (*bcctest.Server, *bcc.Manager) {
+	t.Helper()
+
+	s := bcctest.NewServer()
+	t.Cleanup(s.Close)
+	for _, seed := range fixtures {
+		seed(s)
+	}
+
+	return s, s.Manager()
+}
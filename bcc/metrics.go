@@ -0,0 +1,25 @@
+package bcc
+
+import "time"
+
+// Metrics receives the measurements of a Manager. Set Manager.Metrics to
+// collect them, the separate module github.com/basis-cloud/bcc-go/bcc/bccprom
+// provides one that is a prometheus.Collector.
+type Metrics interface {
+	// ObserveCall is called once for every call passing the middleware
+	// chain, including those answered by Manager.Cache. route is the path
+	// of the call with its ids replaced by {id}, e.g. v1/vm/{id}/state, to
+	// keep the number of label values bounded. result is nil when the API
+	// did not answer.
+	ObserveCall(method string, route string, result *CallResult, err error)
+	// ObserveJobWait is called when Job.Wait returns.
+	ObserveJobWait(job *Job, duration time.Duration, err error)
+}
+
+func (m *Manager) observeCall(call *Call, result *CallResult, err error) {
+	if m.Metrics == nil {
+		return
+	}
+
+	m.Metrics.ObserveCall(call.Method, parseOperation(call.Method, call.Path).Route, result, err)
+}
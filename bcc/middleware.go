@@ -0,0 +1,92 @@
+package bcc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Call is a single API call as it passes through the middleware chain.
+// Middleware may change any field before handing the call on.
+type Call struct {
+	Method string
+	// Path is relative to Manager.BaseURL, e.g. "v1/vm".
+	Path  string
+	Query url.Values
+	// Payload is the JSON request body, nil for GET and DELETE.
+	Payload []byte
+	// Header is sent along with the request. The Authorization header is
+	// filled in from Manager.Token unless a middleware set one.
+	Header http.Header
+}
+
+// CallResult is the outcome of a call. It is also returned next to the
+// error of a failed call when the API answered, so middleware can observe
+// the status.
+type CallResult struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Duration covers the whole call, including retries and lock waits.
+	Duration time.Duration
+	// LockRetries counts the 409 object_locked answers waited out.
+	LockRetries int
+	// Cached is set when Body was taken from Manager.Cache, either without
+	// a request, then Duration is zero, or after a 304 Not Modified.
+	Cached bool
+}
+
+// TaskIds returns the ids of the jobs started by the call.
+func (r *CallResult) TaskIds() []string {
+	var ids []string
+	for _, id := range strings.Split(r.Header.Get("X-Esu-Tasks"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// Doer performs a call.
+type Doer interface {
+	Do(ctx context.Context, call *Call) (*CallResult, error)
+}
+
+type DoerFunc func(ctx context.Context, call *Call) (*CallResult, error)
+
+func (f DoerFunc) Do(ctx context.Context, call *Call) (*CallResult, error) {
+	return f(ctx, call)
+}
+
+// Middleware wraps the Doer of the next stage.
+type Middleware func(next Doer) Doer
+
+// Use adds middleware to the chain wrapping every call made by Request,
+// Get, GetItems, GetSubItems and Delete. The middleware added first is the
+// outermost one. Managers derived with WithContext afterwards share the
+// chain.
+//
+//	m.Use(func(next bcc.Doer) bcc.Doer {
+//		return bcc.DoerFunc(func(ctx context.Context, call *bcc.Call) (*bcc.CallResult, error) {
+//			call.Header.Set("X-Tenant-Id", tenant)
+//			return next.Do(ctx, call)
+//		})
+//	})
+func (m *Manager) Use(middleware ...Middleware) {
+	m.middleware = append(m.middleware[:len(m.middleware):len(m.middleware)], middleware...)
+}
+
+func (m *Manager) chain() Doer {
+	var doer Doer = DoerFunc(m.roundTrip)
+	if m.Cache != nil && m.cacheResource != "" {
+		doer = m.cacheStage(doer)
+	}
+	for i := len(m.middleware) - 1; i >= 0; i-- {
+		doer = m.middleware[i](doer)
+	}
+
+	return doer
+}
@@ -1,8 +1,9 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"iter"
 	"net/url"
 
 	"github.com/pkg/errors"
@@ -30,35 +31,61 @@ func NewNetwork(name string) Network {
 }
 
 func (m *Manager) GetNetworks(extraArgs ...Arguments) (networks []*Network, err error) {
-	path := "v1/network"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetNetworksCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &networks); err != nil {
-		log.Printf("[REQUEST-ERROR]: getting networks was failed: %s]", err)
-	} else {
-		for i := range networks {
-			networks[i].manager = m
-		}
+func (m *Manager) GetNetworksCtx(ctx context.Context, extraArgs ...Arguments) (networks []*Network, err error) {
+	if networks, err = Collect(m.AllNetworksCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR]: getting networks was failed: %s]", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllNetworks(extraArgs ...ListOptions) iter.Seq2[*Network, error] {
+	return m.AllNetworksCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllNetworksCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Network, error] {
+	path := "v1/network"
+	return listItems(m.WithContext(ctx), path, typedQuery[NetworkListOptions](extraArgs), func(network *Network) {
+		network.manager = m
+	})
+}
+
 func (v *Vdc) GetNetworks(extraArgs ...Arguments) (networks []*Network, err error) {
+	return v.GetNetworksCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetNetworksCtx(ctx context.Context, extraArgs ...Arguments) (networks []*Network, err error) {
 	args := Arguments{
 		"vdc": v.ID,
 	}
 	args.merge(extraArgs)
-	networks, err = v.manager.GetNetworks(args)
+	networks, err = v.manager.GetNetworksCtx(ctx, args)
 	return
 }
 
+func (v *Vdc) AllNetworks(extraArgs ...ListOptions) iter.Seq2[*Network, error] {
+	return v.AllNetworksCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllNetworksCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Network, error] {
+	args := Arguments{
+		"vdc": v.ID,
+	}
+	return v.manager.AllNetworksCtx(ctx, args, listQuery(extraArgs))
+}
+
 func (m *Manager) GetNetwork(id string) (network *Network, err error) {
+	return m.GetNetworkCtx(m.ctx, id)
+}
+
+func (m *Manager) GetNetworkCtx(ctx context.Context, id string) (network *Network, err error) {
 	path := fmt.Sprintf("v1/network/%s", id)
 
-	if err = m.Get(path, Defaults(), &network); err != nil {
-		log.Printf("[REQUEST-ERROR]: getting network-%s was failed: %s]", id, err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &network); err != nil {
+		m.log("[REQUEST-ERROR]: getting network-%s was failed: %s]", id, err)
 	} else {
 		network.manager = m
 		for i := range network.Subnets {
@@ -71,6 +98,10 @@ func (m *Manager) GetNetwork(id string) (network *Network, err error) {
 }
 
 func (v *Vdc) CreateNetwork(network *Network) error {
+	return v.CreateNetworkCtx(v.manager.ctx, network)
+}
+
+func (v *Vdc) CreateNetworkCtx(ctx context.Context, network *Network) error {
 	path := "v1/network"
 	args := &struct {
 		Name string   `json:"name"`
@@ -84,8 +115,8 @@ func (v *Vdc) CreateNetwork(network *Network) error {
 		Tags: convertTagsToNames(network.Tags),
 	}
 
-	if err := v.manager.Request("POST", path, args, &network); err != nil {
-		log.Printf("[REQUEST-ERROR]: creating network-%s was failed: %s", network.Name, err)
+	if err := v.manager.WithContext(ctx).Request("POST", path, args, &network); err != nil {
+		v.manager.log("[REQUEST-ERROR]: creating network-%s was failed: %s", network.Name, err)
 	} else {
 		network.manager = v.manager
 	}
@@ -94,24 +125,38 @@ func (v *Vdc) CreateNetwork(network *Network) error {
 }
 
 func (n *Network) GetSubnets(extraArgs ...Arguments) (subnets []*Subnet, err error) {
-	args := Defaults()
-	args.merge(extraArgs)
-	path := fmt.Sprintf("v1/network/%s/subnet", n.ID)
-	if err = n.manager.GetItems(path, args, &subnets); err != nil {
+	return n.GetSubnetsCtx(n.manager.ctx, extraArgs...)
+}
+
+func (n *Network) GetSubnetsCtx(ctx context.Context, extraArgs ...Arguments) (subnets []*Subnet, err error) {
+	if subnets, err = Collect(n.AllSubnetsCtx(ctx, arguments(extraArgs))); err != nil {
 		return subnets, errors.Wrapf(err, "crash via getting subnets for network-%s", n.ID)
 	}
-	for i := range subnets {
-		subnets[i].manager = n.manager
-		subnets[i].network = n
-	}
 
 	return
 }
 
+func (n *Network) AllSubnets(extraArgs ...ListOptions) iter.Seq2[*Subnet, error] {
+	return n.AllSubnetsCtx(n.manager.ctx, extraArgs...)
+}
+
+func (n *Network) AllSubnetsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Subnet, error] {
+	path := fmt.Sprintf("v1/network/%s/subnet", n.ID)
+
+	return listItems(n.manager.WithContext(ctx), path, typedQuery[SubnetListOptions](extraArgs), func(subnet *Subnet) {
+		subnet.manager = n.manager
+		subnet.network = n
+	})
+}
+
 func (n *Network) CreateSubnet(subnet *Subnet) (err error) {
+	return n.CreateSubnetCtx(n.manager.ctx, subnet)
+}
+
+func (n *Network) CreateSubnetCtx(ctx context.Context, subnet *Subnet) (err error) {
 	path := fmt.Sprintf("v1/network/%s/subnet", n.ID)
 
-	if err = n.manager.Request("POST", path, subnet, &subnet); err != nil {
+	if err = n.manager.WithContext(ctx).Request("POST", path, subnet, &subnet); err != nil {
 		return errors.Wrapf(err, "crash via creating subnet for network-%s", n.ID)
 	} else {
 		subnet.manager = n.manager
@@ -122,11 +167,19 @@ func (n *Network) CreateSubnet(subnet *Subnet) (err error) {
 }
 
 func (n *Network) Rename(name string) error {
+	return n.RenameCtx(n.manager.ctx, name)
+}
+
+func (n *Network) RenameCtx(ctx context.Context, name string) error {
 	n.Name = name
-	return n.Update()
+	return n.UpdateCtx(ctx)
 }
 
 func (n *Network) Update() (err error) {
+	return n.UpdateCtx(n.manager.ctx)
+}
+
+func (n *Network) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/network", n.ID)
 	args := &struct {
 		Name string   `json:"name"`
@@ -138,21 +191,29 @@ func (n *Network) Update() (err error) {
 		Tags: convertTagsToNames(n.Tags),
 	}
 
-	if err := n.manager.Request("PUT", path, args, n); err != nil {
-		log.Printf("[REQUEST-ERROR]: updating network-%s was failed: %s", n.Name, err)
+	if err := n.manager.WithContext(ctx).Request("PUT", path, args, n); err != nil {
+		n.manager.log("[REQUEST-ERROR]: updating network-%s was failed: %s", n.Name, err)
 	}
 
 	return
 }
 
 func (n *Network) Delete() error {
+	return n.DeleteCtx(n.manager.ctx)
+}
+
+func (n *Network) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/network", n.ID)
-	return n.manager.Delete(path, Defaults(), nil)
+	return n.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (n Network) WaitLock() error {
+	return n.WaitLockCtx(n.manager.ctx)
+}
+
+func (n Network) WaitLockCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/network", n.ID)
-	if err := loopWaitLock(n.manager, path); err != nil {
+	if err := loopWaitLock(n.manager.WithContext(ctx), path); err != nil {
 		return errors.Wrapf(err, "crash via WaitLock for Network")
 	} else {
 		return nil
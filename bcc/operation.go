@@ -0,0 +1,75 @@
+package bcc
+
+import "strings"
+
+// operation names an API call independently of the ids in its path, so
+// calls can be grouped in traces and metrics.
+type operation struct {
+	// Name is "bcc." followed by the resource names in the path and a verb:
+	// POST v1/vm is bcc.vm.create, GET v1/vm is bcc.vm.list, GET, PUT and
+	// DELETE v1/vm/<id> are bcc.vm.get, bcc.vm.update and bcc.vm.delete,
+	// and an action like POST v1/vm/<id>/state is bcc.vm.state.
+	Name string
+	// Route is the path with every id replaced by {id}, e.g.
+	// v1/kubernetes/{id}/node_group/{id}.
+	Route string
+	// Resource is the innermost resource of the path, "vm" above.
+	Resource string
+	// IDs holds the ids found in the path keyed by resource.
+	IDs map[string]string
+}
+
+// parseOperation relies on the layout of the API paths, where resource
+// names and ids alternate: v1/<resource>/<id>/<resource or action>/<id>.
+func parseOperation(method string, path string) operation {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 0 && segments[0] == "v1" {
+		segments = segments[1:]
+	}
+
+	op := operation{IDs: map[string]string{}}
+	route := []string{"v1"}
+	var names []string
+	lastIsID := false
+
+	for i, segment := range segments {
+		if i%2 == 1 {
+			op.IDs[op.Resource] = segment
+			route = append(route, "{id}")
+			lastIsID = true
+			continue
+		}
+
+		op.Resource = segment
+		names = append(names, segment)
+		route = append(route, segment)
+		lastIsID = false
+	}
+
+	op.Route = strings.Join(route, "/")
+
+	verb := ""
+	switch {
+	case method == "GET" && lastIsID:
+		verb = "get"
+	case method == "GET":
+		verb = "list"
+	case method == "PUT" || method == "PATCH":
+		verb = "update"
+	case method == "DELETE":
+		verb = "delete"
+	case method == "POST" && len(names) == 1 && !lastIsID:
+		verb = "create"
+	case method == "POST" && !lastIsID:
+		// an action, already named by the last segment
+	default:
+		verb = strings.ToLower(method)
+	}
+
+	if verb != "" {
+		names = append(names, verb)
+	}
+	op.Name = "bcc." + strings.Join(names, ".")
+
+	return op
+}
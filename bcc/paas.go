@@ -1,7 +1,8 @@
 package bcc
 
 import (
-	"log"
+	"context"
+	"iter"
 	"net/url"
 )
 
@@ -48,6 +49,10 @@ type PaasService struct {
 }
 
 func (m *Manager) CreatePaasLocation(vdcId string) (err error) {
+	return m.CreatePaasLocationCtx(m.ctx, vdcId)
+}
+
+func (m *Manager) CreatePaasLocationCtx(ctx context.Context, vdcId string) (err error) {
 	path := "v1/paas"
 	args := struct {
 		Vdc string `json:"vdc"`
@@ -55,35 +60,48 @@ func (m *Manager) CreatePaasLocation(vdcId string) (err error) {
 		Vdc: vdcId,
 	}
 
-	if err = m.Request("POST", path, args, nil); err != nil {
-		log.Printf("[REQUEST-ERROR]: creating paas location was failed: %s", err)
+	if err = m.WithContext(ctx).Request("POST", path, args, nil); err != nil {
+		m.log("[REQUEST-ERROR]: creating paas location was failed: %s", err)
 	}
 
 	return
 }
 
 func (m *Manager) GetPaasTemplates(vdcId string, extraArgs ...Arguments) (templates []*PaasTemplate, err error) {
-	path := "v1/paas_template"
-	args := Arguments{"vdc_id": vdcId}
-	args.merge(extraArgs)
+	return m.GetPaasTemplatesCtx(m.ctx, vdcId, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &templates); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-paas-templates was failed: %s", err)
-	} else {
-		for i := range templates {
-			templates[i].manager = m
-		}
+func (m *Manager) GetPaasTemplatesCtx(ctx context.Context, vdcId string, extraArgs ...Arguments) (templates []*PaasTemplate, err error) {
+	if templates, err = Collect(m.AllPaasTemplatesCtx(ctx, vdcId, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR]: get-paas-templates was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllPaasTemplates(vdcId string, extraArgs ...ListOptions) iter.Seq2[*PaasTemplate, error] {
+	return m.AllPaasTemplatesCtx(m.ctx, vdcId, extraArgs...)
+}
+
+func (m *Manager) AllPaasTemplatesCtx(ctx context.Context, vdcId string, extraArgs ...ListOptions) iter.Seq2[*PaasTemplate, error] {
+	path := "v1/paas_template"
+	args := Arguments{"vdc_id": vdcId}
+
+	return listItems(m.WithContext(ctx), path, typedQuery[PaasTemplateListOptions]{args, listQuery(extraArgs)}, func(template *PaasTemplate) {
+		template.manager = m
+	})
+}
+
 func (m *Manager) GetPaasTemplate(id string, vdcId string) (template *PaasTemplate, err error) {
+	return m.GetPaasTemplateCtx(m.ctx, id, vdcId)
+}
+
+func (m *Manager) GetPaasTemplateCtx(ctx context.Context, id string, vdcId string) (template *PaasTemplate, err error) {
 	path, _ := url.JoinPath("v1/paas_template", id)
 	args := Arguments{"vdc_id": vdcId}
 
-	if err = m.Get(path, args, &template); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-paas-template was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, args, &template); err != nil {
+		m.log("[REQUEST-ERROR]: get-paas-template was failed: %s", err)
 	} else {
 		template.manager = m
 	}
@@ -92,6 +110,10 @@ func (m *Manager) GetPaasTemplate(id string, vdcId string) (template *PaasTempla
 }
 
 func (p *PaasTemplate) GetPaasTemplateInputs(projectId string, extraArgs ...Arguments) ([]*PaasInputDescription, error) {
+	return p.GetPaasTemplateInputsCtx(p.manager.ctx, projectId, extraArgs...)
+}
+
+func (p *PaasTemplate) GetPaasTemplateInputsCtx(ctx context.Context, projectId string, extraArgs ...Arguments) ([]*PaasInputDescription, error) {
 	path, _ := url.JoinPath("v1/paas_template", p.ID, "inputs")
 	response := struct {
 		Inputs []*PaasInputDescription `json:"inputs"`
@@ -99,32 +121,46 @@ func (p *PaasTemplate) GetPaasTemplateInputs(projectId string, extraArgs ...Argu
 	args := Arguments{"project_id": projectId}
 	args.merge(extraArgs)
 
-	if err := p.manager.Request("GET", path, args, &response); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-paas-template-inputs was failed: %s", err)
+	if err := p.manager.WithContext(ctx).Request("GET", path, args, &response); err != nil {
+		p.manager.log("[REQUEST-ERROR]: get-paas-template-inputs was failed: %s", err)
 	}
 
 	return response.Inputs, nil
 }
 
 func (m *Manager) GetPaasServices(args Arguments) (services []*PaasService, err error) {
-	path := "v1/paas_service"
+	return m.GetPaasServicesCtx(m.ctx, args)
+}
 
-	if err = m.GetItems(path, args, &services); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-paas-services was failed: %s", err)
-	} else {
-		for i := range services {
-			services[i].manager = m
-		}
+func (m *Manager) GetPaasServicesCtx(ctx context.Context, args Arguments) (services []*PaasService, err error) {
+	if services, err = Collect(m.AllPaasServicesCtx(ctx, args)); err != nil {
+		m.log("[REQUEST-ERROR]: get-paas-services was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllPaasServices(args Arguments) iter.Seq2[*PaasService, error] {
+	return m.AllPaasServicesCtx(m.ctx, args)
+}
+
+func (m *Manager) AllPaasServicesCtx(ctx context.Context, args Arguments) iter.Seq2[*PaasService, error] {
+	path := "v1/paas_service"
+
+	return listItems(m.WithContext(ctx), path, args, func(service *PaasService) {
+		service.manager = m
+	})
+}
+
 func (m *Manager) GetPaasService(id string) (service *PaasService, err error) {
+	return m.GetPaasServiceCtx(m.ctx, id)
+}
+
+func (m *Manager) GetPaasServiceCtx(ctx context.Context, id string) (service *PaasService, err error) {
 	path, _ := url.JoinPath("v1/paas_service", id)
 
-	if err := m.Get(path, Defaults(), &service); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-paas-service was failed: %s", err)
+	if err := m.WithContext(ctx).Get(path, Defaults(), &service); err != nil {
+		m.log("[REQUEST-ERROR]: get-paas-service was failed: %s", err)
 	} else {
 		service.manager = m
 	}
@@ -133,6 +169,10 @@ func (m *Manager) GetPaasService(id string) (service *PaasService, err error) {
 }
 
 func (m *Manager) CreatePaasService(p *PaasService) error {
+	return m.CreatePaasServiceCtx(m.ctx, p)
+}
+
+func (m *Manager) CreatePaasServiceCtx(ctx context.Context, p *PaasService) error {
 	path := "v1/paas_service"
 	args := struct {
 		Name          string                 `json:"name"`
@@ -146,8 +186,8 @@ func (m *Manager) CreatePaasService(p *PaasService) error {
 		Inputs:        p.Inputs,
 	}
 
-	if err := m.Request("POST", path, args, &p); err != nil {
-		log.Printf("[REQUEST-ERROR]: creating paas service was failed: %s", err)
+	if err := m.WithContext(ctx).Request("POST", path, args, &p); err != nil {
+		m.log("[REQUEST-ERROR]: creating paas service was failed: %s", err)
 	} else {
 		p.manager = m
 	}
@@ -156,6 +196,10 @@ func (m *Manager) CreatePaasService(p *PaasService) error {
 }
 
 func (p *PaasService) Update() (err error) {
+	return p.UpdateCtx(p.manager.ctx)
+}
+
+func (p *PaasService) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/paas_service", p.ID)
 	args := struct {
 		Name   string                 `json:"name"`
@@ -165,19 +209,27 @@ func (p *PaasService) Update() (err error) {
 		Inputs: p.Inputs,
 	}
 
-	if err = p.manager.Request("PUT", path, args, p); err != nil {
-		log.Printf("[REQUEST-ERROR]: updating paas service was failed: %s", err)
+	if err = p.manager.WithContext(ctx).Request("PUT", path, args, p); err != nil {
+		p.manager.log("[REQUEST-ERROR]: updating paas service was failed: %s", err)
 	}
 
 	return
 }
 
 func (m *Manager) DeletePaasService(id string) error {
+	return m.DeletePaasServiceCtx(m.ctx, id)
+}
+
+func (m *Manager) DeletePaasServiceCtx(ctx context.Context, id string) error {
 	path, _ := url.JoinPath("v1/paas_service", id)
-	return m.Delete(path, Defaults(), nil)
+	return m.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (p PaasService) WaitLock() (err error) {
+	return p.WaitLockCtx(p.manager.ctx)
+}
+
+func (p PaasService) WaitLockCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/paas_service", p.ID)
-	return loopWaitLock(p.manager, path)
+	return loopWaitLock(p.manager.WithContext(ctx), path)
 }
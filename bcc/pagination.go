@@ -0,0 +1,153 @@
+package bcc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// itemsPage is one page of a list in the {total, limit, items} envelope
+// used by every list endpoint.
+type itemsPage struct {
+	Total int               `json:"total"`
+	Limit int               `json:"limit"`
+	Items []json.RawMessage `json:"items"`
+}
+
+// getPage fetches a single page of the list at path.
+func (m *Manager) getPage(path string, query url.Values, page int) (*itemsPage, error) {
+	params := url.Values{}
+	for key, values := range query {
+		params[key] = values
+	}
+	params.Set("page", fmt.Sprint(page))
+	if !params.Has("limit") && m.PageSize > 0 {
+		params.Set("limit", fmt.Sprint(m.PageSize))
+	}
+
+	p := new(itemsPage)
+	if err := m.get(path, params, p); err != nil {
+		return nil, errors.Wrapf(err, "Fetching page %d of %s failed", page, path)
+	}
+
+	return p, nil
+}
+
+// pages yields the pages of the list at path in order. The page size is taken
+// from opts or Manager.PageSize. With Manager.PagePrefetch above zero up to
+// that many of the following pages are fetched concurrently while the caller
+// consumes the current one. Iteration ends at the first error, which is
+// yielded.
+func (m *Manager) pages(path string, opts ListOptions) iter.Seq2[*itemsPage, error] {
+	return func(yield func(*itemsPage, error) bool) {
+		query, err := opts.Values()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		first, err := m.getPage(path, query, 1)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if !yield(first, nil) || len(first.Items) == 0 || first.Limit <= 0 {
+			return
+		}
+
+		last := (first.Total + first.Limit - 1) / first.Limit
+		if m.PagePrefetch <= 0 {
+			for page := 2; page <= last; page++ {
+				p, err := m.getPage(path, query, page)
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(p, nil) || len(p.Items) == 0 {
+					return
+				}
+			}
+			return
+		}
+
+		// Pages still being fetched are abandoned through ctx once the caller
+		// stops iterating.
+		ctx, cancel := context.WithCancel(m.logContext())
+		defer cancel()
+		fm := m.WithContext(ctx)
+
+		type result struct {
+			page *itemsPage
+			err  error
+		}
+		var pending []chan result
+		next := 2
+		for {
+			for len(pending) < m.PagePrefetch && next <= last {
+				ch := make(chan result, 1)
+				go func(page int) {
+					p, err := fm.getPage(path, query, page)
+					ch <- result{p, err}
+				}(next)
+				pending = append(pending, ch)
+				next++
+			}
+			if len(pending) == 0 {
+				return
+			}
+
+			r := <-pending[0]
+			pending = pending[1:]
+			if r.err != nil {
+				yield(nil, r.err)
+				return
+			}
+			if !yield(r.page, nil) || len(r.page.Items) == 0 {
+				return
+			}
+		}
+	}
+}
+
+// listItems streams the items of the list at path. bind is called on every
+// item before it is yielded, typically to attach the manager to it.
+func listItems[T any](m *Manager, path string, opts ListOptions, bind func(*T)) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		for page, err := range m.pages(path, opts) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, raw := range page.Items {
+				item := new(T)
+				if err := json.Unmarshal(raw, item); err != nil {
+					yield(nil, errors.Wrapf(err, "JSON item decode failed on %s", path))
+					return
+				}
+				bind(item)
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Collect drains seq into a slice, typically one of the All... list methods
+// given typed ListOptions. On error the items read so far are returned along
+// with it.
+func Collect[T any](seq iter.Seq2[T, error]) (items []T, err error) {
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,165 @@
+package bcc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+func listRequests(s *bcctest.Server, path string) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if r.Method == http.MethodGet && r.Path == path {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestAllVmsPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		vms       int
+		pageSize  int
+		prefetch  int
+		wantPages int
+	}{
+		{name: "empty", vms: 0, pageSize: 2, wantPages: 1},
+		{name: "single page", vms: 2, pageSize: 5, wantPages: 1},
+		{name: "partial last page", vms: 5, pageSize: 2, wantPages: 3},
+		{name: "exact pages", vms: 4, pageSize: 2, wantPages: 2},
+		{name: "prefetch", vms: 7, pageSize: 2, prefetch: 2, wantPages: 4},
+		{name: "prefetch beyond last page", vms: 7, pageSize: 3, prefetch: 8, wantPages: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newServer(t, mainVdc, vms(tt.vms, "vdc1"))
+			m.PageSize = tt.pageSize
+			m.PagePrefetch = tt.prefetch
+
+			var got []string
+			for vm, err := range m.AllVms() {
+				if err != nil {
+					t.Fatalf("AllVms: %s", err)
+				}
+				got = append(got, vm.ID)
+			}
+
+			if len(got) != tt.vms {
+				t.Fatalf("got %d vms, want %d", len(got), tt.vms)
+			}
+			for i, id := range got {
+				if want := fmt.Sprintf("vm%d", i); id != want {
+					t.Errorf("vm %d = %s, want %s", i, id, want)
+				}
+			}
+			if pages := listRequests(s, "/v1/vm"); pages != tt.wantPages {
+				t.Errorf("fetched %d pages, want %d", pages, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestAllVmsEarlyStop(t *testing.T) {
+	s, m := newServer(t, mainVdc, vms(10, "vdc1"))
+	m.PageSize = 2
+
+	seen := 0
+	for _, err := range m.AllVms() {
+		if err != nil {
+			t.Fatalf("AllVms: %s", err)
+		}
+		seen++
+		if seen == 3 {
+			break
+		}
+	}
+
+	if pages := listRequests(s, "/v1/vm"); pages != 2 {
+		t.Errorf("fetched %d pages after stopping on the 3rd of 10 vms, want 2", pages)
+	}
+}
+
+// failPage makes the server fail the request following the one for page.
+func failPage(s *bcctest.Server, page string) bcc.Middleware {
+	return func(next bcc.Doer) bcc.Doer {
+		return bcc.DoerFunc(func(ctx context.Context, call *bcc.Call) (*bcc.CallResult, error) {
+			if call.Query.Get("page") == page {
+				s.FailNext(1, http.StatusInternalServerError)
+			}
+			return next.Do(ctx, call)
+		})
+	}
+}
+
+func TestListErrorMidway(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefetch int
+		list     func(m *bcc.Manager) (int, error)
+	}{
+		{
+			name: "GetVms",
+			list: func(m *bcc.Manager) (int, error) {
+				vms, err := m.GetVms()
+				return len(vms), err
+			},
+		},
+		{
+			name:     "GetVms with prefetch",
+			prefetch: 2,
+			list: func(m *bcc.Manager) (int, error) {
+				vms, err := m.GetVms()
+				return len(vms), err
+			},
+		},
+		{
+			name: "GetItems",
+			list: func(m *bcc.Manager) (int, error) {
+				var vms []bcc.Vm
+				err := m.GetItems("v1/vm", bcc.Arguments{}, &vms)
+				return len(vms), err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newServer(t, mainVdc, vms(6, "vdc1"))
+			m.PageSize = 2
+			m.PagePrefetch = tt.prefetch
+			m.Use(failPage(s, "2"))
+
+			n, err := tt.list(m)
+			var apiErr *bcc.ApiError
+			if !errors.As(err, &apiErr) || apiErr.Code() != http.StatusInternalServerError {
+				t.Fatalf("error = %v, want the 500 of a later page", err)
+			}
+			if n >= 6 {
+				t.Errorf("got all %d vms despite the failed page", n)
+			}
+		})
+	}
+}
+
+func TestListOptionsPageSize(t *testing.T) {
+	s, m := newServer(t, mainVdc, vms(5, "vdc1"))
+
+	vms, err := bcc.Collect(m.AllVms(bcc.VmListOptions{PageSize: 2}))
+	if err != nil {
+		t.Fatalf("AllVms: %s", err)
+	}
+	if len(vms) != 5 {
+		t.Errorf("got %d vms, want 5", len(vms))
+	}
+	if pages := listRequests(s, "/v1/vm"); pages != 3 {
+		t.Errorf("fetched %d pages, want 3", pages)
+	}
+}
@@ -1,7 +1,7 @@
 package bcc
 
 import (
-	"log"
+	"context"
 	"net/url"
 )
 
@@ -13,14 +13,18 @@ type Platform struct {
 }
 
 func (m *Manager) GetPlatforms(vdc_id string, extraArgs ...Arguments) (platforms []*Platform, err error) {
+	return m.GetPlatformsCtx(m.ctx, vdc_id, extraArgs...)
+}
+
+func (m *Manager) GetPlatformsCtx(ctx context.Context, vdc_id string, extraArgs ...Arguments) (platforms []*Platform, err error) {
 	path := "v1/platform"
 	args := Arguments{
 		"vdc": vdc_id,
 	}
 	args.merge(extraArgs)
 
-	if err = m.Get(path, args, &platforms); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-platforms was failed: %s", err)
+	if err = m.WithContext(ctx).cached(CachePlatforms).Get(path, args, &platforms); err != nil {
+		m.log("[REQUEST-ERROR]: get-platforms was failed: %s", err)
 	} else {
 		for i := range platforms {
 			platforms[i].manager = m
@@ -31,10 +35,14 @@ func (m *Manager) GetPlatforms(vdc_id string, extraArgs ...Arguments) (platforms
 }
 
 func (m *Manager) GetPlatform(id string) (platforms *Platform, err error) {
+	return m.GetPlatformCtx(m.ctx, id)
+}
+
+func (m *Manager) GetPlatformCtx(ctx context.Context, id string) (platforms *Platform, err error) {
 	path, _ := url.JoinPath("v1/platform", id)
 
-	if err = m.Get(path, Defaults(), &platforms); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-platform was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &platforms); err != nil {
+		m.log("[REQUEST-ERROR]: get-platform was failed: %s", err)
 	} else {
 		platforms.manager = m
 	}
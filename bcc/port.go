@@ -1,8 +1,9 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"iter"
 	"net/url"
 
 	"github.com/pkg/errors"
@@ -33,29 +34,42 @@ func NewPort(network *Network, firewallTemplates []*FirewallTemplate, ipAddress
 }
 
 func (v *Vdc) GetPorts(extraArgs ...Arguments) (ports []*Port, err error) {
+	return v.GetPortsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetPortsCtx(ctx context.Context, extraArgs ...Arguments) (ports []*Port, err error) {
+	if ports, err = Collect(v.AllPortsCtx(ctx, arguments(extraArgs))); err != nil {
+		v.manager.log("[REQUEST-ERROR]: get-ports was failed: %s", err)
+	}
+
+	return
+}
+
+func (v *Vdc) AllPorts(extraArgs ...ListOptions) iter.Seq2[*Port, error] {
+	return v.AllPortsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllPortsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Port, error] {
 	path := "v1/port"
 	args := Arguments{
 		"vdc": v.ID,
 	}
-	args.merge(extraArgs)
-
-	if err = v.manager.GetItems(path, args, &ports); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-ports was failed: %s", err)
-	} else {
-		for i := range ports {
-			ports[i].manager = v.manager
-			ports[i].Network.manager = v.manager
-		}
-	}
 
-	return
+	return listItems(v.manager.WithContext(ctx), path, typedQuery[PortListOptions]{args, listQuery(extraArgs)}, func(port *Port) {
+		port.manager = v.manager
+		port.Network.manager = v.manager
+	})
 }
 
 func (m *Manager) GetPort(id string) (port *Port, err error) {
+	return m.GetPortCtx(m.ctx, id)
+}
+
+func (m *Manager) GetPortCtx(ctx context.Context, id string) (port *Port, err error) {
 	path, _ := url.JoinPath("v1/port", id)
 
-	if err = m.Get(path, Defaults(), &port); err != nil {
-		log.Printf("[REQUEST-ERROR]: getting port-%s was failed: %s]", id, errors.WithStack(err))
+	if err = m.WithContext(ctx).Get(path, Defaults(), &port); err != nil {
+		m.log("[REQUEST-ERROR]: getting port-%s was failed: %s]", id, errors.WithStack(err))
 	} else {
 		port.manager = m
 	}
@@ -64,6 +78,10 @@ func (m *Manager) GetPort(id string) (port *Port, err error) {
 }
 
 func (r *Router) CreatePort(port *Port, toConnect interface{}) (err error) {
+	return r.CreatePortCtx(r.manager.ctx, port, toConnect)
+}
+
+func (r *Router) CreatePortCtx(ctx context.Context, port *Port, toConnect interface{}) (err error) {
 	path := "v1/port"
 	args := &struct {
 		manager           *Manager
@@ -99,14 +117,18 @@ func (r *Router) CreatePort(port *Port, toConnect interface{}) (err error) {
 		return fmt.Errorf("ERROR. Unknown type: %s", v)
 	}
 
-	if err = r.manager.Request("POST", path, args, &port); err != nil {
-		log.Printf("[REQUEST-ERROR]: creating port-%s was failed: %s", port.ID, err)
+	if err = r.manager.WithContext(ctx).Request("POST", path, args, &port); err != nil {
+		r.manager.log("[REQUEST-ERROR]: creating port-%s was failed: %s", port.ID, err)
 	}
 
 	return
 }
 
 func (v *Vdc) CreateEmptyPort(port *Port) (err error) {
+	return v.CreateEmptyPortCtx(v.manager.ctx, port)
+}
+
+func (v *Vdc) CreateEmptyPortCtx(ctx context.Context, port *Port) (err error) {
 	path := "v1/port"
 	var fwTemplates = make([]*string, 0)
 	for _, fwTemplate := range port.FirewallTemplates {
@@ -136,8 +158,8 @@ func (v *Vdc) CreateEmptyPort(port *Port) (err error) {
 		args.Vdc = &port.Vdc.ID
 	}
 
-	if err = v.manager.Request("POST", path, args, &port); err != nil {
-		log.Printf("[REQUEST-ERROR]: creating port-%s was failed: %s", port.ID, err)
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, &port); err != nil {
+		v.manager.log("[REQUEST-ERROR]: creating port-%s was failed: %s", port.ID, err)
 	} else {
 		port.manager = v.manager
 	}
@@ -146,16 +168,28 @@ func (v *Vdc) CreateEmptyPort(port *Port) (err error) {
 }
 
 func (p *Port) UpdateFirewall(firewallTemplates []*FirewallTemplate) error {
+	return p.UpdateFirewallCtx(p.manager.ctx, firewallTemplates)
+}
+
+func (p *Port) UpdateFirewallCtx(ctx context.Context, firewallTemplates []*FirewallTemplate) error {
 	p.FirewallTemplates = firewallTemplates
-	return p.Update()
+	return p.UpdateCtx(ctx)
 }
 
 func (p *Port) UpdateIpAddress(ip_address *string) error {
+	return p.UpdateIpAddressCtx(p.manager.ctx, ip_address)
+}
+
+func (p *Port) UpdateIpAddressCtx(ctx context.Context, ip_address *string) error {
 	p.IpAddress = ip_address
-	return p.Update()
+	return p.UpdateCtx(ctx)
 }
 
 func (p *Port) Update() (err error) {
+	return p.UpdateCtx(p.manager.ctx)
+}
+
+func (p *Port) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/port", p.ID)
 	fwTemplates := make([]*string, 0)
 	for _, fwTemplate := range p.FirewallTemplates {
@@ -173,28 +207,40 @@ func (p *Port) Update() (err error) {
 		Tags:          convertTagsToNames(p.Tags),
 	}
 
-	if err = p.manager.Request("PUT", path, args, p); err != nil {
-		log.Printf("[REQUEST-ERROR]: updating port-%s was failed: %s", p.ID, err)
+	if err = p.manager.WithContext(ctx).Request("PUT", path, args, p); err != nil {
+		p.manager.log("[REQUEST-ERROR]: updating port-%s was failed: %s", p.ID, err)
 	}
 
 	return
 }
 
 func (p *Port) Delete() error {
+	return p.DeleteCtx(p.manager.ctx)
+}
+
+func (p *Port) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/port", p.ID)
-	return p.manager.Delete(path, Defaults(), nil)
+	return p.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (p *Port) ForceDelete() error {
+	return p.ForceDeleteCtx(p.manager.ctx)
+}
+
+func (p *Port) ForceDeleteCtx(ctx context.Context) error {
 	path := fmt.Sprintf("v1/port/%s/force", p.ID)
-	return p.manager.Delete(path, Defaults(), nil)
+	return p.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (p Port) WaitLock() (err error) {
+	return p.WaitLockCtx(p.manager.ctx)
+}
+
+func (p Port) WaitLockCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/port", p.ID)
 
-	if err = loopWaitLock(p.manager, path); err != nil {
-		log.Printf("[REQUEST-ERROR]: wait-lock for port-%s was failed: %s", p.ID, err)
+	if err = loopWaitLock(p.manager.WithContext(ctx), path); err != nil {
+		p.manager.log("[REQUEST-ERROR]: wait-lock for port-%s was failed: %s", p.ID, err)
 	}
 
 	return
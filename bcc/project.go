@@ -1,7 +1,8 @@
 package bcc
 
 import (
-	"log"
+	"context"
+	"iter"
 	"net/url"
 )
 
@@ -22,26 +23,37 @@ func NewProject(name string) Project {
 }
 
 func (m *Manager) GetProjects(extraArgs ...Arguments) (projects []*Project, err error) {
-	path := "v1/project"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetProjectsCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &projects); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-projects was failed: %s", err)
-	} else {
-		for i := range projects {
-			projects[i].manager = m
-		}
+func (m *Manager) GetProjectsCtx(ctx context.Context, extraArgs ...Arguments) (projects []*Project, err error) {
+	if projects, err = Collect(m.AllProjectsCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR]: get-projects was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllProjects(extraArgs ...ListOptions) iter.Seq2[*Project, error] {
+	return m.AllProjectsCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllProjectsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Project, error] {
+	path := "v1/project"
+	return listItems(m.WithContext(ctx), path, typedQuery[ProjectListOptions](extraArgs), func(project *Project) {
+		project.manager = m
+	})
+}
+
 func (m *Manager) GetProject(id string) (project *Project, err error) {
+	return m.GetProjectCtx(m.ctx, id)
+}
+
+func (m *Manager) GetProjectCtx(ctx context.Context, id string) (project *Project, err error) {
 	path, _ := url.JoinPath("v1/project", id)
 
-	if err = m.Get(path, Defaults(), &project); err != nil {
-		log.Printf("[REQUEST-ERROR]: getting project-%s was failed: %s]", id, err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &project); err != nil {
+		m.log("[REQUEST-ERROR]: getting project-%s was failed: %s]", id, err)
 	} else {
 		project.manager = m
 	}
@@ -50,6 +62,10 @@ func (m *Manager) GetProject(id string) (project *Project, err error) {
 }
 
 func (c *Client) CreateProject(project *Project) (err error) {
+	return c.CreateProjectCtx(c.manager.ctx, project)
+}
+
+func (c *Client) CreateProjectCtx(ctx context.Context, project *Project) (err error) {
 	path := "v1/project"
 	args := &struct {
 		Name   string   `json:"name"`
@@ -61,8 +77,8 @@ func (c *Client) CreateProject(project *Project) (err error) {
 		Tags:   convertTagsToNames(project.Tags),
 	}
 
-	if err := c.manager.Request("POST", path, args, &project); err != nil {
-		log.Printf("[REQUEST-ERROR]: creating project-%s was failed: %s", project.Name, err)
+	if err := c.manager.WithContext(ctx).Request("POST", path, args, &project); err != nil {
+		c.manager.log("[REQUEST-ERROR]: creating project-%s was failed: %s", project.Name, err)
 	} else {
 		project.manager = c.manager
 	}
@@ -71,11 +87,19 @@ func (c *Client) CreateProject(project *Project) (err error) {
 }
 
 func (p *Project) Rename(name string) error {
+	return p.RenameCtx(p.manager.ctx, name)
+}
+
+func (p *Project) RenameCtx(ctx context.Context, name string) error {
 	p.Name = name
-	return p.Update()
+	return p.UpdateCtx(ctx)
 }
 
 func (p *Project) Update() (err error) {
+	return p.UpdateCtx(p.manager.ctx)
+}
+
+func (p *Project) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/project", p.ID)
 	args := &struct {
 		Name   string   `json:"name"`
@@ -87,19 +111,27 @@ func (p *Project) Update() (err error) {
 		Tags:   convertTagsToNames(p.Tags),
 	}
 
-	if err = p.manager.Request("PUT", path, args, p); err != nil {
-		log.Printf("[REQUEST-ERROR]: updating project-%s was failed: %s", p.Name, err)
+	if err = p.manager.WithContext(ctx).Request("PUT", path, args, p); err != nil {
+		p.manager.log("[REQUEST-ERROR]: updating project-%s was failed: %s", p.Name, err)
 	}
 
 	return
 }
 
 func (p *Project) Delete() error {
+	return p.DeleteCtx(p.manager.ctx)
+}
+
+func (p *Project) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/project", p.ID)
-	return p.manager.Delete(path, Defaults(), nil)
+	return p.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (p Project) WaitLock() (err error) {
+	return p.WaitLockCtx(p.manager.ctx)
+}
+
+func (p Project) WaitLockCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/project", p.ID)
-	return loopWaitLock(p.manager, path)
+	return loopWaitLock(p.manager.WithContext(ctx), path)
 }
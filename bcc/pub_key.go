@@ -1,8 +1,9 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"iter"
 )
 
 type PubKey struct {
@@ -14,34 +15,60 @@ type PubKey struct {
 }
 
 func (m *Manager) GetPublicKeys(accountId string) (publicKeys []*PubKey, err error) {
-	path := fmt.Sprintf("/v1/account/%s/key", accountId)
+	return m.GetPublicKeysCtx(m.ctx, accountId)
+}
 
-	if err = m.GetItems(path, Defaults(), &publicKeys); err != nil {
-		log.Printf("[REQUEST-ERROR] get-public-keys was failed: %s", err)
-	} else {
-		for i := range publicKeys {
-			publicKeys[i].manager = m
-		}
+func (m *Manager) GetPublicKeysCtx(ctx context.Context, accountId string) (publicKeys []*PubKey, err error) {
+	if publicKeys, err = Collect(m.AllPublicKeysCtx(ctx, accountId)); err != nil {
+		m.log("[REQUEST-ERROR] get-public-keys was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllPublicKeys(accountId string) iter.Seq2[*PubKey, error] {
+	return m.AllPublicKeysCtx(m.ctx, accountId)
+}
+
+func (m *Manager) AllPublicKeysCtx(ctx context.Context, accountId string) iter.Seq2[*PubKey, error] {
+	path := fmt.Sprintf("/v1/account/%s/key", accountId)
+
+	return listItems(m.WithContext(ctx), path, Defaults(), func(publicKey *PubKey) {
+		publicKey.manager = m
+	})
+}
+
 func (a *Account) GetPublicKeys() (publicKeys []*PubKey, err error) {
-	publicKeys, err = a.manager.GetPublicKeys(a.ID)
+	return a.GetPublicKeysCtx(a.manager.ctx)
+}
+
+func (a *Account) GetPublicKeysCtx(ctx context.Context) (publicKeys []*PubKey, err error) {
+	publicKeys, err = a.manager.GetPublicKeysCtx(ctx, a.ID)
 	return
 }
 
+func (a *Account) AllPublicKeys() iter.Seq2[*PubKey, error] {
+	return a.AllPublicKeysCtx(a.manager.ctx)
+}
+
+func (a *Account) AllPublicKeysCtx(ctx context.Context) iter.Seq2[*PubKey, error] {
+	return a.manager.AllPublicKeysCtx(ctx, a.ID)
+}
+
 func (m *Manager) GetPublicKey(id string) (publicKey *PubKey, err error) {
-	account, err := m.GetAccount()
+	return m.GetPublicKeyCtx(m.ctx, id)
+}
+
+func (m *Manager) GetPublicKeyCtx(ctx context.Context, id string) (publicKey *PubKey, err error) {
+	account, err := m.GetAccountCtx(ctx)
 	if err != nil {
-		log.Printf("[REQUEST-ERROR] get-public-key was failed: %s", err)
+		m.log("[REQUEST-ERROR] get-public-key was failed: %s", err)
 		return
 	}
 	path := fmt.Sprintf("/v1/account/%s/key/%s", account.ID, id)
 
-	if err = m.Get(path, Defaults(), &publicKey); err != nil {
-		log.Printf("[REQUEST-ERROR] get-public-key was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &publicKey); err != nil {
+		m.log("[REQUEST-ERROR] get-public-key was failed: %s", err)
 	} else {
 		publicKey.manager = m
 	}
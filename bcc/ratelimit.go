@@ -0,0 +1,129 @@
+package bcc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter delays outgoing requests so they stay under a configured rate.
+// Wait blocks until a request may be sent or ctx is done.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is a RateLimiter that allows Rate requests per second on
+// average with bursts of up to Burst requests. It is safe for concurrent use
+// and is meant to be shared by every Manager talking to the same account.
+// The zero value lets every request through.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket rejects with ErrValidation a rate that is not a positive
+// finite number and a burst below 1, as any such setting would let every
+// request through.
+func NewTokenBucket(rate float64, burst int) (*TokenBucket, error) {
+	if !(rate > 0) || math.IsInf(rate, 1) {
+		return nil, fmt.Errorf("%w: token bucket rate must be positive, got %v", ErrValidation, rate)
+	}
+	if burst < 1 {
+		return nil, fmt.Errorf("%w: token bucket burst must be at least 1, got %d", ErrValidation, burst)
+	}
+
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}, nil
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	if b.rate == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	// Reserve a token right away, so concurrent callers queue up behind
+	// each other instead of racing for the same refill.
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	if err := SleepWithContext(ctx, wait); err != nil {
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// SetMaxInFlight limits the number of requests the manager and every copy
+// made from it by WithContext have in flight at once. Zero removes the limit.
+func (m *Manager) SetMaxInFlight(n int) {
+	if n <= 0 {
+		m.inFlight = nil
+		return
+	}
+
+	m.inFlight = make(chan struct{}, n)
+}
+
+// acquire waits for the rate limiter and a free in-flight slot. The returned
+// function releases the slot.
+func (m *Manager) acquire() (func(), error) {
+	if m.RateLimiter != nil {
+		if err := m.RateLimiter.Wait(m.ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.inFlight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case m.inFlight <- struct{}{}:
+	case <-m.ctx.Done():
+		return nil, m.ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-m.inFlight })
+	}, nil
+}
+
+// releaseOnClose keeps an in-flight slot taken until the response body is
+// closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
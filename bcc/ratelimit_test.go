@@ -0,0 +1,117 @@
+package bcc_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+)
+
+func TestNewTokenBucketRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+	}{
+		{name: "zero rate", rate: 0, burst: 1},
+		{name: "negative rate", rate: -1, burst: 1},
+		{name: "infinite rate", rate: math.Inf(1), burst: 1},
+		{name: "NaN rate", rate: math.NaN(), burst: 1},
+		{name: "zero burst", rate: 1, burst: 0},
+		{name: "negative burst", rate: 1, burst: -2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, err := bcc.NewTokenBucket(tt.rate, tt.burst)
+			if !errors.Is(err, bcc.ErrValidation) || bucket != nil {
+				t.Errorf("NewTokenBucket(%v, %d) = %v, %v, want ErrValidation", tt.rate, tt.burst, bucket, err)
+			}
+		})
+	}
+}
+
+// newTokenBucket returns a bucket the test relies on being valid.
+func newTokenBucket(t *testing.T, rate float64, burst int) *bcc.TokenBucket {
+	t.Helper()
+
+	bucket, err := bcc.NewTokenBucket(rate, burst)
+	if err != nil {
+		t.Fatalf("NewTokenBucket: %s", err)
+	}
+
+	return bucket
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   int
+		waits   int
+		minimum time.Duration
+	}{
+		{name: "burst passes at once", rate: 10, burst: 3, waits: 3, minimum: 0},
+		{name: "rate applies after burst", rate: 100, burst: 1, waits: 4, minimum: 30 * time.Millisecond},
+		{name: "burst then rate", rate: 50, burst: 2, waits: 4, minimum: 40 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket := newTokenBucket(t, tt.rate, tt.burst)
+
+			start := time.Now()
+			for i := 0; i < tt.waits; i++ {
+				if err := bucket.Wait(context.Background()); err != nil {
+					t.Fatalf("Wait: %s", err)
+				}
+			}
+			if elapsed := time.Since(start); elapsed < tt.minimum {
+				t.Errorf("%d waits took %s, want at least %s", tt.waits, elapsed, tt.minimum)
+			}
+		})
+	}
+}
+
+func TestTokenBucketWaitCancelled(t *testing.T) {
+	bucket := newTokenBucket(t, 0.1, 1)
+	if err := bucket.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := bucket.Wait(ctx); err == nil {
+		t.Fatal("Wait returned without a token")
+	}
+}
+
+func TestTokenBucketZeroValue(t *testing.T) {
+	var bucket bcc.TokenBucket
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 100; i++ {
+		if err := bucket.Wait(ctx); err != nil {
+			t.Fatalf("Wait %d on the zero value: %s", i, err)
+		}
+	}
+}
+
+func TestManagerRateLimiter(t *testing.T) {
+	_, m := newServer(t, mainVdc)
+	m.RateLimiter = newTokenBucket(t, 50, 1)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := m.GetVdc("vdc1"); err != nil {
+			t.Fatalf("GetVdc: %s", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("3 requests at 50/s took %s, want at least 40ms", elapsed)
+	}
+}
@@ -0,0 +1,128 @@
+package bcc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how Manager retries requests that failed with a
+// transient error: a connection failure or one of RetryStatuses.
+//
+// Requests with a non-idempotent method (POST, PATCH) are only replayed when
+// RetryNonIdempotent is set or the request carries an Idempotency-Key header,
+// so a create that reached the server is never silently performed twice.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry. It doubles on every
+	// following attempt up to MaxBackoff.
+	MinBackoff time.Duration
+	// MaxBackoff caps every delay, including those asked for by Retry-After.
+	// Zero leaves the delays uncapped.
+	MaxBackoff time.Duration
+	// Jitter randomizes every delay by up to this fraction of it, 0..1.
+	Jitter float64
+	// RetryStatuses lists the HTTP status codes considered transient.
+	RetryStatuses []int
+	// RetryNonIdempotent allows POST and PATCH requests to be replayed.
+	RetryNonIdempotent bool
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		Jitter:      0.2,
+		RetryStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// ShouldRetry reports whether a request that ended with resp or err on the
+// given attempt, counting from 1, may be sent again.
+func (p *RetryPolicy) ShouldRetry(attempt int, req *http.Request, resp *http.Response, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if !p.RetryNonIdempotent && !isIdempotent(req) {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	for _, status := range p.RetryStatuses {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Backoff returns the delay before the attempt following the given one.
+// A Retry-After header on resp takes precedence over the exponential delay.
+func (p *RetryPolicy) Backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return p.capped(delay)
+		}
+	}
+
+	delay := float64(p.MinBackoff) * math.Pow(2, float64(attempt-1))
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if delay >= math.MaxInt64 {
+		return p.capped(math.MaxInt64)
+	}
+
+	return p.capped(time.Duration(delay))
+}
+
+func (p *RetryPolicy) capped(delay time.Duration) time.Duration {
+	if p.MaxBackoff > 0 {
+		return min(delay, p.MaxBackoff)
+	}
+
+	return delay
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
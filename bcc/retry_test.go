@@ -0,0 +1,139 @@
+package bcc_test
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+func fastRetryPolicy() *bcc.RetryPolicy {
+	policy := bcc.DefaultRetryPolicy()
+	policy.MinBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+func TestRetryGet(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		status   int
+		wantErr  bool
+		wantSent int
+	}{
+		{name: "no failure", failures: 0, status: http.StatusBadGateway, wantSent: 1},
+		{name: "recovers from 503", failures: 2, status: http.StatusServiceUnavailable, wantSent: 3},
+		{name: "recovers from 429", failures: 3, status: http.StatusTooManyRequests, wantSent: 4},
+		{name: "gives up after max attempts", failures: 4, status: http.StatusBadGateway, wantErr: true, wantSent: 4},
+		{name: "500 is not retried", failures: 1, status: http.StatusInternalServerError, wantErr: true, wantSent: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newServer(t, mainVdc, object("vm", bcctest.Object{"id": "vm1", "name": "web", "vdc": "vdc1"}))
+			m.RetryPolicy = fastRetryPolicy()
+			s.FailNext(tt.failures, tt.status)
+
+			_, err := m.GetVm("vm1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetVm error = %v, want error %t", err, tt.wantErr)
+			}
+			if sent := len(s.Requests()); sent != tt.wantSent {
+				t.Errorf("sent %d requests, want %d", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestRetryPost(t *testing.T) {
+	tests := []struct {
+		name           string
+		nonIdempotent  bool
+		idempotencyKey string
+		wantErr        bool
+		wantSent       int
+	}{
+		{name: "not replayed", wantErr: true, wantSent: 1},
+		{name: "replayed when allowed", nonIdempotent: true, wantSent: 2},
+		{name: "replayed with idempotency key", idempotencyKey: "power-off-1", wantSent: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newServer(t, mainVdc, object("vm", bcctest.Object{"id": "vm1", "name": "web", "vdc": "vdc1"}))
+			m.RetryPolicy = fastRetryPolicy()
+			m.RetryPolicy.RetryNonIdempotent = tt.nonIdempotent
+			if tt.idempotencyKey != "" {
+				m.Use(func(next bcc.Doer) bcc.Doer {
+					return bcc.DoerFunc(func(ctx context.Context, call *bcc.Call) (*bcc.CallResult, error) {
+						if call.Method == http.MethodPost {
+							call.Header.Set("Idempotency-Key", tt.idempotencyKey)
+						}
+						return next.Do(ctx, call)
+					})
+				})
+			}
+
+			vm, err := m.GetVm("vm1")
+			if err != nil {
+				t.Fatalf("GetVm: %s", err)
+			}
+			s.FailNext(1, http.StatusBadGateway)
+
+			err = vm.PowerOff()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PowerOff error = %v, want error %t", err, tt.wantErr)
+			}
+
+			sent := 0
+			for _, r := range s.Requests() {
+				if r.Method == http.MethodPost {
+					sent++
+				}
+			}
+			if sent != tt.wantSent {
+				t.Errorf("sent %d POST requests, want %d", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	capped := &bcc.RetryPolicy{MaxAttempts: 5, MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	uncapped := &bcc.RetryPolicy{MaxAttempts: 5, MinBackoff: time.Second}
+
+	tests := []struct {
+		name       string
+		policy     *bcc.RetryPolicy
+		attempt    int
+		retryAfter string
+		want       time.Duration
+	}{
+		{name: "first retry", policy: capped, attempt: 1, want: time.Second},
+		{name: "doubles", policy: capped, attempt: 2, want: 2 * time.Second},
+		{name: "capped", policy: capped, attempt: 4, want: 5 * time.Second},
+		{name: "retry after seconds", policy: capped, attempt: 1, retryAfter: "3", want: 3 * time.Second},
+		{name: "retry after capped", policy: capped, attempt: 1, retryAfter: "120", want: 5 * time.Second},
+		{name: "no max backoff", policy: uncapped, attempt: 1, want: time.Second},
+		{name: "no max backoff doubles", policy: uncapped, attempt: 4, want: 8 * time.Second},
+		{name: "no max backoff retry after", policy: uncapped, attempt: 1, retryAfter: "120", want: 120 * time.Second},
+		{name: "no max backoff overflow", policy: uncapped, attempt: 100, want: math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.retryAfter != "" {
+				resp = &http.Response{Header: http.Header{"Retry-After": {tt.retryAfter}}}
+			}
+			if got := tt.policy.Backoff(tt.attempt, resp); got != tt.want {
+				t.Errorf("Backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package bcc
 
 import (
-	"log"
+	"context"
 	"net/http"
 	"net/url"
 )
@@ -21,10 +21,14 @@ func NewRoute(destination, nexthop string) Route {
 }
 
 func (r *Router) GetRoute(id string) (route *Route, err error) {
+	return r.GetRouteCtx(r.manager.ctx, id)
+}
+
+func (r *Router) GetRouteCtx(ctx context.Context, id string) (route *Route, err error) {
 	path, _ := url.JoinPath("v1/router", r.ID, "route", id)
 
-	if err = r.manager.Get(path, Defaults(), &route); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-route was failed: %s", err)
+	if err = r.manager.WithContext(ctx).Get(path, Defaults(), &route); err != nil {
+		r.manager.log("[REQUEST-ERROR]: get-route was failed: %s", err)
 	} else {
 		route.router = r
 	}
@@ -33,6 +37,10 @@ func (r *Router) GetRoute(id string) (route *Route, err error) {
 }
 
 func (r *Router) CreateRoute(route *Route) (err error) {
+	return r.CreateRouteCtx(r.manager.ctx, route)
+}
+
+func (r *Router) CreateRouteCtx(ctx context.Context, route *Route) (err error) {
 	path, _ := url.JoinPath("v1/router", r.ID, "route")
 	args := &struct {
 		Destination string `json:"destination"`
@@ -42,8 +50,8 @@ func (r *Router) CreateRoute(route *Route) (err error) {
 		NextHop:     route.NextHop,
 	}
 
-	if err = r.manager.Request(http.MethodPost, path, args, &route); err != nil {
-		log.Printf("[REQUEST-ERROR]: create-route was failed: %s", err)
+	if err = r.manager.WithContext(ctx).Request(http.MethodPost, path, args, &route); err != nil {
+		r.manager.log("[REQUEST-ERROR]: create-route was failed: %s", err)
 	} else {
 		route.router = r
 	}
@@ -52,6 +60,10 @@ func (r *Router) CreateRoute(route *Route) (err error) {
 }
 
 func (route *Route) Update() (err error) {
+	return route.UpdateCtx(route.router.manager.ctx)
+}
+
+func (route *Route) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/router", route.router.ID, "route", route.ID)
 	args := &struct {
 		Destination string `json:"destination"`
@@ -61,19 +73,27 @@ func (route *Route) Update() (err error) {
 		NextHop:     route.NextHop,
 	}
 
-	if err = route.router.manager.Request(http.MethodPut, path, args, &route); err != nil {
-		log.Printf("[REQUEST-ERROR]: update-route was failed: %s", err)
+	if err = route.router.manager.WithContext(ctx).Request(http.MethodPut, path, args, &route); err != nil {
+		route.router.manager.log("[REQUEST-ERROR]: update-route was failed: %s", err)
 	}
 
 	return
 }
 
 func (route *Route) Delete() error {
+	return route.DeleteCtx(route.router.manager.ctx)
+}
+
+func (route *Route) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/router", route.router.ID, "route", route.ID)
-	return route.router.manager.Delete(path, Defaults(), nil)
+	return route.router.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (route Route) WaitLock() (err error) {
+	return route.WaitLockCtx(route.router.manager.ctx)
+}
+
+func (route Route) WaitLockCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/router", route.router.ID, "route", route.ID)
-	return loopWaitLock(route.router.manager, path)
+	return loopWaitLock(route.router.manager.WithContext(ctx), path)
 }
@@ -1,8 +1,9 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"iter"
 	"net/url"
 )
 
@@ -28,41 +29,67 @@ func NewRouter(name string, floating *string, vdc string) Router {
 }
 
 func (m *Manager) GetRouters(extraArgs ...Arguments) (routers []*Router, err error) {
-	path := "v1/router"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetRoutersCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &routers); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-routers was failed: %s", err)
-	} else {
-		for i := range routers {
-			routers[i].manager = m
-			for x := range routers[i].Ports {
-				routers[i].Ports[x].manager = m
-			}
-			for x := range routers[i].Routes {
-				routers[i].Routes[x].router = routers[i]
-			}
-		}
+func (m *Manager) GetRoutersCtx(ctx context.Context, extraArgs ...Arguments) (routers []*Router, err error) {
+	if routers, err = Collect(m.AllRoutersCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR]: get-routers was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllRouters(extraArgs ...ListOptions) iter.Seq2[*Router, error] {
+	return m.AllRoutersCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllRoutersCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Router, error] {
+	path := "v1/router"
+	return listItems(m.WithContext(ctx), path, typedQuery[RouterListOptions](extraArgs), func(router *Router) {
+		router.manager = m
+		for x := range router.Ports {
+			router.Ports[x].manager = m
+		}
+		for x := range router.Routes {
+			router.Routes[x].router = router
+		}
+	})
+}
+
 func (v *Vdc) GetRouters(extraArgs ...Arguments) (routers []*Router, err error) {
+	return v.GetRoutersCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetRoutersCtx(ctx context.Context, extraArgs ...Arguments) (routers []*Router, err error) {
 	args := Arguments{
 		"vdc": v.ID,
 	}
 	args.merge(extraArgs)
-	routers, err = v.manager.GetRouters(args)
+	routers, err = v.manager.GetRoutersCtx(ctx, args)
 	return
 }
 
+func (v *Vdc) AllRouters(extraArgs ...ListOptions) iter.Seq2[*Router, error] {
+	return v.AllRoutersCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllRoutersCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Router, error] {
+	args := Arguments{
+		"vdc": v.ID,
+	}
+	return v.manager.AllRoutersCtx(ctx, args, listQuery(extraArgs))
+}
+
 func (m *Manager) GetRouter(id string) (router *Router, err error) {
+	return m.GetRouterCtx(m.ctx, id)
+}
+
+func (m *Manager) GetRouterCtx(ctx context.Context, id string) (router *Router, err error) {
 	path, _ := url.JoinPath("v1/router", id)
 
-	if err = m.Get(path, Defaults(), &router); err != nil {
-		log.Printf("[REQUEST-ERROR]: get-router was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &router); err != nil {
+		m.log("[REQUEST-ERROR]: get-router was failed: %s", err)
 	} else {
 		router.manager = m
 		for _, port := range router.Ports {
@@ -77,6 +104,10 @@ func (m *Manager) GetRouter(id string) (router *Router, err error) {
 }
 
 func (v *Vdc) CreateRouter(router *Router) (err error) {
+	return v.CreateRouterCtx(v.manager.ctx, router)
+}
+
+func (v *Vdc) CreateRouterCtx(ctx context.Context, router *Router) (err error) {
 	path := "v1/router"
 	type TempPortCreate struct {
 		ID string `json:"id"`
@@ -111,8 +142,8 @@ func (v *Vdc) CreateRouter(router *Router) (err error) {
 		}
 	}
 
-	if err = v.manager.Request("POST", path, args, &router); err != nil {
-		log.Printf("[REQUEST-ERROR]: create-router was failed: %s", err)
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, &router); err != nil {
+		v.manager.log("[REQUEST-ERROR]: create-router was failed: %s", err)
 	} else {
 		router.manager = v.manager
 	}
@@ -121,6 +152,10 @@ func (v *Vdc) CreateRouter(router *Router) (err error) {
 }
 
 func (r *Router) ConnectPort(port *Port, exsist bool) (err error) {
+	return r.ConnectPortCtx(r.manager.ctx, port, exsist)
+}
+
+func (r *Router) ConnectPortCtx(ctx context.Context, port *Port, exsist bool) (err error) {
 	path := "v1/port"
 	method := "POST"
 	type TempPortCreate struct {
@@ -146,8 +181,8 @@ func (r *Router) ConnectPort(port *Port, exsist bool) (err error) {
 		path, _ = url.JoinPath("v1/port", port.ID)
 	}
 
-	if err = r.manager.Request(method, path, args, &port); err != nil {
-		log.Printf("[REQUEST-ERROR]: connect-port was failed: %s", err)
+	if err = r.manager.WithContext(ctx).Request(method, path, args, &port); err != nil {
+		r.manager.log("[REQUEST-ERROR]: connect-port was failed: %s", err)
 	} else {
 		port.manager = r.manager
 	}
@@ -156,10 +191,14 @@ func (r *Router) ConnectPort(port *Port, exsist bool) (err error) {
 }
 
 func (r *Router) DisconnectPort(port *Port) (err error) {
+	return r.DisconnectPortCtx(r.manager.ctx, port)
+}
+
+func (r *Router) DisconnectPortCtx(ctx context.Context, port *Port) (err error) {
 	path := fmt.Sprintf("v1/port/%s/disconnect", port.ID)
 
-	if err := r.manager.Request("PATCH", path, Defaults(), &port); err != nil {
-		log.Printf("[REQUEST-ERROR]: disconnect-port was failed: %s", err)
+	if err := r.manager.WithContext(ctx).Request("PATCH", path, Defaults(), &port); err != nil {
+		r.manager.log("[REQUEST-ERROR]: disconnect-port was failed: %s", err)
 	} else {
 		for i, routerPorts := range r.Ports {
 			if routerPorts == port {
@@ -173,16 +212,28 @@ func (r *Router) DisconnectPort(port *Port) (err error) {
 }
 
 func (r *Router) Delete() error {
+	return r.DeleteCtx(r.manager.ctx)
+}
+
+func (r *Router) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/router", r.ID)
-	return r.manager.Delete(path, Defaults(), nil)
+	return r.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (r *Router) Rename(name string) error {
+	return r.RenameCtx(r.manager.ctx, name)
+}
+
+func (r *Router) RenameCtx(ctx context.Context, name string) error {
 	path, _ := url.JoinPath("v1/router", r.ID)
-	return r.manager.Request("PUT", path, Arguments{"name": name}, r.ID)
+	return r.manager.WithContext(ctx).Request("PUT", path, Arguments{"name": name}, r.ID)
 }
 
 func (r *Router) Update() (err error) {
+	return r.UpdateCtx(r.manager.ctx)
+}
+
+func (r *Router) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/router", r.ID)
 	args := &struct {
 		ID        string   `json:"id"`
@@ -208,21 +259,25 @@ func (r *Router) Update() (err error) {
 		args.Floating = &r.Floating.ID
 	}
 
-	if err := r.WaitLock(); err != nil {
+	if err := r.WaitLockCtx(ctx); err != nil {
 		return err
 	}
 
-	if err = r.manager.Request("PUT", path, args, r); err != nil {
-		log.Printf("[REQUEST-ERROR]: update-router was failed: %s", err)
+	if err = r.manager.WithContext(ctx).Request("PUT", path, args, r); err != nil {
+		r.manager.log("[REQUEST-ERROR]: update-router was failed: %s", err)
 	}
 
 	return
 }
 
 func (r Router) WaitLock() (err error) {
+	return r.WaitLockCtx(r.manager.ctx)
+}
+
+func (r Router) WaitLockCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/router", r.ID)
-	if err = loopWaitLock(r.manager, path); err != nil {
-		log.Printf("[REQUEST-ERROR]: %s", err)
+	if err = loopWaitLock(r.manager.WithContext(ctx), path); err != nil {
+		r.manager.log("[REQUEST-ERROR]: %s", err)
 	}
 
 	return
@@ -1,8 +1,8 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
 )
 
 type RouterFirewallRule struct {
@@ -45,10 +45,14 @@ func NewRouterFirewallRule(
 }
 
 func (r *Router) CreateFirewallRule(firewallRule *RouterFirewallRule) (err error) {
+	return r.CreateFirewallRuleCtx(r.manager.ctx, firewallRule)
+}
+
+func (r *Router) CreateFirewallRuleCtx(ctx context.Context, firewallRule *RouterFirewallRule) (err error) {
 	path := fmt.Sprintf("v1/router/%s/firewall_rule", r.ID)
 
-	if err = r.manager.Request("POST", path, firewallRule, &firewallRule); err != nil {
-		log.Printf("[REQUEST-ERROR] create-FirewallRule was failed: %s", err)
+	if err = r.manager.WithContext(ctx).Request("POST", path, firewallRule, &firewallRule); err != nil {
+		r.manager.log("[REQUEST-ERROR] create-FirewallRule was failed: %s", err)
 	} else {
 		firewallRule.manager = r.manager
 		firewallRule.routerId = r.ID
@@ -58,10 +62,14 @@ func (r *Router) CreateFirewallRule(firewallRule *RouterFirewallRule) (err error
 }
 
 func (r *Router) GetFirewallRuleById(firewallRuleId string) (firewallRule *RouterFirewallRule, err error) {
+	return r.GetFirewallRuleByIdCtx(r.manager.ctx, firewallRuleId)
+}
+
+func (r *Router) GetFirewallRuleByIdCtx(ctx context.Context, firewallRuleId string) (firewallRule *RouterFirewallRule, err error) {
 	path := fmt.Sprintf("v1/router/%s/firewall_rule/%s", r.ID, firewallRuleId)
 
-	if err = r.manager.Get(path, Defaults(), &firewallRule); err != nil {
-		log.Printf("[REQUEST-ERROR] get-Firewall rule was failed: %s", err)
+	if err = r.manager.WithContext(ctx).Get(path, Defaults(), &firewallRule); err != nil {
+		r.manager.log("[REQUEST-ERROR] get-Firewall rule was failed: %s", err)
 	} else {
 		firewallRule.manager = r.manager
 		firewallRule.routerId = r.ID
@@ -71,33 +79,49 @@ func (r *Router) GetFirewallRuleById(firewallRuleId string) (firewallRule *Route
 }
 
 func (r *Router) GetFirewallRules(extraArgs ...Arguments) (firewallRules []*RouterFirewallRule, err error) {
+	return r.GetFirewallRulesCtx(r.manager.ctx, extraArgs...)
+}
+
+func (r *Router) GetFirewallRulesCtx(ctx context.Context, extraArgs ...Arguments) (firewallRules []*RouterFirewallRule, err error) {
 	path := fmt.Sprintf("v1/router/%s/firewall_rule", r.ID)
 	args := Defaults()
 	args.merge(extraArgs)
 
-	if err = r.manager.Get(path, Defaults(), &firewallRules); err != nil {
-		log.Printf("[REQUEST-ERROR] get-Firewall rules was failed: %s", err)
+	if err = r.manager.WithContext(ctx).Get(path, Defaults(), &firewallRules); err != nil {
+		r.manager.log("[REQUEST-ERROR] get-Firewall rules was failed: %s", err)
 	}
 
 	return
 }
 
 func (f *RouterFirewallRule) Update() (err error) {
+	return f.UpdateCtx(f.manager.ctx)
+}
+
+func (f *RouterFirewallRule) UpdateCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/router/%s/firewall_rule/%s", f.routerId, f.ID)
 
-	if err = f.manager.Request("PUT", path, f, &f); err != nil {
-		log.Printf("[REQUEST-ERROR] update-FirewallRule was failed: %s", err)
+	if err = f.manager.WithContext(ctx).Request("PUT", path, f, &f); err != nil {
+		f.manager.log("[REQUEST-ERROR] update-FirewallRule was failed: %s", err)
 	}
 
 	return
 }
 
 func (f *RouterFirewallRule) Delete() (err error) {
+	return f.DeleteCtx(f.manager.ctx)
+}
+
+func (f *RouterFirewallRule) DeleteCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/router/%s/firewall_rule/%s", f.routerId, f.ID)
-	return f.manager.Delete(path, Defaults(), nil)
+	return f.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (f RouterFirewallRule) WaitLock() (err error) {
+	return f.WaitLockCtx(f.manager.ctx)
+}
+
+func (f RouterFirewallRule) WaitLockCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/router/%s/firewall_rule/%s", f.routerId, f.ID)
-	return loopWaitLock(f.manager, path)
+	return loopWaitLock(f.manager.WithContext(ctx), path)
 }
@@ -1,8 +1,9 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"iter"
 	"net/url"
 )
 
@@ -44,6 +45,10 @@ func NewS3StorageBucket(name string) S3StorageBucket {
 }
 
 func (p *Project) CreateS3Storage(s3 *S3Storage) (err error) {
+	return p.CreateS3StorageCtx(p.manager.ctx, s3)
+}
+
+func (p *Project) CreateS3StorageCtx(ctx context.Context, s3 *S3Storage) (err error) {
 	path := "v1/s3_storage"
 	args := &struct {
 		Name    string   `json:"name"`
@@ -57,8 +62,8 @@ func (p *Project) CreateS3Storage(s3 *S3Storage) (err error) {
 		Tags:    convertTagsToNames(s3.Tags),
 	}
 
-	if err = p.manager.Request("POST", path, args, &s3); err != nil {
-		log.Printf("[REQUEST-ERROR] create-s3Storage was failed: %s", err)
+	if err = p.manager.WithContext(ctx).Request("POST", path, args, &s3); err != nil {
+		p.manager.log("[REQUEST-ERROR] create-s3Storage was failed: %s", err)
 	} else {
 		s3.manager = p.manager
 	}
@@ -67,35 +72,61 @@ func (p *Project) CreateS3Storage(s3 *S3Storage) (err error) {
 }
 
 func (m *Manager) GetS3Storages(extraArgs ...Arguments) (s3Storages []*S3Storage, err error) {
-	path := "v1/s3_storage"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetS3StoragesCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &s3Storages); err != nil {
-		log.Printf("[REQUEST-ERROR] get-s3Storages was failed: %s", err)
-	} else {
-		for i := range s3Storages {
-			s3Storages[i].manager = m
-		}
+func (m *Manager) GetS3StoragesCtx(ctx context.Context, extraArgs ...Arguments) (s3Storages []*S3Storage, err error) {
+	if s3Storages, err = Collect(m.AllS3StoragesCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-s3Storages was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllS3Storages(extraArgs ...ListOptions) iter.Seq2[*S3Storage, error] {
+	return m.AllS3StoragesCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllS3StoragesCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*S3Storage, error] {
+	path := "v1/s3_storage"
+	return listItems(m.WithContext(ctx), path, typedQuery[S3StorageListOptions](extraArgs), func(s3Storage *S3Storage) {
+		s3Storage.manager = m
+	})
+}
+
 func (p *Project) GetS3Storages(extraArgs ...Arguments) (s3Storages []*S3Storage, err error) {
+	return p.GetS3StoragesCtx(p.manager.ctx, extraArgs...)
+}
+
+func (p *Project) GetS3StoragesCtx(ctx context.Context, extraArgs ...Arguments) (s3Storages []*S3Storage, err error) {
 	args := Arguments{
 		"project": p.ID,
 	}
 	args.merge(extraArgs)
-	s3Storages, err = p.manager.GetS3Storages(args)
+	s3Storages, err = p.manager.GetS3StoragesCtx(ctx, args)
 	return
 }
 
+func (p *Project) AllS3Storages(extraArgs ...ListOptions) iter.Seq2[*S3Storage, error] {
+	return p.AllS3StoragesCtx(p.manager.ctx, extraArgs...)
+}
+
+func (p *Project) AllS3StoragesCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*S3Storage, error] {
+	args := Arguments{
+		"project": p.ID,
+	}
+	return p.manager.AllS3StoragesCtx(ctx, args, listQuery(extraArgs))
+}
+
 func (m *Manager) GetS3Storage(id string) (s3Storages *S3Storage, err error) {
+	return m.GetS3StorageCtx(m.ctx, id)
+}
+
+func (m *Manager) GetS3StorageCtx(ctx context.Context, id string) (s3Storages *S3Storage, err error) {
 	path, _ := url.JoinPath("v1/s3_storage", id)
 
-	if err = m.Get(path, Defaults(), &s3Storages); err != nil {
-		log.Printf("[REQUEST-ERROR] get-s3Storage was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &s3Storages); err != nil {
+		m.log("[REQUEST-ERROR] get-s3Storage was failed: %s", err)
 	} else {
 		s3Storages.manager = m
 	}
@@ -104,6 +135,10 @@ func (m *Manager) GetS3Storage(id string) (s3Storages *S3Storage, err error) {
 }
 
 func (s3 *S3Storage) Update() (err error) {
+	return s3.UpdateCtx(s3.manager.ctx)
+}
+
+func (s3 *S3Storage) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/s3_storage", s3.ID)
 	args := &struct {
 		Name string   `json:"name"`
@@ -113,22 +148,30 @@ func (s3 *S3Storage) Update() (err error) {
 		Tags: convertTagsToNames(s3.Tags),
 	}
 
-	if err = s3.manager.Request("PUT", path, args, s3); err != nil {
-		log.Printf("[REQUEST-ERROR] update-s3Storage was failed: %s", err)
+	if err = s3.manager.WithContext(ctx).Request("PUT", path, args, s3); err != nil {
+		s3.manager.log("[REQUEST-ERROR] update-s3Storage was failed: %s", err)
 	} else {
-		s3.WaitLock()
+		s3.WaitLockCtx(ctx)
 	}
 
 	return
 }
 
 func (s3 *S3Storage) Delete() (err error) {
+	return s3.DeleteCtx(s3.manager.ctx)
+}
+
+func (s3 *S3Storage) DeleteCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/s3_storage", s3.ID)
-	err = s3.manager.Delete(path, Defaults(), nil)
+	err = s3.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 	return
 }
 
 func (s3 *S3Storage) CreateBucket(bucket *S3StorageBucket) (err error) {
+	return s3.CreateBucketCtx(s3.manager.ctx, bucket)
+}
+
+func (s3 *S3Storage) CreateBucketCtx(ctx context.Context, bucket *S3StorageBucket) (err error) {
 	path := fmt.Sprintf("v1/s3_storage/%s/bucket", s3.ID)
 	args := &struct {
 		Name string `json:"name"`
@@ -136,8 +179,8 @@ func (s3 *S3Storage) CreateBucket(bucket *S3StorageBucket) (err error) {
 		Name: bucket.Name,
 	}
 
-	if err = s3.manager.Request("POST", path, args, &bucket); err != nil {
-		log.Printf("[REQUEST-ERROR] create-bucket was failed: %s", err)
+	if err = s3.manager.WithContext(ctx).Request("POST", path, args, &bucket); err != nil {
+		s3.manager.log("[REQUEST-ERROR] create-bucket was failed: %s", err)
 	} else {
 		bucket.manager = s3.manager
 		bucket.S3StorageId = s3.ID
@@ -147,31 +190,54 @@ func (s3 *S3Storage) CreateBucket(bucket *S3StorageBucket) (err error) {
 }
 
 func (m *Manager) GetBuckets(id string, extraArgs ...Arguments) (buckets []*S3StorageBucket, err error) {
-	path := fmt.Sprintf("v1/s3_storage/%s/bucket", id)
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetBucketsCtx(m.ctx, id, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &buckets); err != nil {
-		log.Printf("[REQUEST-ERROR] get-buckets was failed: %s", err)
-	} else {
-		for i := range buckets {
-			buckets[i].manager = m
-		}
+func (m *Manager) GetBucketsCtx(ctx context.Context, id string, extraArgs ...Arguments) (buckets []*S3StorageBucket, err error) {
+	if buckets, err = Collect(m.AllBucketsCtx(ctx, id, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-buckets was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllBuckets(id string, extraArgs ...ListOptions) iter.Seq2[*S3StorageBucket, error] {
+	return m.AllBucketsCtx(m.ctx, id, extraArgs...)
+}
+
+func (m *Manager) AllBucketsCtx(ctx context.Context, id string, extraArgs ...ListOptions) iter.Seq2[*S3StorageBucket, error] {
+	path := fmt.Sprintf("v1/s3_storage/%s/bucket", id)
+	return listItems(m.WithContext(ctx), path, typedQuery[BucketListOptions](extraArgs), func(bucket *S3StorageBucket) {
+		bucket.manager = m
+	})
+}
+
 func (s3 *S3Storage) GetBuckets(extraArgs ...Arguments) (buckets []*S3StorageBucket, err error) {
-	buckets, err = s3.manager.GetBuckets(s3.ID, extraArgs...)
+	return s3.GetBucketsCtx(s3.manager.ctx, extraArgs...)
+}
+
+func (s3 *S3Storage) GetBucketsCtx(ctx context.Context, extraArgs ...Arguments) (buckets []*S3StorageBucket, err error) {
+	buckets, err = s3.manager.GetBucketsCtx(ctx, s3.ID, extraArgs...)
 	return
 }
 
+func (s3 *S3Storage) AllBuckets(extraArgs ...ListOptions) iter.Seq2[*S3StorageBucket, error] {
+	return s3.AllBucketsCtx(s3.manager.ctx, extraArgs...)
+}
+
+func (s3 *S3Storage) AllBucketsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*S3StorageBucket, error] {
+	return s3.manager.AllBucketsCtx(ctx, s3.ID, extraArgs...)
+}
+
 func (s3 *S3Storage) GetBucket(id string) (bucket *S3StorageBucket, err error) {
+	return s3.GetBucketCtx(s3.manager.ctx, id)
+}
+
+func (s3 *S3Storage) GetBucketCtx(ctx context.Context, id string) (bucket *S3StorageBucket, err error) {
 	path := fmt.Sprintf("v1/s3_storage/%s/bucket/%s", s3.ID, id)
 
-	if err = s3.manager.Get(path, Defaults(), &bucket); err != nil {
-		log.Printf("[REQUEST-ERROR] get-bucket was failed: %s", err)
+	if err = s3.manager.WithContext(ctx).Get(path, Defaults(), &bucket); err != nil {
+		s3.manager.log("[REQUEST-ERROR] get-bucket was failed: %s", err)
 	} else {
 		bucket.manager = s3.manager
 		bucket.S3StorageId = s3.ID
@@ -181,6 +247,10 @@ func (s3 *S3Storage) GetBucket(id string) (bucket *S3StorageBucket, err error) {
 }
 
 func (b *S3StorageBucket) Update() (err error) {
+	return b.UpdateCtx(b.manager.ctx)
+}
+
+func (b *S3StorageBucket) UpdateCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/s3_storage/%s/bucket/%s", b.S3StorageId, b.ID)
 	args := &struct {
 		Name string `json:"name"`
@@ -188,20 +258,28 @@ func (b *S3StorageBucket) Update() (err error) {
 		Name: b.Name,
 	}
 
-	if err = b.manager.Request("PUT", path, args, b); err != nil {
-		log.Printf("[REQUEST-ERROR] update-bucket was failed: %s", err)
+	if err = b.manager.WithContext(ctx).Request("PUT", path, args, b); err != nil {
+		b.manager.log("[REQUEST-ERROR] update-bucket was failed: %s", err)
 	}
 
 	return
 }
 
 func (b *S3StorageBucket) Delete() (err error) {
+	return b.DeleteCtx(b.manager.ctx)
+}
+
+func (b *S3StorageBucket) DeleteCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/s3_storage/%s/bucket/%s", b.S3StorageId, b.ID)
-	err = b.manager.Delete(path, Defaults(), nil)
+	err = b.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 	return
 }
 
 func (s3 S3Storage) WaitLock() (err error) {
+	return s3.WaitLockCtx(s3.manager.ctx)
+}
+
+func (s3 S3Storage) WaitLockCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/s3_storage", s3.ID)
-	return loopWaitLock(s3.manager, path)
+	return loopWaitLock(s3.manager.WithContext(ctx), path)
 }
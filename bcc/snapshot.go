@@ -0,0 +1,182 @@
+package bcc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Snapshot is a point-in-time copy of a vm and all of its disks.
+type Snapshot struct {
+	manager     *Manager
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Vm          *TmpVm    `json:"vm"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Locked bool   `json:"locked"`
+	JobId  string `json:"job_id"`
+}
+
+func (s *Snapshot) UnmarshalJSON(b []byte) error {
+	type snapshot Snapshot
+	raw := struct {
+		*snapshot
+		CreatedAt string `json:"created_at"`
+	}{
+		snapshot: (*snapshot)(s),
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	s.CreatedAt = parseJobTime(raw.CreatedAt)
+	return nil
+}
+
+func (m *Manager) GetSnapshots(extraArgs ...Arguments) (snapshots []*Snapshot, err error) {
+	return m.GetSnapshotsCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) GetSnapshotsCtx(ctx context.Context, extraArgs ...Arguments) (snapshots []*Snapshot, err error) {
+	if snapshots, err = Collect(m.AllSnapshotsCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-snapshots was failed: %s", err)
+	}
+
+	return
+}
+
+func (m *Manager) AllSnapshots(extraArgs ...ListOptions) iter.Seq2[*Snapshot, error] {
+	return m.AllSnapshotsCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllSnapshotsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Snapshot, error] {
+	path := "v1/snapshot"
+	return listItems(m.WithContext(ctx), path, typedQuery[SnapshotListOptions](extraArgs), func(snapshot *Snapshot) {
+		snapshot.bind(m)
+	})
+}
+
+func (v *Vm) GetSnapshots(extraArgs ...Arguments) (snapshots []*Snapshot, err error) {
+	return v.GetSnapshotsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vm) GetSnapshotsCtx(ctx context.Context, extraArgs ...Arguments) (snapshots []*Snapshot, err error) {
+	args := Arguments{
+		"vm": v.ID,
+	}
+	args.merge(extraArgs)
+	snapshots, err = v.manager.GetSnapshotsCtx(ctx, args)
+	return
+}
+
+func (v *Vm) AllSnapshots(extraArgs ...ListOptions) iter.Seq2[*Snapshot, error] {
+	return v.AllSnapshotsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vm) AllSnapshotsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Snapshot, error] {
+	args := Arguments{
+		"vm": v.ID,
+	}
+	return v.manager.AllSnapshotsCtx(ctx, args, listQuery(extraArgs))
+}
+
+func (m *Manager) GetSnapshot(id string) (snapshot *Snapshot, err error) {
+	return m.GetSnapshotCtx(m.ctx, id)
+}
+
+func (m *Manager) GetSnapshotCtx(ctx context.Context, id string) (snapshot *Snapshot, err error) {
+	path, _ := url.JoinPath("v1/snapshot", id)
+
+	if err = m.WithContext(ctx).Get(path, Defaults(), &snapshot); err != nil {
+		m.log("[REQUEST-ERROR] get-snapshot was failed: %s", err)
+	} else {
+		snapshot.bind(m)
+	}
+
+	return
+}
+
+// CreateSnapshot takes a snapshot of the vm and returns once the snapshot
+// job is finished.
+func (v *Vm) CreateSnapshot(name string, description string) (snapshot *Snapshot, err error) {
+	return v.CreateSnapshotCtx(v.manager.ctx, name, description)
+}
+
+func (v *Vm) CreateSnapshotCtx(ctx context.Context, name string, description string) (snapshot *Snapshot, err error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("%w: snapshot name must not be blank", ErrValidation)
+	}
+
+	path := "v1/snapshot"
+	args := &struct {
+		Vm          string `json:"vm"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}{
+		Vm:          v.ID,
+		Name:        name,
+		Description: description,
+	}
+
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, &snapshot); err != nil {
+		v.manager.log("[REQUEST-ERROR] create-snapshot was failed: %s", err)
+		return nil, err
+	}
+
+	snapshot.bind(v.manager)
+	return
+}
+
+// Revert rolls the vm back to the snapshot. It returns once the vm is
+// unlocked again.
+func (s *Snapshot) Revert() error {
+	return s.RevertCtx(s.manager.ctx)
+}
+
+func (s *Snapshot) RevertCtx(ctx context.Context) (err error) {
+	path := fmt.Sprintf("v1/snapshot/%s/revert", s.ID)
+
+	if err = s.manager.WithContext(ctx).Request("POST", path, nil, s); err != nil {
+		s.manager.log("[REQUEST-ERROR] revert-snapshot was failed: %s", err)
+		return
+	}
+
+	s.bind(s.manager)
+	if s.Vm != nil && jobSetFrom(ctx) == nil {
+		path, _ := url.JoinPath("v1/vm", s.Vm.ID)
+		err = loopWaitLock(s.manager.WithContext(ctx), path)
+	}
+
+	return
+}
+
+func (s *Snapshot) Delete() error {
+	return s.DeleteCtx(s.manager.ctx)
+}
+
+func (s *Snapshot) DeleteCtx(ctx context.Context) error {
+	path, _ := url.JoinPath("v1/snapshot", s.ID)
+	return s.manager.WithContext(ctx).Delete(path, Defaults(), nil)
+}
+
+func (s Snapshot) WaitLock() error {
+	return s.WaitLockCtx(s.manager.ctx)
+}
+
+func (s Snapshot) WaitLockCtx(ctx context.Context) error {
+	path, _ := url.JoinPath("v1/snapshot", s.ID)
+	return loopWaitLock(s.manager.WithContext(ctx), path)
+}
+
+func (s *Snapshot) bind(m *Manager) {
+	s.manager = m
+	if s.Vm != nil {
+		s.Vm.manager = m
+	}
+}
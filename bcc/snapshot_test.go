@@ -0,0 +1,191 @@
+package bcc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+// snapshotFixtures seed vm0 and vm1 with snapshots snap1 and snap2 of vm1
+// and snap3 of vm0.
+var snapshotFixtures = []fixture{
+	mainVdc,
+	vms(2, "vdc1"),
+	object("snapshot", bcctest.Object{"id": "snap1", "name": "before-upgrade", "vm": "vm1", "created_at": "2026-10-01T10:00:00Z"}),
+	object("snapshot", bcctest.Object{"id": "snap2", "name": "nightly", "vm": "vm1", "created_at": "2026-10-02T02:00:00Z"}),
+	object("snapshot", bcctest.Object{"id": "snap3", "name": "nightly", "vm": "vm0", "created_at": "2026-10-02T02:00:00Z"}),
+}
+
+func newSnapshotServer(t *testing.T) (*bcctest.Server, *bcc.Manager, *bcc.Vm) {
+	t.Helper()
+
+	s, m := newServer(t, snapshotFixtures...)
+	vm, err := m.GetVm("vm1")
+	if err != nil {
+		t.Fatalf("GetVm: %s", err)
+	}
+
+	return s, m, vm
+}
+
+func TestVmCreateSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		snap    string
+		wantErr error
+	}{
+		{name: "named", snap: "before-migration"},
+		{name: "blank name", snap: "  ", wantErr: bcc.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, vm := newSnapshotServer(t)
+
+			snapshot, err := vm.CreateSnapshot(tt.snap, "taken by a test")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateSnapshot = %v, want %v", err, tt.wantErr)
+				}
+				for _, r := range s.Requests() {
+					if r.Method == http.MethodPost {
+						t.Errorf("sent %s %s, want nothing", r.Method, r.Path)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateSnapshot: %s", err)
+			}
+
+			if snapshot.Name != tt.snap || snapshot.Description != "taken by a test" || snapshot.Vm == nil || snapshot.Vm.ID != "vm1" {
+				t.Errorf("created %+v, want %s of vm1", snapshot, tt.snap)
+			}
+			if snapshot.CreatedAt.IsZero() {
+				t.Error("created_at was not parsed")
+			}
+			if s.Get("snapshot", snapshot.ID) == nil {
+				t.Errorf("snapshot %s was not stored", snapshot.ID)
+			}
+		})
+	}
+}
+
+func TestGetSnapshots(t *testing.T) {
+	_, m, vm := newSnapshotServer(t)
+
+	all, err := m.GetSnapshots()
+	if err != nil {
+		t.Fatalf("GetSnapshots: %s", err)
+	}
+	if got := names(all, func(s *bcc.Snapshot) string { return s.ID }); got != "snap1,snap2,snap3" {
+		t.Errorf("listed %s, want snap1,snap2,snap3", got)
+	}
+
+	ofVm, err := vm.GetSnapshots()
+	if err != nil {
+		t.Fatalf("Vm.GetSnapshots: %s", err)
+	}
+	if got := names(ofVm, func(s *bcc.Snapshot) string { return s.ID }); got != "snap1,snap2" {
+		t.Errorf("listed %s for vm1, want snap1,snap2", got)
+	}
+
+	snapshot, err := m.GetSnapshot("snap1")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %s", err)
+	}
+	if snapshot.Name != "before-upgrade" || snapshot.Vm.ID != "vm1" || !snapshot.CreatedAt.Equal(time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %+v, want before-upgrade of vm1 taken on 2026-10-01 10:00", snapshot)
+	}
+}
+
+func TestSnapshotJobs(t *testing.T) {
+	tests := []struct {
+		name        string
+		call        func(ctx context.Context, vm *bcc.Vm, snapshot *bcc.Snapshot) error
+		wantJob     string
+		wantDeleted bool
+	}{
+		{
+			name: "create",
+			call: func(ctx context.Context, vm *bcc.Vm, _ *bcc.Snapshot) error {
+				_, err := vm.CreateSnapshotCtx(ctx, "before-migration", "")
+				return err
+			},
+			wantJob: "snapshot.create",
+		},
+		{
+			name: "revert",
+			call: func(ctx context.Context, _ *bcc.Vm, snapshot *bcc.Snapshot) error {
+				return snapshot.RevertCtx(ctx)
+			},
+			wantJob: "snapshot.revert",
+		},
+		{
+			name: "delete",
+			call: func(ctx context.Context, _ *bcc.Vm, snapshot *bcc.Snapshot) error {
+				return snapshot.DeleteCtx(ctx)
+			},
+			wantJob:     "snapshot.delete",
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m, vm := newSnapshotServer(t)
+			s.JobPolls = 2
+			snapshot, err := m.GetSnapshot("snap1")
+			if err != nil {
+				t.Fatalf("GetSnapshot: %s", err)
+			}
+
+			ctx, jobs := bcc.NoWait(context.Background())
+			if err := tt.call(ctx, vm, snapshot); err != nil {
+				t.Fatalf("%s: %s", tt.name, err)
+			}
+			if len(jobs.Jobs()) != 1 {
+				t.Fatalf("collected %d jobs, want 1", len(jobs.Jobs()))
+			}
+
+			job := jobs.Jobs()[0]
+			if err := job.Wait(context.Background()); err != nil {
+				t.Fatalf("Wait: %s", err)
+			}
+			if job.Name != tt.wantJob || job.Status != bcc.JobStatusDone {
+				t.Errorf("job %s is %s, want %s done", job.Name, job.Status, tt.wantJob)
+			}
+			if deleted := s.Get("snapshot", "snap1") == nil; deleted != tt.wantDeleted {
+				t.Errorf("snap1 deleted = %t, want %t", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestSnapshotRevertWaitsForVm(t *testing.T) {
+	s, m, _ := newSnapshotServer(t)
+	snapshot, err := m.GetSnapshot("snap1")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %s", err)
+	}
+
+	s.Lock("vm", "vm1")
+	unlock := time.AfterFunc(50*time.Millisecond, func() { s.Unlock("vm", "vm1") })
+	defer unlock.Stop()
+
+	start := time.Now()
+	if err := snapshot.Revert(); err != nil {
+		t.Fatalf("Revert: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Revert returned after %s, before the vm was unlocked", elapsed)
+	}
+	if polls := listRequests(s, "/v1/vm/vm1"); polls < 2 {
+		t.Errorf("polled the vm %d times, want it polled until unlocked", polls)
+	}
+}
@@ -1,6 +1,9 @@
 package bcc
 
-import "log"
+import (
+	"context"
+	"iter"
+)
 
 type SshKey struct {
 	manager   *Manager
@@ -15,15 +18,25 @@ func NewSshKey(name string, publicKey string) SshKey {
 }
 
 func (m *Manager) GetSshKeys() (sshKeys []*SshKey, err error) {
-	path := "v1/account/me/key"
+	return m.GetSshKeysCtx(m.ctx)
+}
 
-	if err = m.GetItems(path, Defaults(), &sshKeys); err != nil {
-		log.Printf("[REQUEST-ERROR] get-ssh-keys was failed: %s", err)
-	} else {
-		for i := range sshKeys {
-			sshKeys[i].manager = m
-		}
+func (m *Manager) GetSshKeysCtx(ctx context.Context) (sshKeys []*SshKey, err error) {
+	if sshKeys, err = Collect(m.AllSshKeysCtx(ctx)); err != nil {
+		m.log("[REQUEST-ERROR] get-ssh-keys was failed: %s", err)
 	}
 
 	return
 }
+
+func (m *Manager) AllSshKeys() iter.Seq2[*SshKey, error] {
+	return m.AllSshKeysCtx(m.ctx)
+}
+
+func (m *Manager) AllSshKeysCtx(ctx context.Context) iter.Seq2[*SshKey, error] {
+	path := "v1/account/me/key"
+
+	return listItems(m.WithContext(ctx), path, Defaults(), func(sshKey *SshKey) {
+		sshKey.manager = m
+	})
+}
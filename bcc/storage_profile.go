@@ -1,7 +1,8 @@
 package bcc
 
 import (
-	"log"
+	"context"
+	"iter"
 	"net/url"
 
 	"github.com/pkg/errors"
@@ -16,31 +17,44 @@ type StorageProfile struct {
 }
 
 func (v *Vdc) GetStorageProfiles(extraArgs ...Arguments) (storageProfiles []*StorageProfile, err error) {
+	return v.GetStorageProfilesCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetStorageProfilesCtx(ctx context.Context, extraArgs ...Arguments) (storageProfiles []*StorageProfile, err error) {
+	if storageProfiles, err = Collect(v.AllStorageProfilesCtx(ctx, arguments(extraArgs))); err != nil {
+		v.manager.log("[REQUEST-ERROR] get-storageProfiles was failed: %s", err)
+	}
+
+	return
+}
+
+func (v *Vdc) AllStorageProfiles(extraArgs ...ListOptions) iter.Seq2[*StorageProfile, error] {
+	return v.AllStorageProfilesCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllStorageProfilesCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*StorageProfile, error] {
 	path := "v1/storage_profile"
 	args := Arguments{
 		"vdc": v.ID,
 	}
-	args.merge(extraArgs)
-
-	if err = v.manager.GetItems(path, args, &storageProfiles); err != nil {
-		log.Printf("[REQUEST-ERROR] get-storageProfiles was failed: %s", err)
-	} else {
-		for i := range storageProfiles {
-			storageProfiles[i].manager = v.manager
-		}
-	}
 
-	return
+	return listItems(v.manager.WithContext(ctx).cached(CacheStorageProfiles), path, typedQuery[StorageProfileListOptions]{args, listQuery(extraArgs)}, func(storageProfile *StorageProfile) {
+		storageProfile.manager = v.manager
+	})
 }
 
 func (v *Vdc) GetStorageProfile(id string) (storageProfile *StorageProfile, err error) {
+	return v.GetStorageProfileCtx(v.manager.ctx, id)
+}
+
+func (v *Vdc) GetStorageProfileCtx(ctx context.Context, id string) (storageProfile *StorageProfile, err error) {
 	path, _ := url.JoinPath("v1/storage_profile", id)
 	args := Arguments{
 		"vdc": v.ID,
 	}
 
-	if err = v.manager.Get(path, args, &storageProfile); err != nil {
-		log.Printf("[REQUEST-ERROR] get-storageProfile was failed: %s", errors.WithStack(err))
+	if err = v.manager.WithContext(ctx).Get(path, args, &storageProfile); err != nil {
+		v.manager.log("[REQUEST-ERROR] get-storageProfile was failed: %s", errors.WithStack(err))
 	} else {
 		storageProfile.manager = v.manager
 	}
@@ -1,8 +1,8 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
 )
 
 type SubnetDNSServer struct {
@@ -51,46 +51,70 @@ func NewSubnetRoute(cidr string, gateway string, metric int) SubnetRoute {
 }
 
 func (s *Subnet) Delete() (err error) {
+	return s.DeleteCtx(s.manager.ctx)
+}
+
+func (s *Subnet) DeleteCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/network/%s/subnet/%s", s.network.ID, s.ID)
 
-	if err = s.manager.Delete(path, Defaults(), nil); err != nil {
-		log.Printf("[REQUEST-ERROR] delete-subnet was failed: %s", err)
+	if err = s.manager.WithContext(ctx).Delete(path, Defaults(), nil); err != nil {
+		s.manager.log("[REQUEST-ERROR] delete-subnet was failed: %s", err)
 	}
 
 	return
 }
 
-func (s *Subnet) update() (err error) {
+func (s *Subnet) update(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/network/%s/subnet/%s", s.network.ID, s.ID)
 
-	if err = s.manager.Request("PUT", path, s, s); err != nil {
-		log.Printf("[REQUEST-ERROR] update-subnet was failed: %s", err)
+	if err = s.manager.WithContext(ctx).Request("PUT", path, s, s); err != nil {
+		s.manager.log("[REQUEST-ERROR] update-subnet was failed: %s", err)
 	}
 
 	return
 }
 
 func (s *Subnet) EnableDHCP() error {
+	return s.EnableDHCPCtx(s.manager.ctx)
+}
+
+func (s *Subnet) EnableDHCPCtx(ctx context.Context) error {
 	s.IsDHCP = true
-	return s.update()
+	return s.update(ctx)
 }
 
 func (s *Subnet) DisableDHCP() error {
+	return s.DisableDHCPCtx(s.manager.ctx)
+}
+
+func (s *Subnet) DisableDHCPCtx(ctx context.Context) error {
 	s.IsDHCP = false
-	return s.update()
+	return s.update(ctx)
 }
 
 func (s *Subnet) UpdateDNSServers(dnsServers []*SubnetDNSServer) error {
+	return s.UpdateDNSServersCtx(s.manager.ctx, dnsServers)
+}
+
+func (s *Subnet) UpdateDNSServersCtx(ctx context.Context, dnsServers []*SubnetDNSServer) error {
 	s.DnsServers = dnsServers
-	return s.update()
+	return s.update(ctx)
 }
 
 func (s *Subnet) UpdateRoutes(routes []*SubnetRoute) error {
+	return s.UpdateRoutesCtx(s.manager.ctx, routes)
+}
+
+func (s *Subnet) UpdateRoutesCtx(ctx context.Context, routes []*SubnetRoute) error {
 	s.SubnetRoutes = routes
-	return s.update()
+	return s.update(ctx)
 }
 
 func (s Subnet) WaitLock() (err error) {
+	return s.WaitLockCtx(s.manager.ctx)
+}
+
+func (s Subnet) WaitLockCtx(ctx context.Context) (err error) {
 	path := fmt.Sprintf("v1/network/%s/subnet/%s", s.network.ID, s.ID)
-	return loopWaitLock(s.manager, path)
+	return loopWaitLock(s.manager.WithContext(ctx), path)
 }
@@ -1,7 +1,7 @@
 package bcc
 
 import (
-	"log"
+	"context"
 	"net/url"
 )
 
@@ -15,10 +15,14 @@ type Template struct {
 }
 
 func (m *Manager) GetTemplate(id string) (template *Template, err error) {
+	return m.GetTemplateCtx(m.ctx, id)
+}
+
+func (m *Manager) GetTemplateCtx(ctx context.Context, id string) (template *Template, err error) {
 	path, _ := url.JoinPath("v1/template", id)
 
-	if err = m.Get(path, Defaults(), &template); err != nil {
-		log.Printf("[REQUEST-ERROR] get-template with id='%s' was failed: %s", id, err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &template); err != nil {
+		m.log("[REQUEST-ERROR] get-template with id='%s' was failed: %s", id, err)
 	} else {
 		template.manager = m
 	}
@@ -27,14 +31,18 @@ func (m *Manager) GetTemplate(id string) (template *Template, err error) {
 }
 
 func (v *Vdc) GetTemplates(extraArgs ...Arguments) (templates []*Template, err error) {
+	return v.GetTemplatesCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetTemplatesCtx(ctx context.Context, extraArgs ...Arguments) (templates []*Template, err error) {
 	path := "v1/template"
 	args := Arguments{
 		"vdc": v.ID,
 	}
 	args.merge(extraArgs)
 
-	if err = v.manager.Get(path, args, &templates); err != nil {
-		log.Printf("[REQUEST-ERROR] get-templates was failed: %s", err)
+	if err = v.manager.WithContext(ctx).cached(CacheTemplates).Get(path, args, &templates); err != nil {
+		v.manager.log("[REQUEST-ERROR] get-templates was failed: %s", err)
 	} else {
 		for i := range templates {
 			templates[i].manager = v.manager
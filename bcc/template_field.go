@@ -1,8 +1,8 @@
 package bcc
 
 import (
+	"context"
 	"fmt"
-	"log"
 )
 
 type TemplateField struct {
@@ -18,10 +18,14 @@ type TemplateField struct {
 }
 
 func (t *Template) GetFields() (fields []*TemplateField, err error) {
+	return t.GetFieldsCtx(t.manager.ctx)
+}
+
+func (t *Template) GetFieldsCtx(ctx context.Context) (fields []*TemplateField, err error) {
 	path := fmt.Sprintf("v1/template/%s/field", t.ID)
 
-	if err = t.manager.Get(path, Defaults(), &fields); err != nil {
-		log.Printf("[REQUEST-ERROR] get-template-fields was failed: %s", err)
+	if err = t.manager.WithContext(ctx).Get(path, Defaults(), &fields); err != nil {
+		t.manager.log("[REQUEST-ERROR] get-template-fields was failed: %s", err)
 	} else {
 		for i := range fields {
 			fields[i].manager = t.manager
@@ -0,0 +1,116 @@
+package bcc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Tracer starts the spans of a Manager. Set Manager.Tracer to enable
+// tracing, the separate module github.com/basis-cloud/bcc-go/bcc/bccotel
+// provides one backed by OpenTelemetry.
+//
+// Every call gets a span named after the operation, e.g. bcc.vm.create,
+// that covers its job waits. Below it are spans for each HTTP attempt
+// (bcc.http), for waiting out 409 locks (bcc.lock_wait), for WaitLock
+// loops (bcc.wait_lock) and for job waits (bcc.job.wait).
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	// RecordError records err and marks the span as failed.
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a span attribute. Value is a string, bool, int, int64,
+// float64 or []string.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// startSpan returns a manager bound to the context of the new span, so the
+// spans started through it become its children.
+func (m *Manager) startSpan(name string, attrs ...Attribute) (*Manager, Span) {
+	if m.Tracer == nil {
+		return m, noopSpan{}
+	}
+
+	ctx, span := m.Tracer.Start(m.ctx, name, attrs...)
+	traced := m.WithContext(ctx)
+	traced.span = span
+	return traced, span
+}
+
+// traceOperation starts the span of the API call method path.
+func (m *Manager) traceOperation(method string, path string) (*Manager, Span) {
+	if m.Tracer == nil {
+		return m, noopSpan{}
+	}
+
+	op := parseOperation(method, path)
+	return m.startSpan(op.Name, op.attributes(method)...)
+}
+
+func (op operation) attributes(method string) []Attribute {
+	attrs := []Attribute{
+		Attr("http.request.method", method),
+		Attr("http.route", op.Route),
+	}
+	for resource, id := range op.IDs {
+		attrs = append(attrs, Attr("bcc."+resource+".id", id))
+	}
+
+	return attrs
+}
+
+// traceCreated tags the span of a create call with the id of the new
+// object.
+func (m *Manager) traceCreated(method string, path string, body []byte) {
+	if m.span == nil || len(body) == 0 {
+		return
+	}
+
+	op := parseOperation(method, path)
+	if !strings.HasSuffix(op.Name, ".create") {
+		return
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if json.Unmarshal(body, &created) == nil && created.ID != "" {
+		m.span.SetAttributes(Attr("bcc."+op.Resource+".id", created.ID))
+	}
+}
+
+// endSpan records err, along with the status and error aliases of an API
+// error, and ends span.
+func endSpan(span Span, err error) {
+	if err != nil {
+		var apiErr *ApiError
+		if errors.As(err, &apiErr) {
+			span.SetAttributes(Attr("http.response.status_code", apiErr.Code()))
+			if aliases := apiErr.ErrorAliases(); len(aliases) > 0 {
+				span.SetAttributes(Attr("bcc.error_alias", aliases))
+			}
+		}
+		span.RecordError(err)
+	}
+
+	span.End()
+}
@@ -81,6 +81,14 @@ func SleepWithContext(ctx context.Context, dur time.Duration) error {
 }
 
 func loopWaitLock(manager *Manager, path string) (err error) {
+	op := parseOperation("GET", path)
+	manager, span := manager.startSpan("bcc.wait_lock", op.attributes("GET")...)
+	polls := 0
+	defer func() {
+		span.SetAttributes(Attr("bcc.polls", polls))
+		endSpan(span, err)
+	}()
+
 	var wait struct {
 		Locked bool `json:"locked"`
 	}
@@ -92,6 +100,7 @@ func loopWaitLock(manager *Manager, path string) (err error) {
 	defer ticker.Stop()
 
 	for {
+		polls++
 		if err = manager.Get(path, Defaults(), &wait); err != nil {
 			return err
 		}
@@ -102,7 +111,7 @@ func loopWaitLock(manager *Manager, path string) (err error) {
 
 		select {
 		case <-ctx.Done():
-			manager.log("[ERROR] crash via waitlock unlock for '%s' took more than %ds", path, manager.RequestTimeout.Seconds())
+			manager.log("[ERROR] crash via waitlock unlock for '%s' took more than %.0fs", path, manager.RequestTimeout.Seconds())
 			return ctx.Err()
 		case <-ticker.C:
 		}
@@ -1,7 +1,8 @@
 package bcc
 
 import (
-	"log"
+	"context"
+	"iter"
 	"net/url"
 )
 
@@ -31,35 +32,61 @@ func NewVdc(name string, hypervisor *Hypervisor) Vdc {
 }
 
 func (m *Manager) GetVdcs(extraArgs ...Arguments) (vdcs []*Vdc, err error) {
-	path := "v1/vdc"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetVdcsCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &vdcs); err != nil {
-		log.Printf("[REQUEST-ERROR] get-vdcs was failed: %s", err)
-	} else {
-		for i := range vdcs {
-			vdcs[i].manager = m
-		}
+func (m *Manager) GetVdcsCtx(ctx context.Context, extraArgs ...Arguments) (vdcs []*Vdc, err error) {
+	if vdcs, err = Collect(m.AllVdcsCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-vdcs was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllVdcs(extraArgs ...ListOptions) iter.Seq2[*Vdc, error] {
+	return m.AllVdcsCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllVdcsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Vdc, error] {
+	path := "v1/vdc"
+	return listItems(m.WithContext(ctx), path, typedQuery[VdcListOptions](extraArgs), func(vdc *Vdc) {
+		vdc.manager = m
+	})
+}
+
 func (v *Vdc) GetVdcs(extraArgs ...Arguments) (vdcs []*Vdc, err error) {
+	return v.GetVdcsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetVdcsCtx(ctx context.Context, extraArgs ...Arguments) (vdcs []*Vdc, err error) {
 	args := Arguments{
 		"vdc": v.ID,
 	}
 	args.merge(extraArgs)
-	vdcs, err = v.manager.GetVdcs(args)
+	vdcs, err = v.manager.GetVdcsCtx(ctx, args)
 	return
 }
 
+func (v *Vdc) AllVdcs(extraArgs ...ListOptions) iter.Seq2[*Vdc, error] {
+	return v.AllVdcsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllVdcsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Vdc, error] {
+	args := Arguments{
+		"vdc": v.ID,
+	}
+	return v.manager.AllVdcsCtx(ctx, args, listQuery(extraArgs))
+}
+
 func (m *Manager) GetVdc(id string) (vdc *Vdc, err error) {
+	return m.GetVdcCtx(m.ctx, id)
+}
+
+func (m *Manager) GetVdcCtx(ctx context.Context, id string) (vdc *Vdc, err error) {
 	path, _ := url.JoinPath("v1/vdc", id)
 
-	if err = m.Get(path, Defaults(), &vdc); err != nil {
-		log.Printf("[REQUEST-ERROR] get-vdc with id='%s' was failed: %s", id, err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &vdc); err != nil {
+		m.log("[REQUEST-ERROR] get-vdc with id='%s' was failed: %s", id, err)
 	} else {
 		vdc.manager = m
 	}
@@ -68,6 +95,10 @@ func (m *Manager) GetVdc(id string) (vdc *Vdc, err error) {
 }
 
 func (p *Project) CreateVdc(vdc *Vdc) (err error) {
+	return p.CreateVdcCtx(p.manager.ctx, vdc)
+}
+
+func (p *Project) CreateVdcCtx(ctx context.Context, vdc *Vdc) (err error) {
 	path := "v1/vdc"
 	args := &struct {
 		Name       string   `json:"name"`
@@ -81,8 +112,8 @@ func (p *Project) CreateVdc(vdc *Vdc) (err error) {
 		Tags:       convertTagsToNames(vdc.Tags),
 	}
 
-	if err = p.manager.Request("POST", path, args, &vdc); err != nil {
-		log.Printf("[REQUEST-ERROR] create-vdc was failed: %s", err)
+	if err = p.manager.WithContext(ctx).Request("POST", path, args, &vdc); err != nil {
+		p.manager.log("[REQUEST-ERROR] create-vdc was failed: %s", err)
 	} else {
 		vdc.manager = p.manager
 	}
@@ -91,11 +122,19 @@ func (p *Project) CreateVdc(vdc *Vdc) (err error) {
 }
 
 func (v *Vdc) Rename(name string) error {
+	return v.RenameCtx(v.manager.ctx, name)
+}
+
+func (v *Vdc) RenameCtx(ctx context.Context, name string) error {
 	v.Name = name
-	return v.Update()
+	return v.UpdateCtx(ctx)
 }
 
 func (v *Vdc) Update() (err error) {
+	return v.UpdateCtx(v.manager.ctx)
+}
+
+func (v *Vdc) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/vdc", v.ID)
 	args := &struct {
 		Name string   `json:"name"`
@@ -105,28 +144,36 @@ func (v *Vdc) Update() (err error) {
 		Tags: convertTagsToNames(v.Tags),
 	}
 
-	if err = v.manager.Request("PUT", path, args, v); err != nil {
-		log.Printf("[REQUEST-ERROR] update-vdc was failed: %s", err)
+	if err = v.manager.WithContext(ctx).Request("PUT", path, args, v); err != nil {
+		v.manager.log("[REQUEST-ERROR] update-vdc was failed: %s", err)
 	}
 
 	return
 }
 
 func (v *Vdc) Delete() (err error) {
+	return v.DeleteCtx(v.manager.ctx)
+}
+
+func (v *Vdc) DeleteCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/vdc", v.ID)
 
-	if err = v.manager.Delete(path, Defaults(), nil); err != nil {
-		log.Printf("[REQUEST-ERROR] delete-vdc was failed: %s", err)
+	if err = v.manager.WithContext(ctx).Delete(path, Defaults(), nil); err != nil {
+		v.manager.log("[REQUEST-ERROR] delete-vdc was failed: %s", err)
 	}
 
 	return
 }
 
 func (v Vdc) WaitLock() (err error) {
+	return v.WaitLockCtx(v.manager.ctx)
+}
+
+func (v Vdc) WaitLockCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/vdc", v.ID)
 
-	if err = loopWaitLock(v.manager, path); err != nil {
-		log.Printf("[REQUEST-ERROR] wait-lock for vdc-%s was failed: %s", v.ID, err)
+	if err = loopWaitLock(v.manager.WithContext(ctx), path); err != nil {
+		v.manager.log("[REQUEST-ERROR] wait-lock for vdc-%s was failed: %s", v.ID, err)
 	}
 
 	return
@@ -1,9 +1,13 @@
 package bcc
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"iter"
+	"net/http"
 	"net/url"
+	"time"
 )
 
 type Vm struct {
@@ -38,45 +42,71 @@ func NewVm(name string, cpu int, ram float64, template *Template, metadata []*Vm
 }
 
 func (m *Manager) GetVms(extraArgs ...Arguments) (vms []*Vm, err error) {
-	path := "v1/vm"
-	args := Defaults()
-	args.merge(extraArgs)
+	return m.GetVmsCtx(m.ctx, extraArgs...)
+}
 
-	if err = m.GetItems(path, args, &vms); err != nil {
-		log.Printf("[REQUEST-ERROR] get-vms was failed: %s", err)
-	} else {
-		for i := range vms {
-			vms[i].manager = m
-			for x := range vms[i].Ports {
-				vms[i].Ports[x].manager = m
-			}
-			for x := range vms[i].Disks {
-				vms[i].Disks[x].manager = m
-			}
-			vms[i].Vdc.manager = m
-			if vms[i].Floating != nil {
-				vms[i].Floating.manager = m
-			}
-		}
+func (m *Manager) GetVmsCtx(ctx context.Context, extraArgs ...Arguments) (vms []*Vm, err error) {
+	if vms, err = Collect(m.AllVmsCtx(ctx, arguments(extraArgs))); err != nil {
+		m.log("[REQUEST-ERROR] get-vms was failed: %s", err)
 	}
 
 	return
 }
 
+func (m *Manager) AllVms(extraArgs ...ListOptions) iter.Seq2[*Vm, error] {
+	return m.AllVmsCtx(m.ctx, extraArgs...)
+}
+
+func (m *Manager) AllVmsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Vm, error] {
+	path := "v1/vm"
+	return listItems(m.WithContext(ctx), path, typedQuery[VmListOptions](extraArgs), func(vm *Vm) {
+		vm.manager = m
+		for x := range vm.Ports {
+			vm.Ports[x].manager = m
+		}
+		for x := range vm.Disks {
+			vm.Disks[x].manager = m
+		}
+		vm.Vdc.manager = m
+		if vm.Floating != nil {
+			vm.Floating.manager = m
+		}
+	})
+}
+
 func (v *Vdc) GetVms(extraArgs ...Arguments) (vms []*Vm, err error) {
+	return v.GetVmsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) GetVmsCtx(ctx context.Context, extraArgs ...Arguments) (vms []*Vm, err error) {
 	args := Arguments{
 		"vdc": v.ID,
 	}
 	args.merge(extraArgs)
-	vms, err = v.manager.GetVms(args)
+	vms, err = v.manager.GetVmsCtx(ctx, args)
 	return
 }
 
+func (v *Vdc) AllVms(extraArgs ...ListOptions) iter.Seq2[*Vm, error] {
+	return v.AllVmsCtx(v.manager.ctx, extraArgs...)
+}
+
+func (v *Vdc) AllVmsCtx(ctx context.Context, extraArgs ...ListOptions) iter.Seq2[*Vm, error] {
+	args := Arguments{
+		"vdc": v.ID,
+	}
+	return v.manager.AllVmsCtx(ctx, args, listQuery(extraArgs))
+}
+
 func (m *Manager) GetVm(id string) (vm *Vm, err error) {
+	return m.GetVmCtx(m.ctx, id)
+}
+
+func (m *Manager) GetVmCtx(ctx context.Context, id string) (vm *Vm, err error) {
 	path, _ := url.JoinPath("v1/vm", id)
 
-	if err = m.Get(path, Defaults(), &vm); err != nil {
-		log.Printf("[REQUEST-ERROR] get-vm was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &vm); err != nil {
+		m.log("[REQUEST-ERROR] get-vm was failed: %s", err)
 	} else {
 		vm.manager = m
 		for x := range vm.Ports {
@@ -95,6 +125,10 @@ func (m *Manager) GetVm(id string) (vm *Vm, err error) {
 }
 
 func (v *Vdc) CreateVm(vm *Vm) (err error) {
+	return v.CreateVmCtx(v.manager.ctx, vm)
+}
+
+func (v *Vdc) CreateVmCtx(ctx context.Context, vm *Vm) (err error) {
 	path := "v1/vm"
 	type idList struct {
 		ID string `json:"id"`
@@ -181,8 +215,8 @@ func (v *Vdc) CreateVm(vm *Vm) (err error) {
 		args.Platform = &vm.Platform.ID
 	}
 
-	if err = v.manager.Request("POST", path, args, &vm); err != nil {
-		log.Printf("[REQUEST-ERROR] create-vm was failed: %s", err)
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, &vm); err != nil {
+		v.manager.log("[REQUEST-ERROR] create-vm was failed: %s", err)
 	} else {
 		vm.manager = v.manager
 		for idx := range vm.Ports {
@@ -200,6 +234,10 @@ func (v *Vdc) CreateVm(vm *Vm) (err error) {
 }
 
 func (v *Vm) ConnectPort(port *Port, exsist bool) (err error) {
+	return v.ConnectPortCtx(v.manager.ctx, port, exsist)
+}
+
+func (v *Vm) ConnectPortCtx(ctx context.Context, port *Port, exsist bool) (err error) {
 	path := "v1/port"
 	method := "POST"
 	type TempPortCreate struct {
@@ -227,8 +265,8 @@ func (v *Vm) ConnectPort(port *Port, exsist bool) (err error) {
 		method = "PUT"
 	}
 
-	if err = v.manager.Request(method, path, args, &port); err != nil {
-		log.Printf("[REQUEST-ERROR]: connect-port was failed: %s", err)
+	if err = v.manager.WithContext(ctx).Request(method, path, args, &port); err != nil {
+		v.manager.log("[REQUEST-ERROR]: connect-port was failed: %s", err)
 	} else {
 		port.manager = v.manager
 	}
@@ -237,10 +275,14 @@ func (v *Vm) ConnectPort(port *Port, exsist bool) (err error) {
 }
 
 func (v *Vm) DisconnectPort(port *Port) (err error) {
+	return v.DisconnectPortCtx(v.manager.ctx, port)
+}
+
+func (v *Vm) DisconnectPortCtx(ctx context.Context, port *Port) (err error) {
 	path := fmt.Sprintf("v1/port/%s/disconnect", port.ID)
 
-	if err = v.manager.Request("PATCH", path, nil, nil); err != nil {
-		log.Printf("[REQUEST-ERROR]: disconnect-port was failed: %s", err)
+	if err = v.manager.WithContext(ctx).Request("PATCH", path, nil, nil); err != nil {
+		v.manager.log("[REQUEST-ERROR]: disconnect-port was failed: %s", err)
 	} else {
 		for i, vmPorts := range v.Ports {
 			if vmPorts == port {
@@ -254,23 +296,106 @@ func (v *Vm) DisconnectPort(port *Port) (err error) {
 }
 
 func (v *Vm) PowerOn() error {
-	return v.updateState("power_on")
+	return v.PowerOnCtx(v.manager.ctx)
+}
+
+func (v *Vm) PowerOnCtx(ctx context.Context) error {
+	return v.updateState(ctx, "power_on")
 }
 
 func (v *Vm) PowerOff() error {
-	return v.updateState("power_off")
+	return v.PowerOffCtx(v.manager.ctx)
+}
+
+func (v *Vm) PowerOffCtx(ctx context.Context) error {
+	return v.updateState(ctx, "power_off")
 }
 
 func (v *Vm) Reboot() error {
-	return v.updateState("reboot")
+	return v.RebootCtx(v.manager.ctx)
+}
+
+func (v *Vm) RebootCtx(ctx context.Context) error {
+	return v.updateState(ctx, "reboot")
+}
+
+// ShutdownGuest asks the guest os to shut down, unlike PowerOff which cuts
+// the power. Use WaitForPower to learn when the guest is down.
+func (v *Vm) ShutdownGuest() error {
+	return v.ShutdownGuestCtx(v.manager.ctx)
+}
+
+func (v *Vm) ShutdownGuestCtx(ctx context.Context) error {
+	return v.updateState(ctx, "shutdown")
+}
+
+// Suspend saves the memory of a running vm and stops it.
+func (v *Vm) Suspend() error {
+	return v.SuspendCtx(v.manager.ctx)
+}
+
+func (v *Vm) SuspendCtx(ctx context.Context) error {
+	return v.updateState(ctx, "suspend")
+}
+
+// Resume starts a suspended vm from the saved memory.
+func (v *Vm) Resume() error {
+	return v.ResumeCtx(v.manager.ctx)
+}
+
+func (v *Vm) ResumeCtx(ctx context.Context) error {
+	return v.updateState(ctx, "resume")
+}
+
+// Reset restarts the vm without notifying the guest os, unlike Reboot.
+func (v *Vm) Reset() error {
+	return v.ResetCtx(v.manager.ctx)
+}
+
+func (v *Vm) ResetCtx(ctx context.Context) error {
+	return v.updateState(ctx, "reset")
+}
+
+// WaitForPower reloads the vm every Manager.JobPollInterval until its power
+// is on, or off, as asked, ctx is done or Manager.JobTimeout elapses.
+func (v *Vm) WaitForPower(ctx context.Context, power bool) error {
+	m := v.manager
+	if m.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.JobTimeout)
+		defer cancel()
+	}
+
+	interval := m.JobPollInterval
+	if interval <= 0 {
+		interval = RetryTime * time.Millisecond
+	}
+
+	for {
+		if err := v.ReloadCtx(ctx); err != nil {
+			return err
+		}
+		if v.Power == power && !v.Locked {
+			return nil
+		}
+
+		if err := SleepWithContext(ctx, interval); err != nil {
+			m.log("[bcc] Waiting power %t of vm %s took more than %s", power, v.ID, m.JobTimeout)
+			return err
+		}
+	}
 }
 
 func (v *Vm) Reload() (err error) {
+	return v.ReloadCtx(v.manager.ctx)
+}
+
+func (v *Vm) ReloadCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/vm", v.ID)
 	m := v.manager
 
-	if err = m.Get(path, Defaults(), &v); err != nil {
-		log.Printf("[REQUEST-ERROR] get-vm was failed: %s", err)
+	if err = m.WithContext(ctx).Get(path, Defaults(), &v); err != nil {
+		v.manager.log("[REQUEST-ERROR] get-vm was failed: %s", err)
 	} else {
 		v.manager = m
 		for x := range v.Ports {
@@ -289,6 +414,10 @@ func (v *Vm) Reload() (err error) {
 }
 
 func (v *Vm) Update() (err error) {
+	return v.UpdateCtx(v.manager.ctx)
+}
+
+func (v *Vm) UpdateCtx(ctx context.Context) (err error) {
 	path, _ := url.JoinPath("v1/vm", v.ID)
 	affGr := make([]string, 0)
 
@@ -326,14 +455,14 @@ func (v *Vm) Update() (err error) {
 		}
 	}
 
-	if err = v.manager.Request("PUT", path, args, v); err != nil {
-		log.Printf("[REQUEST-ERROR] update-vm was failed: %s", err)
+	if err = v.manager.WithContext(ctx).Request("PUT", path, args, v); err != nil {
+		v.manager.log("[REQUEST-ERROR] update-vm was failed: %s", err)
 	}
 
 	return
 }
 
-func (v *Vm) updateState(state string) (err error) {
+func (v *Vm) updateState(ctx context.Context, state string) (err error) {
 	path := fmt.Sprintf("v1/vm/%s/state", v.ID)
 
 	args := &struct {
@@ -342,19 +471,51 @@ func (v *Vm) updateState(state string) (err error) {
 		State: state,
 	}
 
-	if err = v.manager.Request("POST", path, args, v); err != nil {
-		log.Printf("[REQUEST-ERROR] update-vm was failed: %s", err)
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, v); err != nil {
+		v.manager.log("[REQUEST-ERROR] update-vm was failed: %s", err)
+
+		// A vm still locked after RequestTimeout is not a refusal, only
+		// validation errors and conflicts with an alias of their own are.
+		var apiErr *ApiError
+		if errors.As(err, &apiErr) && !errors.Is(err, ErrLocked) &&
+			(errors.Is(err, ErrValidation) || apiErr.Code() == http.StatusConflict && len(apiErr.ErrorAliases()) > 0) {
+			err = &VmStateError{Vm: v.ID, State: state, Err: err}
+		}
 	}
 
 	return
 }
 
+// VmStateError is returned when the API refuses to move a vm to State, e.g.
+// when a powered off vm is asked to suspend.
+type VmStateError struct {
+	Vm    string
+	State string
+	Err   error
+}
+
+func (e *VmStateError) Error() string {
+	return fmt.Sprintf("vm %s refused state %s: %s", e.Vm, e.State, e.Err)
+}
+
+func (e *VmStateError) Unwrap() error {
+	return e.Err
+}
+
 func (v *Vm) Delete() error {
+	return v.DeleteCtx(v.manager.ctx)
+}
+
+func (v *Vm) DeleteCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/vm", v.ID)
-	return v.manager.Delete(path, Defaults(), nil)
+	return v.manager.WithContext(ctx).Delete(path, Defaults(), nil)
 }
 
 func (v Vm) WaitLock() error {
+	return v.WaitLockCtx(v.manager.ctx)
+}
+
+func (v Vm) WaitLockCtx(ctx context.Context) error {
 	path, _ := url.JoinPath("v1/vm", v.ID)
-	return loopWaitLock(v.manager, path)
+	return loopWaitLock(v.manager.WithContext(ctx), path)
 }
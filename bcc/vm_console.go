@@ -0,0 +1,336 @@
+package bcc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// VmConsole is a time limited access to the VNC console of a vm. URL is a
+// noVNC page or a websocket endpoint speaking raw RFB, Token authorizes it.
+type VmConsole struct {
+	URL       string    `json:"url"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (c *VmConsole) UnmarshalJSON(b []byte) error {
+	type vmConsole VmConsole
+	raw := struct {
+		*vmConsole
+		ExpiresAt string `json:"expires_at"`
+	}{
+		vmConsole: (*vmConsole)(c),
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	c.ExpiresAt = parseJobTime(raw.ExpiresAt)
+	return nil
+}
+
+// Expired reports whether the console access has to be requested again. A
+// console without an expiry time never expires.
+func (c *VmConsole) Expired() bool {
+	return !c.ExpiresAt.IsZero() && !time.Now().Before(c.ExpiresAt)
+}
+
+// websocketURL returns the URL to dial, with the token in the query as
+// websockify expects it.
+func (c *VmConsole) websocketURL() (string, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid console url %q: %w", c.URL, err)
+	}
+
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+
+	if c.Token != "" {
+		query := u.Query()
+		if query.Get("token") == "" {
+			query.Set("token", c.Token)
+			u.RawQuery = query.Encode()
+		}
+	}
+
+	return u.String(), nil
+}
+
+func (v *Vm) GetConsoleURL() (console *VmConsole, err error) {
+	return v.GetConsoleURLCtx(v.manager.ctx)
+}
+
+func (v *Vm) GetConsoleURLCtx(ctx context.Context) (console *VmConsole, err error) {
+	path := fmt.Sprintf("v1/vm/%s/console", v.ID)
+
+	if err = v.manager.WithContext(ctx).Get(path, Defaults(), &console); err != nil {
+		v.manager.log("[REQUEST-ERROR] get-vm-console was failed: %s", err)
+	}
+
+	return
+}
+
+// ConsoleProxy serves the console of a vm on a local TCP port, so it can be
+// opened with a regular VNC client. Every client connection gets its own
+// websocket, the console access is requested again once it expires.
+type ConsoleProxy struct {
+	vm       *Vm
+	listener net.Listener
+	dialer   *websocket.Dialer
+
+	// ctx is cancelled by Close, so that console requests and websocket
+	// dials still in flight do not hold it up.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	console *VmConsole
+	conns   map[net.Conn]struct{}
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// ProxyConsole listens on addr, like "127.0.0.1:5900" or "127.0.0.1:0", and
+// forwards the connections to the console of the vm until ctx is done or
+// the proxy is closed.
+func (v *Vm) ProxyConsole(ctx context.Context, addr string) (*ConsoleProxy, error) {
+	console, err := v.GetConsoleURLCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 45 * time.Second,
+		Subprotocols:     []string{"binary"},
+	}
+	if transport, ok := v.manager.Client.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+		dialer.TLSClientConfig = transport.TLSClientConfig.Clone()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &ConsoleProxy{
+		vm:       v,
+		listener: listener,
+		dialer:   dialer,
+		ctx:      ctx,
+		cancel:   cancel,
+		console:  console,
+		conns:    map[net.Conn]struct{}{},
+	}
+
+	dialer.NetDialContext = p.dialContext
+
+	p.wg.Add(1)
+	go p.serve()
+
+	go func() {
+		<-ctx.Done()
+		p.Close()
+	}()
+
+	return p, nil
+}
+
+// Addr is the local address VNC clients connect to.
+func (p *ConsoleProxy) Addr() net.Addr {
+	return p.listener.Addr()
+}
+
+// Close stops listening, drops the open connections and waits for them to
+// be torn down.
+func (p *ConsoleProxy) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.cancel()
+	err := p.listener.Close()
+	for conn := range p.conns {
+		conn.Close()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	return err
+}
+
+// dialContext opens the connections of the websockets. They are closed once
+// the proxy is, as the websocket handshake only honours deadlines and would
+// otherwise keep Close waiting until HandshakeTimeout.
+func (p *ConsoleProxy) dialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	stop := context.AfterFunc(p.ctx, func() { conn.Close() })
+	return &proxyConn{Conn: conn, stop: stop}, nil
+}
+
+// proxyConn is a websocket connection of a ConsoleProxy.
+type proxyConn struct {
+	net.Conn
+	stop func() bool
+}
+
+func (c *proxyConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
+
+func (p *ConsoleProxy) serve() {
+	defer p.wg.Done()
+
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		if !p.track(conn) {
+			conn.Close()
+			return
+		}
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			defer p.untrack(conn)
+
+			if err := p.forward(conn); err != nil && p.ctx.Err() == nil {
+				p.vm.manager.log("[REQUEST-ERROR] console proxy of vm %s was failed: %s", p.vm.ID, err)
+			}
+		}()
+	}
+}
+
+func (p *ConsoleProxy) track(conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+func (p *ConsoleProxy) untrack(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conn.Close()
+	delete(p.conns, conn)
+}
+
+// currentConsole returns the console access, requesting it again once it
+// has expired. The request is made without holding mu, so Close and other
+// connections are not blocked by it.
+func (p *ConsoleProxy) currentConsole() (*VmConsole, error) {
+	p.mu.Lock()
+	console := p.console
+	p.mu.Unlock()
+
+	if !console.Expired() {
+		return console, nil
+	}
+
+	console, err := p.vm.GetConsoleURLCtx(p.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.console = console
+	p.mu.Unlock()
+
+	return console, nil
+}
+
+func (p *ConsoleProxy) forward(conn net.Conn) error {
+	console, err := p.currentConsole()
+	if err != nil {
+		return err
+	}
+
+	wsURL, err := console.websocketURL()
+	if err != nil {
+		return err
+	}
+
+	ws, res, err := p.dialer.DialContext(p.ctx, wsURL, nil)
+	if err != nil {
+		if res != nil {
+			return fmt.Errorf("console websocket handshake failed with %s: %w", res.Status, err)
+		}
+		return err
+	}
+	defer ws.Close()
+
+	p.vm.manager.log("[bcc] Console of vm %s is proxied to %s", p.vm.ID, conn.RemoteAddr())
+
+	done := make(chan error, 2)
+	go func() {
+		for {
+			_, r, err := ws.NextReader()
+			if err != nil {
+				done <- err
+				return
+			}
+			if _, err := io.Copy(conn, r); err != nil {
+				done <- err
+				return
+			}
+		}
+	}()
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := conn.Read(buf)
+			if n > 0 {
+				if err := ws.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+					done <- err
+					return
+				}
+			}
+			if err != nil {
+				done <- err
+				return
+			}
+		}
+	}()
+
+	err = <-done
+	conn.Close()
+	ws.Close()
+	<-done
+
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) || websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		return nil
+	}
+	return err
+}
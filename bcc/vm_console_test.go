@@ -0,0 +1,136 @@
+package bcc_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+)
+
+// fakeConsole answers the console requests of the vm with url, counting
+// them, instead of passing them on to the server.
+func fakeConsole(url string, expiresAt string, requests *atomic.Int32) bcc.Middleware {
+	return func(next bcc.Doer) bcc.Doer {
+		return bcc.DoerFunc(func(ctx context.Context, call *bcc.Call) (*bcc.CallResult, error) {
+			if call.Method != http.MethodGet || call.Path != "v1/vm/vm1/console" {
+				return next.Do(ctx, call)
+			}
+			requests.Add(1)
+			body := fmt.Sprintf(`{"url": %q, "token": "secret", "expires_at": %q}`, url, expiresAt)
+			return &bcc.CallResult{Status: http.StatusOK, Header: http.Header{}, Body: []byte(body)}, nil
+		})
+	}
+}
+
+// hangingListener accepts connections and never answers them, like a
+// console endpoint stuck in the websocket handshake.
+func hangingListener(t *testing.T) (net.Listener, <-chan struct{}) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	accepted := make(chan struct{}, 8)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+			accepted <- struct{}{}
+		}
+	}()
+
+	return l, accepted
+}
+
+func TestConsoleProxyCloseDuringDial(t *testing.T) {
+	tests := []struct {
+		name         string
+		expiresAt    string
+		wantRequests int32
+	}{
+		{name: "valid console", expiresAt: time.Now().Add(time.Hour).UTC().Format(time.RFC3339), wantRequests: 1},
+		{name: "expired console", expiresAt: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339), wantRequests: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, m, _ := newVmServer(t)
+			hang, accepted := hangingListener(t)
+
+			var requests atomic.Int32
+			m.Use(fakeConsole("http://"+hang.Addr().String()+"/websockify", tt.expiresAt, &requests))
+			vm, err := m.GetVm("vm1")
+			if err != nil {
+				t.Fatalf("GetVm: %s", err)
+			}
+
+			p, err := vm.ProxyConsole(context.Background(), "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("ProxyConsole: %s", err)
+			}
+
+			client, err := net.Dial("tcp", p.Addr().String())
+			if err != nil {
+				t.Fatalf("dial proxy: %s", err)
+			}
+			defer client.Close()
+
+			select {
+			case <-accepted:
+			case <-time.After(5 * time.Second):
+				t.Fatal("proxy did not dial the console")
+			}
+
+			start := time.Now()
+			if err := p.Close(); err != nil {
+				t.Errorf("Close: %s", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Close waited %s for the pending websocket dial", elapsed)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requested the console %d times, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestConsoleProxyStopsWithContext(t *testing.T) {
+	_, m, _ := newVmServer(t)
+
+	var requests atomic.Int32
+	m.Use(fakeConsole("http://127.0.0.1:1/websockify", "", &requests))
+	vm, err := m.GetVm("vm1")
+	if err != nil {
+		t.Fatalf("GetVm: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p, err := vm.ProxyConsole(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ProxyConsole: %s", err)
+	}
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		conn, err := net.Dial("tcp", p.Addr().String())
+		if err != nil {
+			return
+		}
+		conn.Close()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("proxy still listens after its context was cancelled")
+}
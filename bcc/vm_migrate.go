@@ -0,0 +1,196 @@
+package bcc
+
+import (
+	"context"
+	"fmt"
+)
+
+// VmMigration is a checked plan to move a vm to another vdc or platform,
+// built by Vm.PlanMigration.
+type VmMigration struct {
+	Vm       *Vm
+	Vdc      *Vdc
+	Platform *Platform
+	// Hypervisor holds the limits the vm was checked against.
+	Hypervisor *Hypervisor
+	// Ports lists the network every port of the vm is moved to. Within the
+	// same vdc ports stay on their networks. Across vdcs a port goes to the
+	// network of the target vdc with the same name, or to its external
+	// network for a port on an external network. The entries may be changed
+	// before Start.
+	Ports []*VmMigrationPort
+}
+
+type VmMigrationPort struct {
+	Port    *Port
+	Network *Network
+}
+
+// Migrate moves the vm to targetVdc and, when it is not nil, to
+// targetPlatform, and returns once the migration job is finished. See
+// PlanMigration for the checks and the port remapping rules.
+func (v *Vm) Migrate(targetVdc *Vdc, targetPlatform *Platform) error {
+	return v.MigrateCtx(v.manager.ctx, targetVdc, targetPlatform)
+}
+
+func (v *Vm) MigrateCtx(ctx context.Context, targetVdc *Vdc, targetPlatform *Platform) error {
+	migration, err := v.PlanMigration(ctx, targetVdc, targetPlatform)
+	if err != nil {
+		return err
+	}
+
+	job, err := migration.Start(ctx)
+	if err != nil {
+		return err
+	}
+	if job != nil {
+		if err := v.manager.WithContext(ctx).waitJobs([]*Job{job}); err != nil {
+			return err
+		}
+	}
+
+	return v.ReloadCtx(ctx)
+}
+
+// PlanMigration checks that the vm fits the limits of the target hypervisor
+// and that targetPlatform belongs to targetVdc, and maps the ports of the vm
+// onto the networks of targetVdc. Nothing is changed yet.
+func (v *Vm) PlanMigration(ctx context.Context, targetVdc *Vdc, targetPlatform *Platform) (*VmMigration, error) {
+	if targetVdc == nil {
+		return nil, fmt.Errorf("%w: target vdc is required", ErrValidation)
+	}
+
+	vdc, err := v.manager.GetVdcCtx(ctx, targetVdc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	migration := &VmMigration{Vm: v, Vdc: vdc, Hypervisor: &vdc.Hypervisor}
+
+	if targetPlatform != nil {
+		platforms, err := v.manager.GetPlatformsCtx(ctx, vdc.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, platform := range platforms {
+			if platform.ID == targetPlatform.ID {
+				migration.Platform = platform
+			}
+		}
+		if migration.Platform == nil {
+			return nil, fmt.Errorf("%w: platform %s is not available in vdc %s", ErrValidation, targetPlatform.ID, vdc.Name)
+		}
+		if migration.Platform.Hypervisor != nil {
+			migration.Hypervisor = migration.Platform.Hypervisor
+		}
+	}
+
+	if v.Vdc != nil && v.Vdc.ID == vdc.ID && (migration.Platform == nil || (v.Platform != nil && v.Platform.ID == migration.Platform.ID)) {
+		return nil, fmt.Errorf("%w: vm %s already runs in vdc %s on this platform", ErrValidation, v.Name, vdc.Name)
+	}
+
+	if err := v.checkHypervisor(migration.Hypervisor); err != nil {
+		return nil, err
+	}
+
+	if migration.Ports, err = v.mapPorts(ctx, vdc); err != nil {
+		return nil, err
+	}
+
+	return migration, nil
+}
+
+// Start sends the migration and returns the job carrying it out, which is
+// nil when the API did not report one.
+func (m *VmMigration) Start(ctx context.Context) (*Job, error) {
+	type portArg struct {
+		ID      string `json:"id"`
+		Network string `json:"network"`
+	}
+
+	ports := make([]*portArg, 0, len(m.Ports))
+	for _, mapping := range m.Ports {
+		if mapping.Network == nil {
+			return nil, fmt.Errorf("%w: port %s has no target network", ErrValidation, mapping.Port.ID)
+		}
+		ports = append(ports, &portArg{ID: mapping.Port.ID, Network: mapping.Network.ID})
+	}
+
+	path := fmt.Sprintf("v1/vm/%s/migrate", m.Vm.ID)
+	args := &struct {
+		Vdc      string     `json:"vdc"`
+		Platform *string    `json:"platform,omitempty"`
+		Ports    []*portArg `json:"ports"`
+	}{
+		Vdc:   m.Vdc.ID,
+		Ports: ports,
+	}
+	if m.Platform != nil {
+		args.Platform = &m.Platform.ID
+	}
+
+	jobs, err := m.Vm.manager.WithContext(ctx).RequestJobs("POST", path, args, nil)
+	if err != nil {
+		m.Vm.manager.log("[REQUEST-ERROR] migrate-vm was failed: %s", err)
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+
+	return jobs[0], nil
+}
+
+// checkHypervisor reports whether the vm fits the per vm limits of the
+// hypervisor. Zero limits are not checked.
+func (v *Vm) checkHypervisor(hypervisor *Hypervisor) error {
+	switch {
+	case hypervisor.CpuPerVm > 0 && v.Cpu > hypervisor.CpuPerVm:
+		return fmt.Errorf("%w: hypervisor %s allows %d cpu per vm, vm has %d", ErrValidation, hypervisor.Name, hypervisor.CpuPerVm, v.Cpu)
+	case hypervisor.RamPerVm > 0 && v.Ram > float64(hypervisor.RamPerVm):
+		return fmt.Errorf("%w: hypervisor %s allows %d ram per vm, vm has %g", ErrValidation, hypervisor.Name, hypervisor.RamPerVm, v.Ram)
+	case hypervisor.DisksPerVm > 0 && len(v.Disks) > hypervisor.DisksPerVm:
+		return fmt.Errorf("%w: hypervisor %s allows %d disks per vm, vm has %d", ErrValidation, hypervisor.Name, hypervisor.DisksPerVm, len(v.Disks))
+	case hypervisor.PortsPerDevice > 0 && len(v.Ports) > hypervisor.PortsPerDevice:
+		return fmt.Errorf("%w: hypervisor %s allows %d ports per device, vm has %d", ErrValidation, hypervisor.Name, hypervisor.PortsPerDevice, len(v.Ports))
+	}
+
+	return nil
+}
+
+func (v *Vm) mapPorts(ctx context.Context, vdc *Vdc) ([]*VmMigrationPort, error) {
+	ports := make([]*VmMigrationPort, 0, len(v.Ports))
+
+	if v.Vdc != nil && v.Vdc.ID == vdc.ID {
+		for _, port := range v.Ports {
+			ports = append(ports, &VmMigrationPort{Port: port, Network: port.Network})
+		}
+		return ports, nil
+	}
+
+	networks, err := vdc.GetNetworksCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, port := range v.Ports {
+		if port.Network == nil {
+			return nil, fmt.Errorf("%w: network of port %s is unknown", ErrValidation, port.ID)
+		}
+
+		var target *Network
+		for _, network := range networks {
+			if (port.Network.External && network.External) || (!port.Network.External && !network.External && network.Name == port.Network.Name) {
+				target = network
+				break
+			}
+		}
+		if target == nil {
+			return nil, fmt.Errorf("%w: vdc %s has no network matching %s of port %s", ErrValidation, vdc.Name, port.Network.Name, port.ID)
+		}
+
+		ports = append(ports, &VmMigrationPort{Port: port, Network: target})
+	}
+
+	return ports, nil
+}
@@ -0,0 +1,277 @@
+package bcc_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+// migrationFixtures seed vm1 "web" in vdc1 "main", with a port on a private
+// and on an external network and two disks, and vdc2 "dr" holding a network
+// of the same name, an external network and an unrelated one.
+var migrationFixtures = []fixture{
+	object("vdc", bcctest.Object{"id": "vdc1", "name": "main", "hypervisor": bcctest.Object{"id": "hv1", "name": "kvm"}}),
+	object("vdc", bcctest.Object{"id": "vdc2", "name": "dr", "hypervisor": bcctest.Object{"id": "hv2", "name": "vmware", "cpu_per_vm": 8, "ram_per_vm": 16}}),
+	object("network", bcctest.Object{"id": "net1", "name": "private", "vdc": "vdc1"}),
+	object("network", bcctest.Object{"id": "ext1", "name": "internet", "vdc": "vdc1", "external": true}),
+	object("network", bcctest.Object{"id": "net3", "name": "backend", "vdc": "vdc2"}),
+	object("network", bcctest.Object{"id": "net2", "name": "private", "vdc": "vdc2"}),
+	object("network", bcctest.Object{"id": "ext2", "name": "public", "vdc": "vdc2", "external": true}),
+	object("platform", bcctest.Object{"id": "pl1", "name": "standard", "vdc": "vdc1"}),
+	object("platform", bcctest.Object{"id": "pl2", "name": "fast", "vdc": "vdc1"}),
+	object("platform", bcctest.Object{"id": "pl3", "name": "gold", "vdc": "vdc2"}),
+	object("platform", bcctest.Object{"id": "pl4", "name": "small", "vdc": "vdc2", "hypervisor": bcctest.Object{"id": "hv3", "name": "small", "cpu_per_vm": 2}}),
+	object("vm", bcctest.Object{"id": "vm1", "name": "web", "vdc": "vdc1", "platform": "pl1", "cpu": 4, "ram": 8}),
+	object("port", bcctest.Object{"id": "port1", "network": "net1", "connected": bcctest.Object{"id": "vm1", "type": "vm"}}),
+	object("port", bcctest.Object{"id": "port2", "network": "ext1", "connected": bcctest.Object{"id": "vm1", "type": "vm"}}),
+	object("disk", bcctest.Object{"id": "disk1", "name": "root", "size": 10, "vm": "vm1"}),
+	object("disk", bcctest.Object{"id": "disk2", "name": "data", "size": 20, "vm": "vm1"}),
+}
+
+// limitedVdc seeds vdc3 "edge", whose hypervisor has the given per vm
+// limits.
+func limitedVdc(limits bcctest.Object) fixture {
+	hypervisor := bcctest.Object{"id": "hv4", "name": "edge"}
+	for key, value := range limits {
+		hypervisor[key] = value
+	}
+
+	return object("vdc", bcctest.Object{"id": "vdc3", "name": "edge", "hypervisor": hypervisor})
+}
+
+func newMigrationServer(t *testing.T, fixtures ...fixture) (*bcctest.Server, *bcc.Vm) {
+	t.Helper()
+
+	s, m := newServer(t, append(append([]fixture{}, migrationFixtures...), fixtures...)...)
+	vm, err := m.GetVm("vm1")
+	if err != nil {
+		t.Fatalf("GetVm: %s", err)
+	}
+
+	return s, vm
+}
+
+func TestPlanMigration(t *testing.T) {
+	tests := []struct {
+		name           string
+		fixtures       []fixture
+		vdc            string
+		platform       string
+		wantPorts      map[string]string
+		wantHypervisor string
+		wantErr        string
+	}{
+		{
+			name:           "same vdc",
+			vdc:            "vdc1",
+			platform:       "pl2",
+			wantPorts:      map[string]string{"port1": "net1", "port2": "ext1"},
+			wantHypervisor: "kvm",
+		},
+		{
+			name:           "other vdc",
+			vdc:            "vdc2",
+			wantPorts:      map[string]string{"port1": "net2", "port2": "ext2"},
+			wantHypervisor: "vmware",
+		},
+		{
+			name:           "other vdc and platform",
+			vdc:            "vdc2",
+			platform:       "pl3",
+			wantPorts:      map[string]string{"port1": "net2", "port2": "ext2"},
+			wantHypervisor: "vmware",
+		},
+		{
+			name: "network missing",
+			fixtures: []fixture{
+				object("network", bcctest.Object{"id": "net4", "name": "storage", "vdc": "vdc1"}),
+				object("port", bcctest.Object{"id": "port3", "network": "net4", "connected": bcctest.Object{"id": "vm1", "type": "vm"}}),
+			},
+			vdc:     "vdc2",
+			wantErr: "vdc dr has no network matching storage of port port3",
+		},
+		{
+			name:     "external network missing",
+			fixtures: []fixture{limitedVdc(nil), object("network", bcctest.Object{"id": "net5", "name": "private", "vdc": "vdc3"})},
+			vdc:      "vdc3",
+			wantErr:  "vdc edge has no network matching internet of port port2",
+		},
+		{
+			name:     "cpu limit",
+			fixtures: []fixture{limitedVdc(bcctest.Object{"cpu_per_vm": 2})},
+			vdc:      "vdc3",
+			wantErr:  "hypervisor edge allows 2 cpu per vm, vm has 4",
+		},
+		{
+			name:     "ram limit",
+			fixtures: []fixture{limitedVdc(bcctest.Object{"ram_per_vm": 4})},
+			vdc:      "vdc3",
+			wantErr:  "hypervisor edge allows 4 ram per vm, vm has 8",
+		},
+		{
+			name:     "disk limit",
+			fixtures: []fixture{limitedVdc(bcctest.Object{"disks_per_vm": 1})},
+			vdc:      "vdc3",
+			wantErr:  "hypervisor edge allows 1 disks per vm, vm has 2",
+		},
+		{
+			name:     "port limit",
+			fixtures: []fixture{limitedVdc(bcctest.Object{"ports_per_device": 1})},
+			vdc:      "vdc3",
+			wantErr:  "hypervisor edge allows 1 ports per device, vm has 2",
+		},
+		{
+			name:     "platform hypervisor limit",
+			vdc:      "vdc2",
+			platform: "pl4",
+			wantErr:  "hypervisor small allows 2 cpu per vm, vm has 4",
+		},
+		{
+			name:     "platform missing from the vdc",
+			vdc:      "vdc2",
+			platform: "pl2",
+			wantErr:  "platform pl2 is not available in vdc dr",
+		},
+		{
+			name:    "already there",
+			vdc:     "vdc1",
+			wantErr: "vm web already runs in vdc main on this platform",
+		},
+		{
+			name:     "already on the platform",
+			vdc:      "vdc1",
+			platform: "pl1",
+			wantErr:  "vm web already runs in vdc main on this platform",
+		},
+		{
+			name:    "no vdc",
+			wantErr: "target vdc is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, vm := newMigrationServer(t, tt.fixtures...)
+
+			var vdc *bcc.Vdc
+			if tt.vdc != "" {
+				vdc = &bcc.Vdc{ID: tt.vdc}
+			}
+			var platform *bcc.Platform
+			if tt.platform != "" {
+				platform = &bcc.Platform{ID: tt.platform}
+			}
+
+			migration, err := vm.PlanMigration(context.Background(), vdc, platform)
+			if tt.wantErr != "" {
+				if !errors.Is(err, bcc.ErrValidation) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PlanMigration = %v, want a validation error saying %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanMigration: %s", err)
+			}
+
+			if migration.Vdc.ID != tt.vdc {
+				t.Errorf("vdc = %s, want %s", migration.Vdc.ID, tt.vdc)
+			}
+			if tt.platform != "" && (migration.Platform == nil || migration.Platform.ID != tt.platform) {
+				t.Errorf("platform = %+v, want %s", migration.Platform, tt.platform)
+			}
+			if migration.Hypervisor.Name != tt.wantHypervisor {
+				t.Errorf("checked against hypervisor %s, want %s", migration.Hypervisor.Name, tt.wantHypervisor)
+			}
+
+			ports := make(map[string]string)
+			for _, mapping := range migration.Ports {
+				ports[mapping.Port.ID] = mapping.Network.ID
+			}
+			if len(ports) != len(tt.wantPorts) {
+				t.Errorf("ports = %v, want %v", ports, tt.wantPorts)
+			}
+			for port, network := range tt.wantPorts {
+				if ports[port] != network {
+					t.Errorf("port %s goes to %s, want %s", port, ports[port], network)
+				}
+			}
+		})
+	}
+}
+
+func TestVmMigrationStart(t *testing.T) {
+	_, vm := newMigrationServer(t)
+
+	migration, err := vm.PlanMigration(context.Background(), &bcc.Vdc{ID: "vdc2"}, &bcc.Platform{ID: "pl3"})
+	if err != nil {
+		t.Fatalf("PlanMigration: %s", err)
+	}
+	for _, mapping := range migration.Ports {
+		if mapping.Port.ID == "port1" {
+			mapping.Network = &bcc.Network{ID: "net3"}
+		}
+	}
+
+	job, err := migration.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+	if job == nil {
+		t.Fatal("Start returned no job")
+	}
+	if err := job.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait: %s", err)
+	}
+
+	if err := vm.Reload(); err != nil {
+		t.Fatalf("Reload: %s", err)
+	}
+	if vm.Vdc.ID != "vdc2" || vm.Platform == nil || vm.Platform.ID != "pl3" {
+		t.Errorf("vm is in %s on %+v, want vdc2 on pl3", vm.Vdc.ID, vm.Platform)
+	}
+	want := map[string]string{"port1": "net3", "port2": "ext2"}
+	for _, port := range vm.Ports {
+		if port.Network.ID != want[port.ID] {
+			t.Errorf("port %s is on %s, want %s", port.ID, port.Network.ID, want[port.ID])
+		}
+	}
+}
+
+func TestVmMigrationStartUnmappedPort(t *testing.T) {
+	s, vm := newMigrationServer(t)
+
+	migration, err := vm.PlanMigration(context.Background(), &bcc.Vdc{ID: "vdc2"}, nil)
+	if err != nil {
+		t.Fatalf("PlanMigration: %s", err)
+	}
+	migration.Ports[0].Network = nil
+
+	if _, err := migration.Start(context.Background()); !errors.Is(err, bcc.ErrValidation) {
+		t.Fatalf("Start = %v, want a validation error", err)
+	}
+	for _, r := range s.Requests() {
+		if r.Path == "/v1/vm/vm1/migrate" {
+			t.Errorf("sent %s %s, want no migration", r.Method, r.Path)
+		}
+	}
+}
+
+func TestVmMigrate(t *testing.T) {
+	_, vm := newMigrationServer(t)
+
+	if err := vm.Migrate(&bcc.Vdc{ID: "vdc2"}, nil); err != nil {
+		t.Fatalf("Migrate: %s", err)
+	}
+	if vm.Vdc.ID != "vdc2" {
+		t.Errorf("vm is in %s after reload, want vdc2", vm.Vdc.ID)
+	}
+	for _, port := range vm.Ports {
+		if port.Network.Vdc.Id != "vdc2" {
+			t.Errorf("port %s is on network %s of %s, want one of vdc2", port.ID, port.Network.ID, port.Network.Vdc.Id)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package bcc
+
+import (
+	"context"
+	"fmt"
+)
+
+// Rebuild reinstalls the root disk of the vm from template. The vm keeps
+// its id, ports, floating ip, data disks and affinity groups. metadata is
+// checked against the fields of the template before anything is sent.
+func (v *Vm) Rebuild(template *Template, metadata []*VmMetadata, userData *string) error {
+	return v.RebuildCtx(v.manager.ctx, template, metadata, userData)
+}
+
+func (v *Vm) RebuildCtx(ctx context.Context, template *Template, metadata []*VmMetadata, userData *string) (err error) {
+	if template == nil {
+		return fmt.Errorf("%w: template is required", ErrValidation)
+	}
+	if err = v.checkTemplate(template); err != nil {
+		return
+	}
+
+	t := *template
+	t.manager = v.manager
+	fields, err := t.GetFieldsCtx(ctx)
+	if err != nil {
+		return
+	}
+	if err = validateMetadata(&t, fields, metadata); err != nil {
+		return
+	}
+
+	type metadataArg struct {
+		Field string `json:"field"`
+		Value string `json:"value"`
+	}
+
+	metadataList := make([]*metadataArg, len(metadata))
+	for idx := range metadata {
+		metadataList[idx] = &metadataArg{Field: metadata[idx].Field.ID, Value: metadata[idx].Value}
+	}
+
+	path := fmt.Sprintf("v1/vm/%s/rebuild", v.ID)
+	args := &struct {
+		Template string         `json:"template"`
+		Metadata []*metadataArg `json:"metadata"`
+		UserData *string        `json:"user_data,omitempty"`
+	}{
+		Template: template.ID,
+		Metadata: metadataList,
+		UserData: userData,
+	}
+
+	if err = v.manager.WithContext(ctx).Request("POST", path, args, nil); err != nil {
+		v.manager.log("[REQUEST-ERROR] rebuild-vm was failed: %s", err)
+		return
+	}
+
+	return v.ReloadCtx(ctx)
+}
+
+// checkTemplate reports whether the vm satisfies the minimal requirements
+// of template. Sizes unknown to the client are not checked.
+func (v *Vm) checkTemplate(template *Template) error {
+	if v.Cpu > 0 && v.Cpu < template.MinCpu {
+		return fmt.Errorf("%w: template %s needs at least %d cpu, vm has %d", ErrValidation, template.Name, template.MinCpu, v.Cpu)
+	}
+	if v.Ram > 0 && v.Ram < template.MinRam {
+		return fmt.Errorf("%w: template %s needs at least %g ram, vm has %g", ErrValidation, template.Name, template.MinRam, v.Ram)
+	}
+
+	for _, disk := range v.Disks {
+		if disk.IsRoot && disk.Size < template.MinHdd {
+			return fmt.Errorf("%w: template %s needs a root disk of at least %d, vm has %d", ErrValidation, template.Name, template.MinHdd, disk.Size)
+		}
+	}
+
+	return nil
+}
+
+// validateMetadata checks that metadata only sets editable fields of the
+// template, sets each of them once and covers the required fields that
+// have no default.
+func validateMetadata(template *Template, fields []*TemplateField, metadata []*VmMetadata) error {
+	known := make(map[string]*TemplateField, len(fields))
+	for _, field := range fields {
+		known[field.ID] = field
+	}
+
+	set := make(map[string]bool, len(metadata))
+	for _, item := range metadata {
+		field, ok := known[item.Field.ID]
+		if !ok {
+			return fmt.Errorf("%w: field %q does not belong to template %s", ErrValidation, item.Field.ID, template.Name)
+		}
+		if set[field.ID] {
+			return fmt.Errorf("%w: field %s is set more than once", ErrValidation, field.Name)
+		}
+		if !field.Editable {
+			return fmt.Errorf("%w: field %s of template %s is not editable", ErrValidation, field.Name, template.Name)
+		}
+		if field.Required && item.Value == "" {
+			return fmt.Errorf("%w: field %s of template %s is required", ErrValidation, field.Name, template.Name)
+		}
+		set[field.ID] = true
+	}
+
+	for _, field := range fields {
+		if field.Required && field.Editable && field.Default == "" && !set[field.ID] {
+			return fmt.Errorf("%w: field %s of template %s is required", ErrValidation, field.Name, template.Name)
+		}
+	}
+
+	return nil
+}
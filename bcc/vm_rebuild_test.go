@@ -0,0 +1,174 @@
+package bcc_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+// rebuildFixtures seed vm1 "web" in vdc1 and the templates ubuntu, whose
+// fields are hostname (required), username (required with a default),
+// note (optional) and os (not editable), and windows, which needs more cpu
+// than the vm has.
+var rebuildFixtures = []fixture{
+	mainVdc,
+	object("template", bcctest.Object{"id": "tpl1", "name": "ubuntu", "vdc": "vdc1", "min_cpu": 1, "min_ram": 1, "min_hdd": 10}),
+	object("template", bcctest.Object{"id": "tpl2", "name": "windows", "vdc": "vdc1", "min_cpu": 8, "min_ram": 1, "min_hdd": 10}),
+	object("template_field", bcctest.Object{"id": "f-host", "name": "hostname", "template": "tpl1", "required": true, "editable": true}),
+	object("template_field", bcctest.Object{"id": "f-user", "name": "username", "template": "tpl1", "required": true, "editable": true, "default": "admin"}),
+	object("template_field", bcctest.Object{"id": "f-note", "name": "note", "template": "tpl1", "editable": true}),
+	object("template_field", bcctest.Object{"id": "f-os", "name": "os", "template": "tpl1", "required": true, "default": "ubuntu"}),
+	object("vm", bcctest.Object{"id": "vm1", "name": "web", "vdc": "vdc1", "template": "tpl2", "cpu": 2, "ram": 4}),
+	object("disk", bcctest.Object{"id": "disk1", "name": "root", "size": 20, "is_root": true, "vm": "vm1"}),
+}
+
+// metadata builds the metadata setting fields, given as id and value pairs.
+func metadata(fields ...string) []*bcc.VmMetadata {
+	var items []*bcc.VmMetadata
+	for i := 0; i+1 < len(fields); i += 2 {
+		items = append(items, &bcc.VmMetadata{Field: bcc.TemplateField{ID: fields[i]}, Value: fields[i+1]})
+	}
+
+	return items
+}
+
+func TestVmRebuildValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		template *bcc.Template
+		metadata []*bcc.VmMetadata
+		wantErr  string
+	}{
+		{
+			name:     "required field missing",
+			template: &bcc.Template{ID: "tpl1", Name: "ubuntu"},
+			metadata: metadata("f-note", "hello"),
+			wantErr:  "field hostname of template ubuntu is required",
+		},
+		{
+			name:     "required field empty",
+			template: &bcc.Template{ID: "tpl1", Name: "ubuntu"},
+			metadata: metadata("f-host", ""),
+			wantErr:  "field hostname of template ubuntu is required",
+		},
+		{
+			name:     "field with a default emptied",
+			template: &bcc.Template{ID: "tpl1", Name: "ubuntu"},
+			metadata: metadata("f-host", "web", "f-user", ""),
+			wantErr:  "field username of template ubuntu is required",
+		},
+		{
+			name:     "not editable",
+			template: &bcc.Template{ID: "tpl1", Name: "ubuntu"},
+			metadata: metadata("f-host", "web", "f-os", "debian"),
+			wantErr:  "field os of template ubuntu is not editable",
+		},
+		{
+			name:     "duplicate",
+			template: &bcc.Template{ID: "tpl1", Name: "ubuntu"},
+			metadata: metadata("f-host", "web", "f-host", "db"),
+			wantErr:  "field hostname is set more than once",
+		},
+		{
+			name:     "unknown field",
+			template: &bcc.Template{ID: "tpl1", Name: "ubuntu"},
+			metadata: metadata("f-host", "web", "f-other", "x"),
+			wantErr:  `field "f-other" does not belong to template ubuntu`,
+		},
+		{
+			name:     "template too large",
+			template: &bcc.Template{ID: "tpl2", Name: "windows", MinCpu: 8},
+			wantErr:  "template windows needs at least 8 cpu, vm has 2",
+		},
+		{
+			name:     "root disk too small",
+			template: &bcc.Template{ID: "tpl1", Name: "ubuntu", MinHdd: 40},
+			metadata: metadata("f-host", "web"),
+			wantErr:  "template ubuntu needs a root disk of at least 40, vm has 20",
+		},
+		{
+			name:    "no template",
+			wantErr: "template is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newServer(t, rebuildFixtures...)
+			vm, err := m.GetVm("vm1")
+			if err != nil {
+				t.Fatalf("GetVm: %s", err)
+			}
+
+			err = vm.Rebuild(tt.template, tt.metadata, nil)
+			if !errors.Is(err, bcc.ErrValidation) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Rebuild = %v, want a validation error saying %q", err, tt.wantErr)
+			}
+			for _, r := range s.Requests() {
+				if r.Path == "/v1/vm/vm1/rebuild" {
+					t.Errorf("sent %s %s, want no rebuild", r.Method, r.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestVmRebuild(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata []*bcc.VmMetadata
+		want     map[string]string
+	}{
+		{
+			name:     "required fields",
+			metadata: metadata("f-host", "web"),
+			want:     map[string]string{"f-host": "web"},
+		},
+		{
+			name:     "optional and defaulted fields",
+			metadata: metadata("f-host", "web", "f-user", "ops", "f-note", "rebuilt"),
+			want:     map[string]string{"f-host": "web", "f-user": "ops", "f-note": "rebuilt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, m := newServer(t, rebuildFixtures...)
+			vm, err := m.GetVm("vm1")
+			if err != nil {
+				t.Fatalf("GetVm: %s", err)
+			}
+			template, err := m.GetTemplate("tpl1")
+			if err != nil {
+				t.Fatalf("GetTemplate: %s", err)
+			}
+
+			userData := "#cloud-config"
+			if err := vm.Rebuild(template, tt.metadata, &userData); err != nil {
+				t.Fatalf("Rebuild: %s", err)
+			}
+
+			if vm.ID != "vm1" || vm.Template.ID != "tpl1" {
+				t.Errorf("vm %s runs %s after the rebuild, want vm1 on tpl1", vm.ID, vm.Template.ID)
+			}
+			if vm.UserData == nil || *vm.UserData != userData {
+				t.Errorf("user data = %v, want %s", vm.UserData, userData)
+			}
+			got := make(map[string]string)
+			for _, item := range vm.Metadata {
+				got[item.Field.ID] = item.Value
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("metadata = %v, want %v", got, tt.want)
+			}
+			for field, value := range tt.want {
+				if got[field] != value {
+					t.Errorf("metadata %s = %q, want %q", field, got[field], value)
+				}
+			}
+		})
+	}
+}
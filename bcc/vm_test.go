@@ -0,0 +1,95 @@
+package bcc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/basis-cloud/bcc-go/bcc/bcctest"
+)
+
+// conflictOnState answers state changes of the vm with a 409 carrying alias.
+func conflictOnState(alias string) bcc.Middleware {
+	return func(next bcc.Doer) bcc.Doer {
+		return bcc.DoerFunc(func(ctx context.Context, call *bcc.Call) (*bcc.CallResult, error) {
+			if !strings.HasSuffix(call.Path, "/state") {
+				return next.Do(ctx, call)
+			}
+			resp := &http.Response{
+				StatusCode: http.StatusConflict,
+				Body:       io.NopCloser(strings.NewReader(`{"error_alias": ["` + alias + `"]}`)),
+			}
+			return nil, bcc.NewApiError(call.Path, resp)
+		})
+	}
+}
+
+func TestVmStateError(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          func(t *testing.T, s *bcctest.Server, m *bcc.Manager, vm *bcc.Vm)
+		change         func(vm *bcc.Vm) error
+		wantStateError bool
+		wantErr        error
+	}{
+		{
+			name:   "allowed",
+			change: (*bcc.Vm).PowerOff,
+		},
+		{
+			name: "refused by validation",
+			setup: func(t *testing.T, s *bcctest.Server, m *bcc.Manager, vm *bcc.Vm) {
+				if err := vm.PowerOff(); err != nil {
+					t.Fatalf("PowerOff: %s", err)
+				}
+			},
+			change:         (*bcc.Vm).Suspend,
+			wantStateError: true,
+			wantErr:        bcc.ErrValidation,
+		},
+		{
+			name: "refused by conflict",
+			setup: func(t *testing.T, s *bcctest.Server, m *bcc.Manager, vm *bcc.Vm) {
+				m.Use(conflictOnState("vm_state_conflict"))
+			},
+			change:         (*bcc.Vm).Reboot,
+			wantStateError: true,
+		},
+		{
+			name: "still locked",
+			setup: func(t *testing.T, s *bcctest.Server, m *bcc.Manager, vm *bcc.Vm) {
+				s.Lock("vm", "vm1")
+				m.RequestTimeout = 50 * time.Millisecond
+			},
+			change:  (*bcc.Vm).PowerOff,
+			wantErr: bcc.ErrLocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m, vm := newVmServer(t)
+			if tt.setup != nil {
+				tt.setup(t, s, m, vm)
+			}
+
+			err := tt.change(vm)
+
+			var stateErr *bcc.VmStateError
+			if errors.As(err, &stateErr) != tt.wantStateError {
+				t.Errorf("error = %v, want a *bcc.VmStateError: %t", err, tt.wantStateError)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !tt.wantStateError && tt.wantErr == nil && err != nil {
+				t.Errorf("state change: %s", err)
+			}
+		})
+	}
+}
@@ -1,8 +1,15 @@
 module github.com/basis-cloud/bcc-go
 
-go 1.21
+go 1.23.0
 
 require (
+	github.com/gorilla/websocket v1.5.3
 	github.com/pkg/errors v0.9.1
 	gopkg.in/yaml.v2 v2.4.0
 )
+
+require (
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/rogpeppe/go-internal v1.13.1 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+)